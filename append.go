@@ -0,0 +1,236 @@
+package kevlar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"errors"
+	"github.com/boggydigital/busan"
+	"hash"
+	"hash/crc64"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const hashStateExt = ".sha256-state"
+
+func (kv *keyValues) absHashStateFilename(key string) string {
+	return filepath.Join(kv.dir, kevlarDirname, busan.Sanitize(key)+hashStateExt)
+}
+
+// Append appends data to the stored value without reading it, creating the
+// value when it doesn't exist. SHA-256 hash is updated incrementally from the
+// hash state stored alongside the value (and computed from the value when
+// the state is missing). Unlike Set, the value file is written in place, so
+// reads might observe partially appended data. Failed appends are truncated
+// back to the previous value size. Existing values are not appended to,
+// unless the storage conflict policy is Overwrite (see WithConflictPolicy)
+func (kv *keyValues) Append(key string, reader io.Reader) error {
+	span := kv.startSpan(opAppend, key)
+
+	n, err := kv.append(key, reader)
+	if err == nil {
+		kv.stats.sets.Add(1)
+		kv.stats.bytesIn.Add(n)
+	}
+
+	span.SetBytes(n)
+	span.End(err)
+	return err
+}
+
+func (kv *keyValues) append(key string, reader io.Reader) (int64, error) {
+	done, err := kv.mutating()
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+
+	if err := kv.validateKey(key); err != nil {
+		return 0, err
+	}
+
+	unlock := kv.lockKey(key)
+	defer unlock()
+
+	release, err := kv.reserveKey(key)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	if err := kv.checkConflict(key); err != nil {
+		return 0, err
+	}
+
+	exists, err := kv.Has(key)
+	if err != nil {
+		return 0, err
+	}
+
+	absValueFilename := kv.absValueFilename(key)
+
+	var size int64
+	if fi, err := os.Stat(absValueFilename); err == nil {
+		size = fi.Size()
+		// linked duplicates share the file, appending in place would
+		// change values of other keys (see WithLinkDuplicates)
+		if st, ok := fi.Sys().(*syscall.Stat_t); ok && st.Nlink > 1 {
+			if err := kv.copyFile(absValueFilename, absValueFilename); err != nil {
+				return 0, err
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return 0, err
+	}
+
+	h, err := kv.hashState(key, size)
+	if err != nil {
+		return 0, err
+	}
+
+	var fh *fastHashState
+	if kv.fastHash {
+		if fh, err = kv.fastHashState(key, size); err != nil {
+			return 0, err
+		}
+	}
+
+	file, err := os.OpenFile(absValueFilename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, noSpaceErr(err)
+	}
+
+	writers := []io.Writer{file, h}
+	if fh != nil {
+		writers = append(writers, fh)
+	}
+
+	n, err := io.Copy(io.MultiWriter(writers...), reader)
+	if err != nil {
+		// keep the previous value when the append has failed
+		err = errors.Join(err, file.Truncate(size))
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, noSpaceErr(err)
+	}
+
+	if exists && n == 0 {
+		kv.stats.unchangedSets.Add(1)
+		return n, nil
+	}
+
+	hash := hex.EncodeToString(h.Sum(nil))
+	if err := kv.createHashFile(key, hash); err != nil {
+		return n, err
+	}
+	if err := kv.createHashStateFile(key, h); err != nil {
+		return n, err
+	}
+
+	if kv.linkDuplicates {
+		kv.setHashKey(hash, key)
+	}
+
+	if fh != nil {
+		if err := kv.createFastHashFile(key, formatFastHash(size+n, fh.sum)); err != nil {
+			return n, err
+		}
+	}
+
+	return n, kv.committed(kv.createOrUpdateLogRecord(key, size+n))
+}
+
+// hashState returns SHA-256 hash with the state of the current value of the
+// provided size. Stored state is used when it matches the current hash,
+// otherwise the value is hashed again
+func (kv *keyValues) hashState(key string, size int64) (hash.Hash, error) {
+	h := sha256.New()
+	if size == 0 {
+		return h, nil
+	}
+
+	currentHash, err := readSidecarFile(kv.absHashFilename(key))
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := readSidecarFile(kv.absHashStateFilename(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if state != "" && currentHash != "" {
+		if err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary([]byte(state)); err == nil &&
+			hex.EncodeToString(h.Sum(nil)) == currentHash {
+			return h, nil
+		}
+	}
+
+	if _, err := hashFile(h, kv.absValueFilename(key)); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+func (kv *keyValues) createHashStateFile(key string, h hash.Hash) error {
+	state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return kv.writeStaged(kv.absHashStateFilename(key), bytes.NewReader(state))
+}
+
+// fastHashState is CRC-64 checksum that can be updated with appended data
+type fastHashState struct {
+	sum uint64
+}
+
+func (fhs *fastHashState) Write(p []byte) (int, error) {
+	fhs.sum = crc64.Update(fhs.sum, crc64Table, p)
+	return len(p), nil
+}
+
+// fastHashState returns CRC-64 checksum of the current value of the provided
+// size. Stored fast hash is used when it matches the size, otherwise the value
+// is checksummed again
+func (kv *keyValues) fastHashState(key string, size int64) (*fastHashState, error) {
+	fhs := new(fastHashState)
+	if size == 0 {
+		return fhs, nil
+	}
+
+	fh, err := readSidecarFile(kv.absFastHashFilename(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if fhSize, fhSum, ok := strings.Cut(fh, ":"); ok {
+		if n, err := strconv.ParseInt(fhSize, 10, 64); err == nil && n == size {
+			if fhs.sum, err = strconv.ParseUint(fhSum, 16, 64); err == nil {
+				return fhs, nil
+			}
+		}
+	}
+
+	file, err := os.Open(kv.absValueFilename(key))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(fhs, file); err != nil {
+		return nil, err
+	}
+
+	return fhs, nil
+}
@@ -0,0 +1,94 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_Append(t *testing.T) {
+	tests := []struct {
+		fastHash       bool
+		linkDuplicates bool
+	}{
+		{false, false},
+		{true, false},
+		{false, true},
+	}
+
+	dir := filepath.Join(os.TempDir(), testsDirname)
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			var options []KeyValuesOption
+			if tt.fastHash {
+				options = append(options, WithFastHash())
+			}
+			if tt.linkDuplicates {
+				options = append(options, WithLinkDuplicates())
+			}
+
+			ikv, err := NewKeyValues(dir, GobExt, options...)
+			testo.Error(t, err, false)
+
+			kv, ok := ikv.(*keyValues)
+			testo.EqualValues(t, ok, true)
+
+			assertValue := func(key, expected string) {
+				testo.EqualValues(t, readValue(t, kv, key), expected)
+				hash, err := readSidecarFile(kv.absHashFilename(key))
+				testo.Error(t, err, false)
+				testo.EqualValues(t, hash, sha256Hex([]byte(expected)))
+				if tt.fastHash {
+					fh, err := readSidecarFile(kv.absFastHashFilename(key))
+					testo.Error(t, err, false)
+					testo.EqualValues(t, fh, fastHash([]byte(expected)))
+				}
+			}
+
+			// appending to a key that doesn't exist creates it
+			testo.Error(t, kv.Append("append", strings.NewReader("a")), false)
+			assertValue("append", "a")
+
+			testo.Error(t, kv.Append("append", strings.NewReader("bc")), false)
+			assertValue("append", "abc")
+
+			// hash is computed from the value when the state is missing
+			testo.Error(t, os.Remove(kv.absHashStateFilename("append")), false)
+			testo.Error(t, kv.Append("append", strings.NewReader("d")), false)
+			assertValue("append", "abcd")
+
+			// stored state is not used when the value has been set since
+			testo.Error(t, kv.Set("append", strings.NewReader("x")), false)
+			testo.Error(t, kv.Append("append", strings.NewReader("y")), false)
+			assertValue("append", "xy")
+
+			// appending nothing doesn't change the value
+			testo.Error(t, kv.Append("append", strings.NewReader("")), false)
+			updated, err := kv.UpdatedAfter(0)
+			testo.Error(t, err, false)
+			testo.EqualValues(t, len(updated), 1)
+
+			// appending to a linked duplicate doesn't change the other value
+			testo.Error(t, kv.Set("duplicate", strings.NewReader("xy")), false)
+			testo.Error(t, kv.Append("duplicate", strings.NewReader("z")), false)
+			assertValue("duplicate", "xyz")
+			assertValue("append", "xy")
+
+			for _, key := range []string{"append", "duplicate"} {
+				ok, err = kv.Cut(key)
+				testo.EqualValues(t, ok, true)
+				testo.Error(t, err, false)
+
+				_, err = os.Stat(kv.absHashStateFilename(key))
+				testo.EqualValues(t, os.IsNotExist(err), true)
+			}
+
+			testo.Error(t, ikv.Close(), false)
+			testo.Error(t, logRecordsCleanup(), false)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package kevlar
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"github.com/boggydigital/busan"
+	"io"
+	"slices"
+)
+
+const archiveCompressionLevel = flate.DefaultCompression
+
+// GetArchive streams values into a zip archive written to w. Archive
+// filenames are derived from keys the same way value filenames are and
+// keys are stored in entry comments, since filenames might not preserve
+// them (e.g. user/1.avatar is user_1_avatar). Archives are reproducible:
+// entries are written in the keys order, have zero timestamps and use
+// fixed compression level
+func (kv *keyValues) GetArchive(w io.Writer, keys ...string) error {
+	zw := newArchiveWriter(w)
+
+	for _, key := range keys {
+		if err := kv.archiveValue(zw, key); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func newArchiveWriter(w io.Writer) *zip.Writer {
+	zw := zip.NewWriter(w)
+	zw.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, archiveCompressionLevel)
+	})
+	return zw
+}
+
+// ExportWhere streams values of keys matching the reductions query (see
+// ReadableRedux.Match) into a zip archive same as GetArchive, e.g. for
+// partial backups. Keys are exported in ascending order and matching
+// keys that don't have values in this storage are skipped
+func ExportWhere(kv KeyValues, w io.Writer, rdx ReadableRedux, query map[string][]string, options ...MatchOption) error {
+	keys, err := existingKeys(kv, rdx.Match(query, options...))
+	if err != nil {
+		return err
+	}
+	return kv.GetArchive(w, keys...)
+}
+
+// existingKeys returns ordered keys that have values in the storage
+func existingKeys(kv KeyValues, keys []string) ([]string, error) {
+	existing := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if ok, err := kv.Has(key); err != nil {
+			return nil, err
+		} else if ok {
+			existing = append(existing, key)
+		}
+	}
+	slices.Sort(existing)
+	return existing, nil
+}
+
+func (kv *keyValues) archiveValue(zw *zip.Writer, key string) error {
+	file, err := kv.Get(key)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Modified is left zero, so that archive doesn't depend on the time of the
+	// export or the modification time of the value
+	fw, err := zw.CreateHeader(&zip.FileHeader{
+		Name:    busan.Sanitize(key) + kv.ext,
+		Comment: key,
+		Method:  zip.Deflate,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(fw, file)
+	return err
+}
@@ -0,0 +1,112 @@
+package kevlar
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/boggydigital/busan"
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_GetArchive(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), JsonExt)
+	testo.Error(t, err, false)
+
+	values := map[string]string{
+		"1":             "{}",
+		"2":             "[]",
+		"user/1.avatar": "\"\"",
+	}
+	for key, value := range values {
+		testo.Error(t, kv.Set(key, strings.NewReader(value)), false)
+	}
+
+	buf := new(bytes.Buffer)
+	testo.Error(t, kv.GetArchive(buf, "1", "2", "user/1.avatar"), false)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(zr.File), 3)
+
+	for _, zf := range zr.File {
+		// filenames are sanitized, keys are preserved in comments
+		key := zf.Comment
+		testo.EqualValues(t, zf.Name, busan.Sanitize(key)+JsonExt)
+		rc, err := zf.Open()
+		testo.Error(t, err, false)
+		bts, err := io.ReadAll(rc)
+		testo.Error(t, err, false)
+		testo.Error(t, rc.Close(), false)
+		testo.EqualValues(t, string(bts), values[key])
+	}
+
+	// archives of the same values are identical, even after values are set again
+	for key, value := range values {
+		_, err = kv.Cut(key)
+		testo.Error(t, err, false)
+		testo.Error(t, kv.Set(key, strings.NewReader(value)), false)
+	}
+
+	another := new(bytes.Buffer)
+	testo.Error(t, kv.GetArchive(another, "1", "2", "user/1.avatar"), false)
+	testo.EqualValues(t, bytes.Equal(buf.Bytes(), another.Bytes()), true)
+
+	testo.Error(t, kv.GetArchive(io.Discard, "key-that-doesnt-exist"), true)
+
+	for key := range values {
+		ok, err := kv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_ExportWhere(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), JsonExt)
+	testo.Error(t, err, false)
+
+	// k3 matches the query, but doesn't have a value
+	for _, key := range []string{"k1", "k2", "k4"} {
+		testo.Error(t, kv.Set(key, strings.NewReader("{}")), false)
+	}
+
+	rdx := mockRedux()
+
+	tests := []struct {
+		query map[string][]string
+		exp   []string
+	}{
+		{map[string][]string{"a1": {"v"}}, []string{"k1", "k2"}},
+		{map[string][]string{"a1": {"v2"}}, []string{"k2"}},
+		{map[string][]string{"a1": {"nothing"}}, []string{}},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			testo.Error(t, ExportWhere(kv, buf, rdx, tt.query), false)
+
+			zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			testo.Error(t, err, false)
+
+			names := make([]string, 0, len(zr.File))
+			for _, zf := range zr.File {
+				names = append(names, strings.TrimSuffix(zf.Name, JsonExt))
+			}
+			testo.DeepEqual(t, names, tt.exp)
+		})
+	}
+
+	_, err = kv.CutMany("k1", "k2", "k4")
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,200 @@
+package kevlar
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	binaryLogRecordsFilename = "_log.bin"
+	maxBinaryLogChunk        = 1 << 16
+	// maxBinaryLogKeyLength is much longer than any key that can be
+	// stored as a filename and only protects from corrupted key lengths
+	maxBinaryLogKeyLength = 1 << 16
+)
+
+var ErrBinaryLogCorrupted = errors.New("kevlar: binary log records are corrupted")
+
+// binaryLogMagic identifies binary log records and their version.
+// Version 1 log records (binaryLogMagicV1) don't have value sizes
+var (
+	binaryLogMagic   = []byte("KVL2")
+	binaryLogMagicV1 = []byte("KVL1")
+)
+
+// encodeBinaryLogRecords writes log records in a compact binary encoding:
+// magic and records count are followed by records, each encoded as varint
+// timestamp, single byte mutation type, uvarint value size, uvarint key
+// length and key bytes
+func encodeBinaryLogRecords(w io.Writer, lrs logRecords) error {
+	bw := bufio.NewWriter(w)
+
+	if _, err := bw.Write(binaryLogMagic); err != nil {
+		return err
+	}
+
+	var scratch [binary.MaxVarintLen64]byte
+
+	n := binary.PutUvarint(scratch[:], uint64(len(lrs)))
+	if _, err := bw.Write(scratch[:n]); err != nil {
+		return err
+	}
+
+	for _, lr := range lrs {
+		n = binary.PutVarint(scratch[:], lr.Ts)
+		if _, err := bw.Write(scratch[:n]); err != nil {
+			return err
+		}
+		if err := bw.WriteByte(byte(lr.Mt)); err != nil {
+			return err
+		}
+		n = binary.PutUvarint(scratch[:], uint64(max(lr.Sz, 0)))
+		if _, err := bw.Write(scratch[:n]); err != nil {
+			return err
+		}
+		n = binary.PutUvarint(scratch[:], uint64(len(lr.Id)))
+		if _, err := bw.Write(scratch[:n]); err != nil {
+			return err
+		}
+		if _, err := bw.WriteString(lr.Id); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// decodeBinaryLogRecords reads log records encoded with encodeBinaryLogRecords.
+// Empty input is decoded as empty log records. Same as gob decoding, every
+// record gets a separate key string, see logRecords.intern
+func decodeBinaryLogRecords(r io.Reader) (logRecords, error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(binaryLogMagic))
+	if _, err := io.ReadFull(br, magic); err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, corruptedBinaryLog(err)
+	}
+	sizes := bytes.Equal(magic, binaryLogMagic)
+	if !sizes && !bytes.Equal(magic, binaryLogMagicV1) {
+		return nil, fmt.Errorf("%w: unknown magic %q", ErrBinaryLogCorrupted, magic)
+	}
+
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, corruptedBinaryLog(err)
+	}
+
+	// count is not trusted to preallocate everything upfront, since
+	// corrupted log records could otherwise cause a huge allocation
+	lrs := make(logRecords, 0, min(count, maxBinaryLogChunk))
+	// records are allocated in chunks to reduce allocations for large logs
+	var records []logRecord
+	idBuf := make([]byte, 0, 64)
+
+	for ii := uint64(0); ii < count; ii++ {
+		if len(records) == cap(records) {
+			records = make([]logRecord, 0, min(count-ii, maxBinaryLogChunk))
+		}
+		records = append(records, logRecord{})
+		lr := &records[len(records)-1]
+
+		if lr.Ts, err = binary.ReadVarint(br); err != nil {
+			return nil, corruptedBinaryLog(err)
+		}
+
+		mt, err := br.ReadByte()
+		if err != nil {
+			return nil, corruptedBinaryLog(err)
+		}
+		lr.Mt = mutationType(mt)
+		if lr.Mt > cut {
+			return nil, fmt.Errorf("%w: unknown mutation type %d", ErrBinaryLogCorrupted, mt)
+		}
+
+		if sizes {
+			sz, err := binary.ReadUvarint(br)
+			if err != nil {
+				return nil, corruptedBinaryLog(err)
+			}
+			lr.Sz = int64(sz)
+		}
+
+		idLen, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, corruptedBinaryLog(err)
+		}
+		if idLen > maxBinaryLogKeyLength {
+			return nil, fmt.Errorf("%w: key length %d", ErrBinaryLogCorrupted, idLen)
+		}
+		if uint64(cap(idBuf)) < idLen {
+			idBuf = make([]byte, idLen)
+		}
+		idBuf = idBuf[:idLen]
+		if _, err := io.ReadFull(br, idBuf); err != nil {
+			return nil, corruptedBinaryLog(err)
+		}
+
+		lr.Id = string(idBuf)
+
+		lrs = append(lrs, lr)
+	}
+
+	return lrs, nil
+}
+
+func corruptedBinaryLog(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%w: %w", ErrBinaryLogCorrupted, err)
+}
+
+// detectLogFormat selects the format of existing log records when there
+// are no log records in the format selected with options (see WithBinaryLog),
+// so that the storage is never split into gob and binary log records
+func (kv *keyValues) detectLogFormat() {
+	if _, err := os.Stat(kv.absLogRecordsFilename()); !os.IsNotExist(err) {
+		return
+	}
+
+	kv.binaryLog = !kv.binaryLog
+	if _, err := os.Stat(kv.absLogRecordsFilename()); err != nil {
+		kv.binaryLog = !kv.binaryLog
+	}
+}
+
+// ConvertLogToBinary converts gob log records of the storage at the specified
+// directory to binary log records (see WithBinaryLog). Storage should not be
+// used during conversion and is connected with binary log records afterwards
+func ConvertLogToBinary(dir string) error {
+	absGobLogFilename := filepath.Join(dir, kevlarDirname, logRecordsFilename)
+	if _, err := os.Stat(absGobLogFilename); err != nil {
+		return err
+	}
+
+	// we won't be reading values, so extension
+	// can safely be set to an empty string
+	kv, err := connect(dir, "")
+	if err != nil {
+		return err
+	}
+
+	kv.mtx.Lock()
+	kv.binaryLog = true
+	err = kv.createLogRecords()
+	kv.mtx.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(absGobLogFilename)
+}
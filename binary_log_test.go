@@ -0,0 +1,247 @@
+package kevlar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func mockLogRecords(n int) logRecords {
+	lrs := make(logRecords, 0, n)
+	for ii := 0; ii < n; ii++ {
+		lr := &logRecord{
+			Ts: int64(1700000000 + ii),
+			Mt: mutationType(ii % 3),
+			Id: "key-" + strconv.Itoa(ii%(n/2+1)),
+		}
+		if lr.Mt != cut {
+			lr.Sz = int64(ii * 1000)
+		}
+		lrs = append(lrs, lr)
+	}
+	return lrs
+}
+
+func TestBinaryLogRecords_RoundTrip(t *testing.T) {
+	tests := []logRecords{
+		{},
+		{{Ts: -1, Mt: cut, Id: ""}},
+		mockLogRecords(100),
+	}
+
+	for ii, lrs := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			testo.Error(t, encodeBinaryLogRecords(buf, lrs), false)
+
+			decoded, err := decodeBinaryLogRecords(buf)
+			testo.Error(t, err, false)
+			testo.EqualValues(t, len(decoded), len(lrs))
+			for jj := range lrs {
+				testo.DeepEqual(t, decoded[jj], lrs[jj])
+			}
+		})
+	}
+}
+
+func TestDecodeBinaryLogRecords_Errors(t *testing.T) {
+	buf := new(bytes.Buffer)
+	testo.Error(t, encodeBinaryLogRecords(buf, mockLogRecords(10)), false)
+	encoded := buf.Bytes()
+
+	unknownMutationType := append(bytes.Clone(binaryLogMagic), 1, 0, 9, 0)
+	hugeKeyLength := binary.AppendUvarint(append(bytes.Clone(binaryLogMagic), 1, 0, 0, 0), 1<<40)
+
+	tests := [][]byte{
+		[]byte("gob?"),
+		encoded[:len(binaryLogMagic)],
+		encoded[:len(encoded)-1],
+		unknownMutationType,
+		hugeKeyLength,
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			_, err := decodeBinaryLogRecords(bytes.NewReader(tt))
+			testo.EqualValues(t, errors.Is(err, ErrBinaryLogCorrupted), true)
+		})
+	}
+
+	lrs, err := decodeBinaryLogRecords(bytes.NewReader(nil))
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(lrs), 0)
+}
+
+func TestDecodeBinaryLogRecords_V1(t *testing.T) {
+	// version 1 records: count, timestamp, mutation type, key length, key
+	v1 := append(bytes.Clone(binaryLogMagicV1), 2, 2, byte(create), 1, 'a', 4, byte(update), 1, 'a')
+
+	lrs, err := decodeBinaryLogRecords(bytes.NewReader(v1))
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, lrs, logRecords{
+		{Ts: 1, Mt: create, Id: "a"},
+		{Ts: 2, Mt: update, Id: "a"},
+	})
+}
+
+func TestWithBinaryLog(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname)
+
+	kv, err := NewKeyValues(dir, GobExt, WithBinaryLog())
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("binary", strings.NewReader("value")), false)
+
+	_, err = os.Stat(filepath.Join(dir, kevlarDirname, binaryLogRecordsFilename))
+	testo.Error(t, err, false)
+	_, err = os.Stat(filepath.Join(dir, kevlarDirname, logRecordsFilename))
+	testo.EqualValues(t, os.IsNotExist(err), true)
+
+	// connect again to decode log records from disk
+	testo.Error(t, kv.Close(), false)
+	kv, err = NewKeyValues(dir, GobExt, WithBinaryLog())
+	testo.Error(t, err, false)
+
+	ok, err := kv.Has("binary")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	ok, err = kv.Cut("binary")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, os.Remove(filepath.Join(dir, kevlarDirname, binaryLogRecordsFilename)), false)
+}
+
+func TestConvertLogToBinary(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname)
+
+	kv, err := connect(dir, GobExt)
+	testo.Error(t, err, false)
+
+	for _, key := range []string{"1", "2"} {
+		testo.Error(t, kv.Set(key, strings.NewReader(key)), false)
+	}
+
+	testo.Error(t, ConvertLogToBinary(dir), false)
+
+	_, err = os.Stat(filepath.Join(dir, kevlarDirname, logRecordsFilename))
+	testo.EqualValues(t, os.IsNotExist(err), true)
+
+	bkv, err := NewKeyValues(dir, GobExt, WithBinaryLog())
+	testo.Error(t, err, false)
+
+	keys, err := bkv.Keys()
+	testo.Error(t, err, false)
+	sort.Strings(keys)
+	testo.DeepEqual(t, keys, []string{"1", "2"})
+
+	for _, key := range keys {
+		ok, err := bkv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, bkv.Close(), false)
+	testo.Error(t, os.Remove(filepath.Join(dir, kevlarDirname, binaryLogRecordsFilename)), false)
+}
+
+const benchmarkLogRecords = 1_000_000
+
+func BenchmarkEncodeLogRecords_Gob(b *testing.B) {
+	lrs := mockLogRecords(benchmarkLogRecords)
+	b.ResetTimer()
+	for ii := 0; ii < b.N; ii++ {
+		if err := gob.NewEncoder(new(bytes.Buffer)).Encode(lrs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeLogRecords_Binary(b *testing.B) {
+	lrs := mockLogRecords(benchmarkLogRecords)
+	b.ResetTimer()
+	for ii := 0; ii < b.N; ii++ {
+		if err := encodeBinaryLogRecords(new(bytes.Buffer), lrs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeLogRecords_Gob(b *testing.B) {
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(mockLogRecords(benchmarkLogRecords)); err != nil {
+		b.Fatal(err)
+	}
+	encoded := buf.Bytes()
+	b.ResetTimer()
+	for ii := 0; ii < b.N; ii++ {
+		var lrs logRecords
+		if err := gob.NewDecoder(bytes.NewReader(encoded)).Decode(&lrs); err != nil {
+			b.Fatal(err)
+		}
+		lrs.intern()
+	}
+}
+
+func BenchmarkDecodeLogRecords_Binary(b *testing.B) {
+	buf := new(bytes.Buffer)
+	if err := encodeBinaryLogRecords(buf, mockLogRecords(benchmarkLogRecords)); err != nil {
+		b.Fatal(err)
+	}
+	encoded := buf.Bytes()
+	b.ResetTimer()
+	for ii := 0; ii < b.N; ii++ {
+		lrs, err := decodeBinaryLogRecords(bytes.NewReader(encoded))
+		if err != nil {
+			b.Fatal(err)
+		}
+		lrs.intern()
+	}
+}
+
+func TestKeyValues_DetectLogFormat(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname)
+	absGobLogFilename := filepath.Join(dir, kevlarDirname, logRecordsFilename)
+	absBinaryLogFilename := filepath.Join(dir, kevlarDirname, binaryLogRecordsFilename)
+
+	kv, err := connect(dir, GobExt)
+	testo.Error(t, err, false)
+	testo.Error(t, kv.Set("1", strings.NewReader("1")), false)
+
+	// gob log records are used when connected with WithBinaryLog
+	bkv, err := connect(dir, GobExt, WithBinaryLog())
+	testo.Error(t, err, false)
+	testo.Error(t, bkv.Set("2", strings.NewReader("2")), false)
+
+	_, err = os.Stat(absBinaryLogFilename)
+	testo.EqualValues(t, os.IsNotExist(err), true)
+
+	testo.Error(t, ConvertLogToBinary(dir), false)
+
+	// binary log records are used when connected without WithBinaryLog
+	kv, err = connect(dir, GobExt)
+	testo.Error(t, err, false)
+
+	keys, err := kv.Keys()
+	testo.Error(t, err, false)
+	sort.Strings(keys)
+	testo.DeepEqual(t, keys, []string{"1", "2"})
+
+	_, err = kv.CutMany(keys...)
+	testo.Error(t, err, false)
+
+	_, err = os.Stat(absGobLogFilename)
+	testo.EqualValues(t, os.IsNotExist(err), true)
+
+	testo.Error(t, os.Remove(absBinaryLogFilename), false)
+}
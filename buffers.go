@@ -0,0 +1,40 @@
+package kevlar
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// maxPooledBufferCap limits the capacity of buffers returned to the pool,
+// so that a single large value doesn't stay allocated for the process lifetime
+const maxPooledBufferCap = 16 << 20
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferCap {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// sha256Hex returns hex encoded SHA-256 hash of the data, encoding
+// into a fixed size array instead of formatting with fmt
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	var dst [sha256.Size * 2]byte
+	hex.Encode(dst[:], sum[:])
+	return string(dst[:])
+}
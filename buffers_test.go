@@ -0,0 +1,61 @@
+package kevlar
+
+import (
+	"bytes"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestSha256Hex(t *testing.T) {
+	tests := []string{"", "value", "longer value with spaces"}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			exp, err := Sha256(bytes.NewReader([]byte(tt)))
+			testo.Error(t, err, false)
+			testo.EqualValues(t, sha256Hex([]byte(tt)), exp)
+		})
+	}
+}
+
+func TestGetBuffer(t *testing.T) {
+	buf := getBuffer()
+	buf.WriteString("value")
+	putBuffer(buf)
+
+	testo.EqualValues(t, getBuffer().Len(), 0)
+}
+
+func BenchmarkKeyValues_Set(b *testing.B) {
+	kv, err := connect(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	value := bytes.Repeat([]byte("value"), 1024)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for ii := 0; ii < b.N; ii++ {
+		// alternate keys to write values that have changed
+		if err := kv.Set(strconv.Itoa(ii%8), bytes.NewReader(value[ii%len(value):])); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.StopTimer()
+
+	for ii := 0; ii < 8 && ii < b.N; ii++ {
+		if _, err := kv.Cut(strconv.Itoa(ii)); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	if err := logRecordsCleanup(); err != nil {
+		b.Fatal(err)
+	}
+}
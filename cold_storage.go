@@ -0,0 +1,140 @@
+package kevlar
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const archivedFilename = "_archived.gob"
+
+var ErrArchived = errors.New("kevlar: value has been archived")
+
+// Archive moves values with records matching the predicate (e.g. not
+// modified for a long time) to the destination (cold) storage and returns
+// the number of moved values. Moved values are cut and leave a stub, so
+// that Get returns ErrArchived for them and applications can redirect
+// to the destination storage. Setting the key again restores the value
+// in this storage and removes the stub, same as cutting it after that.
+// Pinned keys are not archived (see Pin)
+func (kv *keyValues) Archive(pred func(Record) bool, dst KeyValues) (int, error) {
+	if dkv, ok := dst.(*keyValues); ok && dkv == kv {
+		return 0, errors.New("kevlar: can't archive values to the same storage")
+	}
+
+	keys, err := kv.Keys()
+	if err != nil {
+		return 0, err
+	}
+
+	if keys, err = kv.unpinned(keys); err != nil {
+		return 0, err
+	}
+
+	records, err := kv.getRecords(keys)
+	if err != nil {
+		return 0, err
+	}
+
+	archived := make([]string, 0)
+	for _, key := range keys {
+		record, ok := records[key]
+		if !ok || !pred(record) {
+			continue
+		}
+		if err := kv.copyTo(key, dst); err != nil {
+			return 0, err
+		}
+		archived = append(archived, key)
+	}
+
+	if len(archived) == 0 {
+		return 0, nil
+	}
+
+	// stubs are written before values are cut, so that values are
+	// never missing from both storages without a stub
+	if err := kv.setArchived(archived, time.Now().Unix()); err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, key := range archived {
+		if err := kv.cutArchived(key); err != nil {
+			return moved, err
+		}
+		moved++
+	}
+
+	return moved, nil
+}
+
+// cutArchived cuts the value same as Cut, keeping the stub
+func (kv *keyValues) cutArchived(key string) error {
+	done, err := kv.mutating()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	ok, seq, err := kv.cutValue(key, true)
+	if ok && err == nil {
+		kv.stats.cuts.Add(1)
+	}
+	return kv.committed(seq, err)
+}
+
+func (kv *keyValues) copyTo(key string, dst KeyValues) error {
+	rc, err := kv.Get(key)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return dst.Set(key, rc)
+}
+
+func (kv *keyValues) setArchived(keys []string, ts int64) error {
+	done, err := kv.mutating()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	tss, err := kv.loadTimestamps(archivedFilename)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		tss[key] = ts
+	}
+
+	return kv.writeTimestamps(archivedFilename, tss)
+}
+
+// archivedErr annotates errors for missing values that have been
+// archived with ErrArchived
+func (kv *keyValues) archivedErr(key string, err error) error {
+	if ok, hasErr := kv.Has(key); hasErr != nil {
+		return hasErr
+	} else if ok {
+		return err
+	}
+
+	kv.mtx.Lock()
+	tss, tsErr := kv.loadTimestamps(archivedFilename)
+	kv.mtx.Unlock()
+
+	if tsErr != nil {
+		return tsErr
+	}
+	if _, ok := tss[key]; ok {
+		return fmt.Errorf("%w: %s: %w", ErrArchived, key, err)
+	}
+
+	return err
+}
@@ -0,0 +1,76 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_Archive(t *testing.T) {
+	hotDir := filepath.Join(os.TempDir(), testsDirname, "hot")
+	coldDir := filepath.Join(os.TempDir(), testsDirname, "cold")
+	for _, dir := range []string{hotDir, coldDir} {
+		testo.Error(t, os.MkdirAll(dir, 0755), false)
+	}
+
+	hot, err := NewKeyValues(hotDir, GobExt)
+	testo.Error(t, err, false)
+	cold, err := NewKeyValues(coldDir, GobExt)
+	testo.Error(t, err, false)
+
+	_, err = hot.Archive(func(Record) bool { return true }, hot)
+	testo.Error(t, err, true)
+
+	testo.Error(t, hot.Set("old", strings.NewReader("old")), false)
+	testo.Error(t, hot.Set("new", strings.NewReader("new value")), false)
+
+	n, err := hot.Archive(func(r Record) bool { return r.Size < int64(len("new value")) }, cold)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, n, 1)
+
+	ok, err := hot.Has("old")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, ok, false)
+
+	_, err = hot.Get("old")
+	testo.EqualValues(t, errors.Is(err, ErrArchived), true)
+
+	_, err = hot.Get("missing")
+	testo.EqualValues(t, os.IsNotExist(err), true)
+
+	rc, err := cold.Get("old")
+	testo.Error(t, err, false)
+	data, err := io.ReadAll(rc)
+	testo.Error(t, err, false)
+	testo.Error(t, rc.Close(), false)
+	testo.EqualValues(t, string(data), "old")
+
+	ok, err = hot.Has("new")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, ok, true)
+
+	// setting archived key restores the value
+	testo.Error(t, hot.Set("old", strings.NewReader("old")), false)
+	rc, err = hot.Get("old")
+	testo.Error(t, err, false)
+	testo.Error(t, rc.Close(), false)
+
+	// restored values that are cut again are not archived
+	ok, err = hot.Cut("old")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, ok, true)
+
+	_, err = hot.Get("old")
+	testo.EqualValues(t, os.IsNotExist(err), true)
+	testo.EqualValues(t, errors.Is(err, ErrArchived), false)
+
+	testo.Error(t, hot.Close(), false)
+	testo.Error(t, cold.Close(), false)
+	for _, dir := range []string{hotDir, coldDir} {
+		testo.Error(t, os.RemoveAll(dir), false)
+	}
+}
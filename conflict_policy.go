@@ -0,0 +1,270 @@
+package kevlar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrKeyExists = errors.New("kevlar: key already exists")
+
+// ConflictPolicy decides what happens when a value is set for a key
+// that already exists, see WithConflictPolicy and SetWithPolicy
+type ConflictPolicy int
+
+const (
+	// Overwrite replaces existing values (default)
+	Overwrite ConflictPolicy = iota
+	// ErrorIfExists keeps existing values and fails with ErrKeyExists
+	ErrorIfExists
+	// KeepNewest keeps existing values modified after the new value timestamp.
+	// Value files edited outside of storage are modified at the time of the edit.
+	// Only SetWithPolicy has the timestamp, it can't be the storage policy
+	KeepNewest
+	// AppendVersion keeps existing values and sets the new value as the
+	// version following the latest existing version of the key, see VersionKey
+	AppendVersion
+)
+
+var conflictPolicyStrings = map[ConflictPolicy]string{
+	Overwrite:     "overwrite",
+	ErrorIfExists: "error-if-exists",
+	KeepNewest:    "keep-newest",
+	AppendVersion: "append-version",
+}
+
+func (cp ConflictPolicy) String() string {
+	if str, ok := conflictPolicyStrings[cp]; ok {
+		return str
+	}
+	return "unknown conflict policy " + strconv.Itoa(int(cp))
+}
+
+// ParseConflictPolicy returns the conflict policy with the name returned by String
+func ParseConflictPolicy(str string) (ConflictPolicy, error) {
+	for cp, cps := range conflictPolicyStrings {
+		if cps == str {
+			return cp, nil
+		}
+	}
+	return Overwrite, fmt.Errorf("kevlar: unknown conflict policy %q", str)
+}
+
+const (
+	versionSeparator = "~"
+	// versionsFilename stores version numbers of keys set with AppendVersion,
+	// so that other keys that look like versions (e.g. a~2) are never
+	// mistaken for them
+	versionsFilename = "_versions.gob"
+)
+
+// VersionKey returns the key of the nth version of the value set with
+// AppendVersion policy, e.g. key~2. The first version is the key itself
+func VersionKey(key string, n int) string {
+	if n <= 1 {
+		return key
+	}
+	return key + versionSeparator + strconv.Itoa(n)
+}
+
+// keyVersion returns the key and the version number of the version key,
+// reverse of VersionKey
+func keyVersion(versionKey string) (string, int) {
+	if ii := strings.LastIndex(versionKey, versionSeparator); ii > 0 {
+		if n, err := strconv.Atoi(versionKey[ii+len(versionSeparator):]); err == nil && n > 1 {
+			return versionKey[:ii], n
+		}
+	}
+	return versionKey, 1
+}
+
+type setWithPolicyResult struct {
+	key  string
+	size int64
+}
+
+// SetWithPolicy sets the value same as Set, resolving conflicts with existing
+// values with the policy instead of the storage policy. Timestamp (e.g. source
+// modification time) is compared with existing values by KeepNewest. Returns
+// the key the value has been set to (a version key for AppendVersion) or an
+// empty string when the existing value has been kept
+func (kv *keyValues) SetWithPolicy(key string, reader io.Reader, policy ConflictPolicy, ts int64) (string, error) {
+	span := kv.startSpan(opSet, key)
+
+	result, err := withTimeout(kv, opSet, key, func(op *operation) (setWithPolicyResult, error) {
+		done, err := kv.mutating()
+		if err != nil {
+			return setWithPolicyResult{}, err
+		}
+		defer done()
+
+		return kv.setValueWithPolicy(key, op.reader(reader), policy, ts)
+	}, nil)
+	if result.key != "" && err == nil {
+		kv.stats.sets.Add(1)
+		kv.stats.bytesIn.Add(result.size)
+	}
+
+	span.SetBytes(result.size)
+	span.End(err)
+	return result.key, err
+}
+
+// setValueWithPolicy sets and commits the value, resolving conflicts with
+// the policy. Values are compared and set with the expected hash, so that
+// concurrent writes are resolved with the same policy.
+// Expects mutations to be allowed (see mutating)
+func (kv *keyValues) setValueWithPolicy(key string, reader io.Reader, policy ConflictPolicy, ts int64) (setWithPolicyResult, error) {
+	if policy == Overwrite {
+		size, seq, err := kv.setValue(key, reader)
+		return setWithPolicyResult{key: key, size: size}, kv.committed(seq, err)
+	}
+
+	// the value might need to be set more than once
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if _, err := io.Copy(buf, reader); err != nil {
+		return setWithPolicyResult{}, err
+	}
+
+	setKey, version := key, 1
+	if policy == AppendVersion {
+		var err error
+		if setKey, version, err = kv.nextVersionKey(key); err != nil {
+			return setWithPolicyResult{}, err
+		}
+	}
+
+	for {
+		// by default, the key must not exist
+		expectedHash := ""
+
+		switch policy {
+		case ErrorIfExists:
+		case KeepNewest:
+			kept, hash, err := kv.keepNewest(key, ts)
+			if err != nil || kept {
+				return setWithPolicyResult{}, err
+			}
+			expectedHash = hash
+		case AppendVersion:
+		default:
+			return setWithPolicyResult{}, fmt.Errorf("kevlar: %s", policy)
+		}
+
+		size, matched, seq, err := kv.setValueIf(setKey, bytes.NewReader(buf.Bytes()), &expectedHash)
+		if err = kv.committed(seq, err); err != nil {
+			return setWithPolicyResult{}, err
+		}
+		if matched && policy == AppendVersion {
+			err = kv.putTimestamp(versionsFilename, setKey, int64(version))
+		}
+		if matched {
+			return setWithPolicyResult{key: setKey, size: size}, err
+		}
+
+		switch policy {
+		case ErrorIfExists:
+			return setWithPolicyResult{}, fmt.Errorf("%w: %s", ErrKeyExists, key)
+		case AppendVersion:
+			if setKey, version, err = kv.nextVersionKey(key); err != nil {
+				return setWithPolicyResult{}, err
+			}
+		}
+		// KeepNewest: the value has been set concurrently, compare again
+	}
+}
+
+// keepNewest reports whether the existing value has been modified after the
+// timestamp and returns its hash otherwise (empty, when it doesn't exist)
+func (kv *keyValues) keepNewest(key string, ts int64) (bool, string, error) {
+	record, err := kv.getRecord(key)
+	if err != nil || record.Created == 0 {
+		return false, "", err
+	}
+
+	// value files edited outside of storage are not logged
+	mt, err := kv.ModTime(key)
+	if err != nil {
+		return false, "", err
+	}
+
+	return max(record.Modified, mt) > ts, record.Hash, nil
+}
+
+// nextVersionKey returns the version key following the latest existing
+// version of the key and its version number. Versions are not reused when
+// earlier versions have been cut (e.g. by ApplyRetention), so that later
+// versions are newer
+func (kv *keyValues) nextVersionKey(key string) (string, int, error) {
+	versionKeys, err := kv.KeysWithPrefix(key + versionSeparator)
+	if err != nil {
+		return "", 0, err
+	}
+
+	latest := 0
+	if ok, err := kv.Has(key); err != nil {
+		return "", 0, err
+	} else if ok {
+		latest = 1
+	}
+
+	// keys that look like versions are never overwritten,
+	// even when they haven't been set with AppendVersion
+	for _, vk := range versionKeys {
+		if versionedKey, n := keyVersion(vk); versionedKey == key {
+			latest = max(latest, n)
+		}
+	}
+
+	return VersionKey(key, latest+1), latest + 1, nil
+}
+
+// versionedKey returns the key that the version key has been set for
+// with AppendVersion, given the version number (see VersionKey)
+func versionedKey(versionKey string, n int64) string {
+	if n <= 1 {
+		return versionKey
+	}
+	return strings.TrimSuffix(versionKey, versionSeparator+strconv.FormatInt(n, 10))
+}
+
+// validateStoragePolicy makes sure the policy can be used as the storage
+// conflict policy. KeepNewest needs the timestamp of the new value, which
+// only SetWithPolicy callers have
+func validateStoragePolicy(policy ConflictPolicy) error {
+	switch policy {
+	case Overwrite, ErrorIfExists, AppendVersion:
+		return nil
+	default:
+		return fmt.Errorf("kevlar: %s can't be used as the storage conflict policy", policy)
+	}
+}
+
+// setWithStoragePolicy sets the value with the storage conflict
+// policy (see WithConflictPolicy)
+func (kv *keyValues) setWithStoragePolicy(key string, reader io.Reader) (int64, error) {
+	result, err := kv.setValueWithPolicy(key, reader, kv.conflictPolicy, time.Now().Unix())
+	return result.size, err
+}
+
+// checkConflict returns ErrKeyExists when the key exists and the storage
+// conflict policy doesn't allow replacing values in place. Used by writes
+// that can't set another version of the value (e.g. Append, Copy).
+// Expects the key to be locked
+func (kv *keyValues) checkConflict(key string) error {
+	if kv.conflictPolicy == Overwrite {
+		return nil
+	}
+	if ok, err := kv.Has(key); err != nil {
+		return err
+	} else if ok {
+		return fmt.Errorf("%w: %s", ErrKeyExists, key)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConflictPolicy_String(t *testing.T) {
+	for _, cp := range []ConflictPolicy{Overwrite, ErrorIfExists, KeepNewest, AppendVersion} {
+		pcp, err := ParseConflictPolicy(cp.String())
+		testo.Error(t, err, false)
+		testo.EqualValues(t, pcp, cp)
+	}
+
+	_, err := ParseConflictPolicy("unknown")
+	testo.Error(t, err, true)
+}
+
+func TestWithConflictPolicy(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt, WithConflictPolicy(ErrorIfExists))
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("conflict", strings.NewReader("original")), false)
+	testo.Error(t, kv.Set("source", strings.NewReader("source")), false)
+
+	path := filepath.Join(os.TempDir(), "conflict-file")
+	testo.Error(t, os.WriteFile(path, []byte("updated"), 0644), false)
+
+	// every write resolves conflicts with the storage policy
+	for _, set := range []func() error{
+		func() error { return kv.Set("conflict", strings.NewReader("updated")) },
+		func() error { return kv.SetMany(map[string]io.Reader{"conflict": strings.NewReader("updated")}) },
+		func() error { return kv.SetFromFile("conflict", path, false) },
+		func() error { return kv.Append("conflict", strings.NewReader("updated")) },
+		func() error { return kv.Copy("source", "conflict") },
+		func() error { return kv.Rename("source", "conflict") },
+	} {
+		testo.EqualValues(t, errors.Is(set(), ErrKeyExists), true)
+		testo.EqualValues(t, readValue(t, kv, "conflict"), "original")
+	}
+
+	testo.Error(t, os.Remove(path), false)
+
+	// policy can be overridden for a single value
+	setKey, err := kv.SetWithPolicy("conflict", strings.NewReader("updated"), Overwrite, 0)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, setKey, "conflict")
+	testo.EqualValues(t, readValue(t, kv, "conflict"), "updated")
+
+	cut, err := kv.CutMany("conflict", "source")
+	testo.DeepEqual(t, cut, map[string]bool{"conflict": true, "source": true})
+	testo.Error(t, err, false)
+
+	// KeepNewest needs the timestamp of the new value
+	_, err = NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt, WithConflictPolicy(KeepNewest))
+	testo.Error(t, err, true)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestWithConflictPolicy_AppendVersion(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt, WithConflictPolicy(AppendVersion))
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("versioned", strings.NewReader("1")), false)
+	testo.Error(t, kv.SetMany(map[string]io.Reader{"versioned": strings.NewReader("2")}), false)
+
+	wc, err := kv.SetWriter("versioned")
+	testo.Error(t, err, false)
+	_, err = io.WriteString(wc, "3")
+	testo.Error(t, err, false)
+	testo.Error(t, wc.Close(), false)
+
+	for n := 1; n <= 3; n++ {
+		testo.EqualValues(t, readValue(t, kv, VersionKey("versioned", n)), strconv.Itoa(n))
+	}
+
+	cut, err := kv.CutMany("versioned", "versioned~2", "versioned~3")
+	testo.EqualValues(t, len(cut), 3)
+	testo.Error(t, err, false)
+
+	// staged SetWriter files are removed when the value is set
+	staged, err := filepath.Glob(filepath.Join(os.TempDir(), testsDirname, ".versioned*"))
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(staged), 0)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_SetWithPolicy(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	now := time.Now().Unix()
+
+	tests := []struct {
+		policy ConflictPolicy
+		ts     int64
+		key    string
+	}{
+		{KeepNewest, now, "newest"},
+		{KeepNewest, now - 100, ""},
+		{KeepNewest, now + 100, "newest"},
+		{AppendVersion, now, "versioned"},
+		{AppendVersion, now, "versioned~2"},
+		{AppendVersion, now, "versioned~3"},
+		{ErrorIfExists, now, ""},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			key := "newest"
+			if tt.policy != KeepNewest {
+				key = "versioned"
+			}
+			setKey, err := kv.SetWithPolicy(key, strings.NewReader(strconv.Itoa(ii)), tt.policy, tt.ts)
+			testo.EqualValues(t, errors.Is(err, ErrKeyExists), tt.policy == ErrorIfExists)
+			testo.EqualValues(t, setKey, tt.key)
+			if setKey != "" {
+				testo.EqualValues(t, readValue(t, kv, setKey), strconv.Itoa(ii))
+			}
+		})
+	}
+
+	// value files edited outside of storage are kept, until newer values are set
+	edited := time.Now().Add(time.Hour)
+	testo.Error(t, os.Chtimes(kv.(*keyValues).absValueFilename("newest"), edited, edited), false)
+
+	setKey, err := kv.SetWithPolicy("newest", strings.NewReader("newer"), KeepNewest, now+100)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, setKey, "")
+
+	setKey, err = kv.SetWithPolicy("newest", strings.NewReader("newer"), KeepNewest, edited.Unix()+1)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, setKey, "newest")
+
+	_, err = kv.CutMany("newest", "versioned", "versioned~2", "versioned~3")
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
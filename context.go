@@ -0,0 +1,90 @@
+package kevlar
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// contextReader fails reads once the context is cancelled
+// or its deadline passes, e.g. reads of values returned by GetContext
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+type contextReadCloser struct {
+	contextReader
+	io.Closer
+}
+
+// GetContext returns the value same as Get. Context cancellation and
+// deadline apply to opening the value and reading the returned value
+func (kv *keyValues) GetContext(ctx context.Context, key string) (io.ReadCloser, error) {
+	span := kv.startSpan(opGet, key)
+
+	file, err := withContext(ctx, kv, opGet, key, func(*operation) (*os.File, error) {
+		return kv.get(key)
+	}, func(file *os.File) {
+		file.Close()
+	})
+	if err == nil {
+		kv.stats.gets.Add(1)
+		if fi, err := file.Stat(); err == nil {
+			kv.stats.bytesOut.Add(fi.Size())
+			span.SetBytes(fi.Size())
+		}
+	}
+
+	span.End(err)
+	if err != nil || ctx.Done() == nil {
+		return file, err
+	}
+	return &contextReadCloser{contextReader{ctx: ctx, r: file}, file}, nil
+}
+
+// SetContext sets the value same as Set. Reading the data is aborted
+// when the context is cancelled, its deadline passes or storage timeout
+// expires (see WithTimeout) and the value is not set. Values that have been
+// read completely are set and SetContext waits for them
+func (kv *keyValues) SetContext(ctx context.Context, key string, reader io.Reader) error {
+	span := kv.startSpan(opSet, key)
+
+	n, err := withContext(ctx, kv, opSet, key, func(op *operation) (int64, error) {
+		return kv.set(key, op.reader(reader))
+	}, nil)
+	if err == nil {
+		kv.stats.sets.Add(1)
+		kv.stats.bytesIn.Add(n)
+	}
+
+	span.SetBytes(n)
+	span.End(err)
+	return err
+}
+
+// CutContext removes the value same as Cut, unless the context
+// is cancelled or its deadline passes before the value is removed
+func (kv *keyValues) CutContext(ctx context.Context, key string) (bool, error) {
+	span := kv.startSpan(opCut, key)
+
+	ok, err := withContext(ctx, kv, opCut, key, func(op *operation) (bool, error) {
+		if err := op.commit(); err != nil {
+			return false, err
+		}
+		return kv.cut(key)
+	}, nil)
+	if ok && err == nil {
+		kv.stats.cuts.Add(1)
+	}
+
+	span.End(err)
+	return ok, err
+}
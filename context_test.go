@@ -0,0 +1,96 @@
+package kevlar
+
+import (
+	"context"
+	"errors"
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cancellingReader cancels the context after the first read,
+// simulating a request cancelled during a large value copy
+type cancellingReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+}
+
+func (cr *cancellingReader) Read(p []byte) (int, error) {
+	defer cr.cancel()
+	return cr.r.Read(p[:min(len(p), 1)])
+}
+
+func TestKeyValuesContext(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = kv.SetContext(cancelled, "cancelled", strings.NewReader("value"))
+	testo.EqualValues(t, errors.Is(err, context.Canceled), true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = kv.SetContext(ctx, "aborted", &cancellingReader{r: strings.NewReader("value"), cancel: cancel})
+	testo.EqualValues(t, errors.Is(err, context.Canceled), true)
+
+	for _, key := range []string{"cancelled", "aborted"} {
+		ok, err := kv.Has(key)
+		testo.EqualValues(t, ok, false)
+		testo.Error(t, err, false)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	testo.Error(t, kv.SetContext(ctx, "key", strings.NewReader("value")), false)
+
+	_, err = kv.GetContext(cancelled, "key")
+	testo.EqualValues(t, errors.Is(err, context.Canceled), true)
+
+	rc, err := kv.GetContext(ctx, "key")
+	testo.Error(t, err, false)
+	cancel()
+	_, err = io.ReadAll(rc)
+	testo.EqualValues(t, errors.Is(err, context.Canceled), true)
+	testo.Error(t, rc.Close(), false)
+
+	ok, err := kv.CutContext(cancelled, "key")
+	testo.EqualValues(t, ok, false)
+	testo.EqualValues(t, errors.Is(err, context.Canceled), true)
+
+	ok, err = kv.CutContext(context.Background(), "key")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValuesContext_WithTimeout(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt,
+		WithTimeout(10*time.Millisecond))
+	testo.Error(t, err, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// storage timeout applies to reading the data, not only the context
+	for _, parent := range []context.Context{context.Background(), ctx} {
+		lr := &lateReader{release: make(chan struct{}), done: make(chan struct{})}
+		err = kv.SetContext(parent, "late", lr)
+		testo.EqualValues(t, errors.Is(err, context.DeadlineExceeded), true)
+		close(lr.release)
+		<-lr.done
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	ok, err := kv.Has("late")
+	testo.EqualValues(t, ok, false)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,229 @@
+package kevlar
+
+import (
+	"fmt"
+	"github.com/boggydigital/busan"
+	"os"
+	"time"
+)
+
+// Copy copies the value of the src key to the dst key at the file level,
+// without reading the value into memory. Hash files are copied as well.
+// Copied value is created (or updated, when dst exists) with the current
+// timestamp. Copying the value that dst already has doesn't change it.
+// Existing dst value is replaced, unless the storage conflict policy is
+// not Overwrite (see WithConflictPolicy)
+func (kv *keyValues) Copy(src, dst string) error {
+	done, err := kv.mutating()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if err := kv.validateKey(dst); err != nil {
+		return err
+	}
+
+	unlock := kv.lockKeys(src, dst)
+	defer unlock()
+
+	if ok, err := kv.Has(src); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, src)
+	}
+
+	release, err := kv.reserveKey(dst)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := kv.checkConflict(dst); err != nil {
+		return err
+	}
+
+	hash, err := readSidecarFile(kv.absHashFilename(src))
+	if err != nil {
+		return err
+	}
+
+	if currentHash, err := kv.currentHash(dst); err != nil {
+		return err
+	} else if currentHash == hash {
+		kv.stats.unchangedSets.Add(1)
+		return nil
+	}
+
+	// keys that share the filename share the value, only the log records
+	// need to be updated
+	if busan.Sanitize(src) != busan.Sanitize(dst) {
+		if err := kv.copyFile(kv.absValueFilename(src), kv.absValueFilename(dst)); err != nil {
+			return err
+		}
+		if err := kv.createHashFile(dst, hash); err != nil {
+			return err
+		}
+		if fh, err := readSidecarFile(kv.absFastHashFilename(src)); err != nil {
+			return err
+		} else if fh != "" {
+			if err := kv.createFastHashFile(dst, fh); err != nil {
+				return err
+			}
+		}
+	}
+
+	size, err := kv.Size(src)
+	if err != nil {
+		return err
+	}
+
+	return kv.committed(kv.createOrUpdateLogRecord(dst, size))
+}
+
+func (kv *keyValues) copyFile(absSrcFilename, absDstFilename string) error {
+	file, err := os.Open(absSrcFilename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return kv.writeStaged(absDstFilename, file)
+}
+
+// Rename moves the value of the src key to the dst key at the file level.
+// Hash files, derived variants, refresh, expiration and pin annotations are
+// moved as well. Renamed value keeps created and modified timestamps of
+// the src key. Existing dst value is replaced, unless the storage conflict
+// policy is not Overwrite (see WithConflictPolicy)
+func (kv *keyValues) Rename(src, dst string) error {
+	done, err := kv.mutating()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if src == dst {
+		return nil
+	}
+
+	if err := kv.validateKey(dst); err != nil {
+		return err
+	}
+
+	unlock := kv.lockKeys(src, dst)
+	defer unlock()
+
+	if ok, err := kv.Has(src); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, src)
+	}
+
+	dstExists, err := kv.Has(dst)
+	if err != nil {
+		return err
+	}
+
+	if dstExists {
+		if err := kv.checkConflict(dst); err != nil {
+			return err
+		}
+	} else {
+		release, err := kv.reserveKey(dst)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
+	// keys that share the filename share the value, only the log records
+	// need to be updated
+	if busan.Sanitize(src) != busan.Sanitize(dst) {
+		for _, filenames := range [][2]string{
+			{kv.absValueFilename(src), kv.absValueFilename(dst)},
+			{kv.absHashFilename(src), kv.absHashFilename(dst)},
+			{kv.absFastHashFilename(src), kv.absFastHashFilename(dst)},
+			{kv.absHashStateFilename(src), kv.absHashStateFilename(dst)},
+		} {
+			if err := kv.renameFile(filenames[0], filenames[1]); err != nil {
+				return err
+			}
+		}
+
+		if err := kv.cutDerived(dst); err != nil {
+			return err
+		}
+		if err := kv.renameFile(kv.absDerivedDir(src), kv.absDerivedDir(dst)); err != nil {
+			return err
+		}
+	}
+
+	for _, filename := range keyTimestampsFilenames {
+		if err := kv.moveTimestamp(filename, src, dst); err != nil {
+			return err
+		}
+	}
+
+	for _, filename := range valueTimestampsFilenames {
+		if err := kv.cutTimestamps(filename, src, dst); err != nil {
+			return err
+		}
+	}
+
+	return kv.committed(kv.renameLogRecords(src, dst, dstExists))
+}
+
+// renameFile renames the src file into place of the dst file. When src file
+// doesn't exist, dst file is removed, so that it doesn't outlive the value
+func (kv *keyValues) renameFile(absSrcFilename, absDstFilename string) error {
+	if _, err := os.Stat(absSrcFilename); os.IsNotExist(err) {
+		if err := os.Remove(absDstFilename); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	return kv.retry(func() error {
+		return os.Rename(absSrcFilename, absDstFilename)
+	})
+}
+
+// renameLogRecords appends log records that cut the src key and create the
+// dst key with the src key timestamps, as a single mutation
+func (kv *keyValues) renameLogRecords(src, dst string, dstExists bool) (uint64, error) {
+	if err := kv.refreshLogRecords(); err != nil {
+		return 0, err
+	}
+
+	var created, modified, size int64
+	kv.mtx.Lock()
+	for _, lr := range kv.log {
+		if lr.Id != src {
+			continue
+		}
+		switch lr.Mt {
+		case create:
+			created, modified, size = lr.Ts, lr.Ts, lr.Sz
+		case update:
+			modified, size = lr.Ts, lr.Sz
+		case cut:
+			created, modified, size = 0, 0, 0
+		}
+	}
+	kv.mtx.Unlock()
+
+	now := time.Now().Unix()
+
+	recs := make([]*logRecord, 0, 4)
+	if dstExists {
+		recs = append(recs, &logRecord{Ts: now, Mt: cut, Id: dst})
+	}
+	recs = append(recs, &logRecord{Ts: created, Mt: create, Id: dst, Sz: size})
+	if modified > created {
+		recs = append(recs, &logRecord{Ts: modified, Mt: update, Id: dst, Sz: size})
+	}
+	recs = append(recs, &logRecord{Ts: now, Mt: cut, Id: src})
+
+	return kv.appendLogRecords(recs...)
+}
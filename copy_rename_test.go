@@ -0,0 +1,111 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readValue(t *testing.T, kv KeyValues, key string) string {
+	rc, err := kv.Get(key)
+	testo.Error(t, err, false)
+	sb := new(strings.Builder)
+	_, err = io.Copy(sb, rc)
+	testo.Error(t, err, false)
+	testo.Error(t, rc.Close(), false)
+	return sb.String()
+}
+
+func TestKeyValues_Copy(t *testing.T) {
+	ikv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt, WithFastHash())
+	testo.Error(t, err, false)
+
+	kv, ok := ikv.(*keyValues)
+	testo.EqualValues(t, ok, true)
+
+	testo.Error(t, kv.Set("src", strings.NewReader("value")), false)
+	testo.Error(t, kv.Copy("src", "dst"), false)
+
+	testo.EqualValues(t, readValue(t, kv, "src"), "value")
+	testo.EqualValues(t, readValue(t, kv, "dst"), "value")
+
+	srcRecord, ok := kv.GetRecord("src")
+	testo.EqualValues(t, ok, true)
+	dstRecord, ok := kv.GetRecord("dst")
+	testo.EqualValues(t, ok, true)
+	testo.EqualValues(t, dstRecord.Hash, srcRecord.Hash)
+	testo.EqualValues(t, dstRecord.Meta[MetaFastHash], srcRecord.Meta[MetaFastHash])
+
+	// copying the same value again doesn't update dst
+	testo.Error(t, kv.Copy("src", "dst"), false)
+	updated, err := kv.UpdatedAfter(0)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(updated), 0)
+
+	testo.Error(t, kv.Copy("key-that-doesnt-exist", "dst"), true)
+
+	for _, key := range []string{"src", "dst"} {
+		ok, err = kv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, ikv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_Rename(t *testing.T) {
+	ikv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	kv, ok := ikv.(*keyValues)
+	testo.EqualValues(t, ok, true)
+
+	testo.Error(t, kv.Set("src", strings.NewReader("value")), false)
+	testo.Error(t, kv.Set("dst", strings.NewReader("replaced")), false)
+	testo.Error(t, kv.SetDerived("src", "variant", strings.NewReader("derived")), false)
+	testo.Error(t, kv.SetRefreshAfter("src", 10), false)
+
+	// make sure renamed value would get a different timestamp
+	// if timestamps weren't preserved
+	src, ok := kv.GetRecord("src")
+	testo.EqualValues(t, ok, true)
+	time.Sleep(time.Until(time.Unix(src.Created+1, 0)))
+
+	testo.Error(t, kv.Rename("src", "dst"), false)
+
+	has, err := kv.Has("src")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, has, false)
+
+	testo.EqualValues(t, readValue(t, kv, "dst"), "value")
+
+	dst, ok := kv.GetRecord("dst")
+	testo.EqualValues(t, ok, true)
+	testo.EqualValues(t, dst.Created, src.Created)
+	testo.EqualValues(t, dst.Hash, src.Hash)
+	testo.EqualValues(t, dst.Meta[MetaRefreshAfter], "10")
+
+	rc, err := kv.GetDerived("dst", "variant")
+	testo.Error(t, err, false)
+	testo.Error(t, rc.Close(), false)
+
+	for _, absFilename := range []string{kv.absValueFilename("src"), kv.absHashFilename("src"), kv.absDerivedDir("src")} {
+		_, err = os.Stat(absFilename)
+		testo.EqualValues(t, os.IsNotExist(err), true)
+	}
+
+	testo.Error(t, kv.Rename("src", "dst"), true)
+
+	ok, err = kv.Cut("dst")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, ikv.Close(), false)
+	testo.Error(t, os.Remove(kv.absTimestampsFilename(refreshAfterFilename)), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
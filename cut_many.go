@@ -0,0 +1,41 @@
+package kevlar
+
+import "fmt"
+
+// CutMany cuts values same as Cut, but writes log records once for all of
+// them, instead of once per value. Result contains keys that have been cut
+// (true) or didn't exist (false). Values that have been cut before an error
+// are returned and their log records are written
+func (kv *keyValues) CutMany(keys ...string) (map[string]bool, error) {
+	done, err := kv.mutating()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	cut := make(map[string]bool, len(keys))
+
+	var lastSeq uint64
+	for _, key := range keys {
+		span := kv.startSpan(opCut, key)
+
+		ok, seq, err := kv.cutValue(key, false)
+		if ok && err == nil {
+			kv.stats.cuts.Add(1)
+		}
+
+		span.End(err)
+
+		if err != nil {
+			if commitErr := kv.committed(lastSeq, nil); commitErr != nil {
+				return cut, commitErr
+			}
+			return cut, fmt.Errorf("%s: %w", key, err)
+		}
+
+		cut[key] = ok
+		lastSeq = max(lastSeq, seq)
+	}
+
+	return cut, kv.committed(lastSeq, nil)
+}
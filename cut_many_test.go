@@ -0,0 +1,53 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_CutMany(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname, "cut-many")
+	testo.Error(t, os.MkdirAll(dir, 0755), false)
+
+	logWrites := 0
+	kv, err := NewKeyValues(dir, GobExt, WithAfterLogWrite(func(string) error {
+		logWrites++
+		return nil
+	}))
+	testo.Error(t, err, false)
+
+	keyReaders := make(map[string]io.Reader)
+	keys := make([]string, 0, 20)
+	for ii := 0; ii < 20; ii++ {
+		key := strconv.Itoa(ii)
+		keyReaders[key] = strings.NewReader(key)
+		keys = append(keys, key)
+	}
+	testo.Error(t, kv.SetMany(keyReaders), false)
+
+	cut, err := kv.CutMany(append(keys, "missing")...)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(cut), len(keys)+1)
+	testo.EqualValues(t, cut["1"], true)
+	testo.EqualValues(t, cut["missing"], false)
+	// one write for SetMany and one for CutMany
+	testo.EqualValues(t, logWrites, 2)
+
+	ckv, err := connect(dir, GobExt)
+	testo.Error(t, err, false)
+
+	remaining, err := ckv.Keys()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(remaining), 0)
+
+	_, err = os.Stat(ckv.absValueFilename("1"))
+	testo.EqualValues(t, os.IsNotExist(err), true)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, os.RemoveAll(dir), false)
+}
@@ -0,0 +1,94 @@
+package kevlar
+
+import (
+	"errors"
+	"fmt"
+	"github.com/boggydigital/busan"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const derivedDirname = "_derived"
+
+var (
+	ErrUnknownKey     = errors.New("kevlar: unknown key")
+	ErrInvalidVariant = errors.New("kevlar: invalid derived variant")
+)
+
+// validateVariant rejects variants that would resolve outside
+// of the key derived directory: empty variants, "." and variants
+// containing ".." or path separators
+func validateVariant(variant string) error {
+	switch {
+	case variant == "" || variant == ".":
+		return fmt.Errorf("%w: %q", ErrInvalidVariant, variant)
+	case strings.Contains(variant, ".."):
+		return fmt.Errorf("%w: %q contains ..", ErrInvalidVariant, variant)
+	case strings.ContainsAny(variant, `/\`):
+		return fmt.Errorf("%w: %q contains path separator", ErrInvalidVariant, variant)
+	}
+	return nil
+}
+
+func (kv *keyValues) absDerivedDir(key string) string {
+	return filepath.Join(kv.dir, kevlarDirname, derivedDirname, busan.Sanitize(key))
+}
+
+func (kv *keyValues) absDerivedFilename(key, variant string) string {
+	return filepath.Join(kv.absDerivedDir(key), busan.Sanitize(variant))
+}
+
+// SetDerived stores a variant derived from the value (e.g. a thumbnail or
+// a compressed copy). Derived variants can only be set for existing keys
+// and are cut together with the value. Variants are used as filenames and
+// can't be empty, contain ".." or path separators
+func (kv *keyValues) SetDerived(key, variant string, reader io.Reader) error {
+	if err := validateVariant(variant); err != nil {
+		return err
+	}
+
+	done, err := kv.mutating()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	unlock := kv.lockKey(key)
+	defer unlock()
+
+	if ok, err := kv.Has(key); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, key)
+	}
+
+	absDerivedDir := kv.absDerivedDir(key)
+	if _, err := os.Stat(absDerivedDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(absDerivedDir, 0755); err != nil {
+			return err
+		}
+	}
+
+	return kv.writeStaged(kv.absDerivedFilename(key, variant), reader)
+}
+
+// GetDerived returns previously set variant derived from the value
+func (kv *keyValues) GetDerived(key, variant string) (io.ReadCloser, error) {
+	if err := validateVariant(variant); err != nil {
+		return nil, err
+	}
+
+	if ok, err := kv.Has(key); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, key)
+	}
+
+	return os.Open(kv.absDerivedFilename(key, variant))
+}
+
+func (kv *keyValues) cutDerived(key string) error {
+	return os.RemoveAll(kv.absDerivedDir(key))
+}
@@ -0,0 +1,56 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_SetGetDerived(t *testing.T) {
+	ikv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	kv, ok := ikv.(*keyValues)
+	testo.EqualValues(t, ok, true)
+
+	err = kv.SetDerived("image", "thumbnail", strings.NewReader("small"))
+	testo.EqualValues(t, errors.Is(err, ErrUnknownKey), true)
+
+	testo.Error(t, kv.Set("image", strings.NewReader("large")), false)
+	testo.Error(t, kv.SetDerived("image", "thumbnail", strings.NewReader("small")), false)
+
+	rc, err := kv.GetDerived("image", "thumbnail")
+	testo.Error(t, err, false)
+	bts, err := io.ReadAll(rc)
+	testo.Error(t, err, false)
+	testo.Error(t, rc.Close(), false)
+	testo.EqualValues(t, string(bts), "small")
+
+	_, err = kv.GetDerived("image", "variant-that-doesnt-exist")
+	testo.Error(t, err, true)
+
+	// variants must stay within the derived directory of the key
+	for _, variant := range []string{"", ".", "..", "../image", "a/b", `a\b`} {
+		err = kv.SetDerived("image", variant, strings.NewReader("escaped"))
+		testo.EqualValues(t, errors.Is(err, ErrInvalidVariant), true)
+		_, err = kv.GetDerived("image", variant)
+		testo.EqualValues(t, errors.Is(err, ErrInvalidVariant), true)
+	}
+
+	ok, err = kv.Cut("image")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	_, err = os.Stat(kv.absDerivedDir("image"))
+	testo.EqualValues(t, os.IsNotExist(err), true)
+
+	_, err = kv.GetDerived("image", "thumbnail")
+	testo.EqualValues(t, errors.Is(err, ErrUnknownKey), true)
+
+	testo.Error(t, ikv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
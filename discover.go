@@ -0,0 +1,104 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/busan"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StoreInfo describes key values storage found by DiscoverStores
+type StoreInfo struct {
+	Dir  string
+	Ext  string
+	Keys int
+	Size int64
+}
+
+// DiscoverStores walks the directory tree and reports every key values storage
+// (identified by the presence of log records). Extension is inferred from the
+// stored values, since it's not recorded in the log
+func DiscoverStores(root string) ([]StoreInfo, error) {
+	dirs := make([]string, 0)
+	if err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || d.Name() != kevlarDirname {
+			return nil
+		}
+		for _, filename := range []string{logRecordsFilename, binaryLogRecordsFilename} {
+			if _, err := os.Stat(filepath.Join(path, filename)); err == nil {
+				dir, _ := filepath.Split(path)
+				dirs = append(dirs, filepath.Clean(dir))
+				break
+			}
+		}
+		return fs.SkipDir
+	}); err != nil {
+		return nil, err
+	}
+
+	stores := make([]StoreInfo, 0, len(dirs))
+	for _, dir := range dirs {
+		si, err := describeStore(dir)
+		if err != nil {
+			return nil, err
+		}
+		stores = append(stores, *si)
+	}
+
+	return stores, nil
+}
+
+func describeStore(dir string) (*StoreInfo, error) {
+	// we won't be reading values, so extension
+	// can safely be set to an empty string.
+	// Log records format is detected on connect
+	kv, err := NewKeyValues(dir, "")
+	if err != nil {
+		return nil, err
+	}
+	defer kv.Close()
+
+	keys, err := kv.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	filenames := make(map[string]any, len(keys))
+	for _, key := range keys {
+		filenames[busan.Sanitize(key)] = nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	si := &StoreInfo{
+		Dir:  dir,
+		Keys: len(keys),
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		if _, ok := filenames[strings.TrimSuffix(entry.Name(), ext)]; !ok {
+			continue
+		}
+		if si.Ext == "" {
+			si.Ext = ext
+		}
+		if fi, err := entry.Info(); err == nil {
+			si.Size += fi.Size()
+		} else {
+			return nil, err
+		}
+	}
+
+	return si, nil
+}
@@ -0,0 +1,48 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverStores(t *testing.T) {
+	root := filepath.Join(os.TempDir(), testsDirname, "discover")
+
+	jkv, err := NewKeyValues(filepath.Join(root, "json"), JsonExt)
+	testo.Error(t, err, false)
+	testo.Error(t, jkv.Set("1", strings.NewReader("{}")), false)
+	testo.Error(t, jkv.Set("2", strings.NewReader("[]")), false)
+
+	hkv, err := NewKeyValues(filepath.Join(root, "nested", "html"), HtmlExt)
+	testo.Error(t, err, false)
+	testo.Error(t, hkv.Set("1", strings.NewReader("<html></html>")), false)
+
+	// directories without log records are not reported
+	testo.Error(t, os.MkdirAll(filepath.Join(root, "empty"), 0755), false)
+
+	stores, err := DiscoverStores(root)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(stores), 2)
+
+	for _, si := range stores {
+		switch si.Dir {
+		case filepath.Join(root, "json"):
+			testo.EqualValues(t, si.Ext, JsonExt)
+			testo.EqualValues(t, si.Keys, 2)
+			testo.EqualValues(t, si.Size, int64(4))
+		case filepath.Join(root, "nested", "html"):
+			testo.EqualValues(t, si.Ext, HtmlExt)
+			testo.EqualValues(t, si.Keys, 1)
+			testo.EqualValues(t, si.Size, int64(13))
+		default:
+			t.Error("unexpected store " + si.Dir)
+		}
+	}
+
+	testo.Error(t, jkv.Close(), false)
+	testo.Error(t, hkv.Close(), false)
+	testo.Error(t, os.RemoveAll(root), false)
+}
@@ -0,0 +1,44 @@
+package kevlar
+
+import (
+	"io"
+	"time"
+)
+
+const expiresFilename = "_expires.gob"
+
+// SetWithExpiry sets the value same as Set and annotates the key with the
+// expiration timestamp ttl from now. Expired values are not removed until
+// PruneExpired is called. Setting the value with Set keeps the expiration,
+// cutting the value removes it
+func (kv *keyValues) SetWithExpiry(key string, reader io.Reader, ttl time.Duration) error {
+	if err := kv.Set(key, reader); err != nil {
+		return err
+	}
+	return kv.setTimestamp(expiresFilename, key, time.Now().Add(ttl).Unix())
+}
+
+// PruneExpired cuts values that have expired and returns cut keys.
+// Pinned keys are kept (see Pin)
+func (kv *keyValues) PruneExpired() ([]string, error) {
+	expired, err := kv.keysBefore(expiresFilename, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	if expired, err = kv.unpinned(expired); err != nil {
+		return nil, err
+	}
+
+	cut := make([]string, 0, len(expired))
+	for _, key := range expired {
+		if ok, err := kv.Cut(key); err != nil {
+			return cut, err
+		} else if ok {
+			cut = append(cut, key)
+		}
+	}
+
+	// expiration is removed with the cut value
+	return cut, nil
+}
@@ -0,0 +1,86 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyValues_PruneExpired(t *testing.T) {
+	ikv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	kv, ok := ikv.(*keyValues)
+	testo.EqualValues(t, ok, true)
+
+	testo.Error(t, kv.SetWithExpiry("expired", strings.NewReader("expired"), -time.Second), false)
+	testo.Error(t, kv.SetWithExpiry("fresh", strings.NewReader("fresh"), time.Hour), false)
+	testo.Error(t, kv.Set("no-expiry", strings.NewReader("no-expiry")), false)
+
+	record, ok := kv.GetRecord("fresh")
+	testo.EqualValues(t, ok, true)
+	_, ok = record.Meta[MetaExpires]
+	testo.EqualValues(t, ok, true)
+
+	pruned, err := kv.PruneExpired()
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, pruned, []string{"expired"})
+
+	keys, err := kv.Keys()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(keys), 2)
+
+	// expiration is removed with the pruned value
+	tss, err := kv.loadTimestamps(expiresFilename)
+	testo.Error(t, err, false)
+	_, ok = tss["expired"]
+	testo.EqualValues(t, ok, false)
+
+	pruned, err = kv.PruneExpired()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(pruned), 0)
+
+	for _, key := range []string{"fresh", "no-expiry"} {
+		ok, err = kv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, ikv.Close(), false)
+	testo.Error(t, os.Remove(kv.absTimestampsFilename(expiresFilename)), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_CutRemovesExpiry(t *testing.T) {
+	ikv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	kv, ok := ikv.(*keyValues)
+	testo.EqualValues(t, ok, true)
+
+	testo.Error(t, kv.Set("recreated", strings.NewReader("original")), false)
+	testo.Error(t, kv.SetWithExpiry("recreated", strings.NewReader("expiring"), -time.Second), false)
+
+	ok, err = kv.Cut("recreated")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	// values set after the cut must not inherit the expiration
+	testo.Error(t, kv.Set("recreated", strings.NewReader("recreated")), false)
+
+	pruned, err := kv.PruneExpired()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(pruned), 0)
+	testo.EqualValues(t, readValue(t, kv, "recreated"), "recreated")
+
+	ok, err = kv.Cut("recreated")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, ikv.Close(), false)
+	testo.Error(t, os.Remove(kv.absTimestampsFilename(expiresFilename)), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,65 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/busan"
+	"hash/crc64"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const fastHashExt = ".crc64"
+
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+// fastHash combines value size and CRC-64 checksum. It's much cheaper to
+// compute than SHA-256 and is only used to detect unchanged values
+func fastHash(data []byte) string {
+	return formatFastHash(int64(len(data)), crc64.Checksum(data, crc64Table))
+}
+
+func formatFastHash(size int64, sum uint64) string {
+	dst := make([]byte, 0, 40)
+	dst = strconv.AppendInt(dst, size, 10)
+	dst = append(dst, ':')
+	dst = strconv.AppendUint(dst, sum, 16)
+	return string(dst)
+}
+
+// fastHashFile computes fast hash of a file without reading it into memory
+func fastHashFile(path string, size int64) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := crc64.New(crc64Table)
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+
+	return formatFastHash(size, h.Sum64()), nil
+}
+
+func (kv *keyValues) absFastHashFilename(key string) string {
+	return filepath.Join(kv.dir, kevlarDirname, busan.Sanitize(key)+fastHashExt)
+}
+
+func (kv *keyValues) currentFastHash(key string) (string, error) {
+	if ok, err := kv.Has(key); err == nil {
+		if !ok {
+			return "", nil
+		}
+	} else {
+		return "", err
+	}
+
+	return readSidecarFile(kv.absFastHashFilename(key))
+}
+
+func (kv *keyValues) createFastHashFile(key, hash string) error {
+	return kv.writeStaged(kv.absFastHashFilename(key), strings.NewReader(hash))
+}
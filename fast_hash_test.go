@@ -0,0 +1,50 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFastHash(t *testing.T) {
+	testo.EqualValues(t, fastHash(nil), "0:0")
+	testo.EqualValues(t, fastHash([]byte("1")) == fastHash([]byte("1")), true)
+	testo.EqualValues(t, fastHash([]byte("1")) == fastHash([]byte("2")), false)
+}
+
+func TestKeyValues_WithFastHash(t *testing.T) {
+	ikv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt, WithFastHash())
+	testo.Error(t, err, false)
+
+	kv, ok := ikv.(*keyValues)
+	testo.EqualValues(t, ok, true)
+
+	testo.Error(t, kv.Set("fast", strings.NewReader("1")), false)
+	testo.EqualValues(t, len(kv.log), 1)
+
+	fh, err := kv.currentFastHash("fast")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, fh, fastHash([]byte("1")))
+
+	testo.Error(t, kv.Set("fast", strings.NewReader("1")), false)
+	testo.EqualValues(t, len(kv.log), 1)
+
+	testo.Error(t, kv.Set("fast", strings.NewReader("2")), false)
+	testo.EqualValues(t, len(kv.log), 2)
+
+	fh, err = kv.currentFastHash("fast")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, fh, fastHash([]byte("2")))
+
+	ok, err = kv.Cut("fast")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	_, err = os.Stat(kv.absFastHashFilename("fast"))
+	testo.EqualValues(t, os.IsNotExist(err), true)
+
+	testo.Error(t, ikv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
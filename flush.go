@@ -0,0 +1,127 @@
+package kevlar
+
+import (
+	"errors"
+	"hash/crc64"
+	"time"
+)
+
+// Flush writes log records that haven't been written yet because
+// of the flush policy (see WithFlushEvery, WithFlushInterval)
+func (kv *keyValues) Flush() error {
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	return kv.flushLogRecords()
+}
+
+// Close flushes pending log records and releases shared key values when
+// called by their last user, which also stops background maintenance. Key values are
+// released even when flushing fails, otherwise they would never be closed
+// by that user
+func (kv *keyValues) Close() error {
+	// shared key values keep maintenance running until the last user closes them
+	released, err := kv.release()
+	if released {
+		kv.stopMaintenance()
+	}
+
+	flushErr := kv.Flush()
+	return errors.Join(flushErr, err)
+}
+
+// persistLogRecords returns the sequence number of the mutation that should
+// be committed (see commitLogRecords) by default. When flush policy is set,
+// log records are written after the specified number of mutations or flush
+// interval and returned sequence number is 0. Expects storage to be locked
+func (kv *keyValues) persistLogRecords() (uint64, error) {
+	kv.logSeq++
+	kv.generation++
+
+	if kv.flushMutations == 0 && kv.flushInterval == 0 {
+		return kv.logSeq, nil
+	}
+
+	kv.pendingMutations++
+
+	if kv.flushMutations > 0 && kv.pendingMutations >= kv.flushMutations {
+		return 0, kv.flushLogRecords()
+	}
+
+	if kv.flushInterval > 0 && kv.flushTimer == nil {
+		kv.flushTimer = time.AfterFunc(kv.flushInterval, func() {
+			kv.mtx.Lock()
+			defer kv.mtx.Unlock()
+			kv.flushErr = kv.flushLogRecords()
+		})
+	}
+
+	return 0, nil
+}
+
+// commitLogRecords makes sure log records are written up to the mutation with
+// the sequence number. Log records are encoded while storage is locked, but
+// written without holding the lock, so that other mutations can proceed. Writes
+// are serialized and every write includes all mutations so far, so concurrent
+// mutations that wait for a write in progress are usually committed by the
+// next single write. Expects storage to be unlocked
+func (kv *keyValues) commitLogRecords(seq uint64) error {
+	kv.logWriteMtx.Lock()
+	defer kv.logWriteMtx.Unlock()
+
+	kv.mtx.Lock()
+	if kv.committedSeq >= seq {
+		kv.mtx.Unlock()
+		return nil
+	}
+	snapshotSeq := kv.logSeq
+	generation := kv.generation
+	buf, err := kv.encodeLogRecords()
+	kv.mtx.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	sum := crc64.Checksum(buf.Bytes(), crc64Table)
+	if err := kv.writeLogRecords(buf, generation); err != nil {
+		return err
+	}
+
+	ls := kv.statLogRecords()
+	ls.sum = sum
+
+	kv.mtx.Lock()
+	kv.committedSeq = snapshotSeq
+	// own writes shouldn't cause log records to be reloaded, since
+	// mutations committed by the next write would be lost
+	kv.logState = ls
+	kv.mtx.Unlock()
+
+	return nil
+}
+
+// flushLogRecords writes pending log records and returns an error of
+// the previous deferred flush, if any. Expects storage to be locked
+func (kv *keyValues) flushLogRecords() error {
+	if kv.flushTimer != nil {
+		kv.flushTimer.Stop()
+		kv.flushTimer = nil
+	}
+
+	if err := kv.flushErr; err != nil {
+		kv.flushErr = nil
+		return err
+	}
+
+	if kv.pendingMutations == 0 {
+		return nil
+	}
+
+	if err := kv.createLogRecords(); err != nil {
+		return err
+	}
+
+	kv.pendingMutations = 0
+	return nil
+}
@@ -0,0 +1,120 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func logRecordsExist(t *testing.T, kv KeyValues) bool {
+	_, err := os.Stat(kv.(*keyValues).absLogRecordsFilename())
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return err == nil
+}
+
+func TestKeyValues_WithFlushEvery(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt, WithFlushEvery(3))
+	testo.Error(t, err, false)
+
+	for ii := 0; ii < 2; ii++ {
+		testo.Error(t, kv.Set(strconv.Itoa(ii), strings.NewReader("flush")), false)
+		testo.EqualValues(t, logRecordsExist(t, kv), false)
+	}
+
+	testo.Error(t, kv.Set("2", strings.NewReader("flush")), false)
+	testo.EqualValues(t, logRecordsExist(t, kv), true)
+
+	for ii := 0; ii < 3; ii++ {
+		ok, err := kv.Cut(strconv.Itoa(ii))
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_WithFlushInterval(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt, WithFlushInterval(50*time.Millisecond))
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("interval", strings.NewReader("flush")), false)
+	testo.EqualValues(t, logRecordsExist(t, kv), false)
+
+	time.Sleep(100 * time.Millisecond)
+	testo.EqualValues(t, logRecordsExist(t, kv), true)
+
+	ok, err := kv.Cut("interval")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_CommitLogRecords(t *testing.T) {
+	writes := 0
+	kv, err := connect(filepath.Join(os.TempDir(), testsDirname), GobExt,
+		WithAfterLogWrite(func(string) error {
+			writes++
+			return nil
+		}))
+	testo.Error(t, err, false)
+
+	// two mutations are persisted before either is committed
+	seqs := make([]uint64, 0, 2)
+	for _, key := range []string{"1", "2"} {
+		kv.mtx.Lock()
+		kv.log = append(kv.log, &logRecord{Ts: time.Now().Unix(), Mt: create, Id: key})
+		seq, err := kv.persistLogRecords()
+		kv.mtx.Unlock()
+		testo.Error(t, err, false)
+		seqs = append(seqs, seq)
+	}
+
+	// the last mutation commit writes both of them
+	testo.Error(t, kv.commitLogRecords(seqs[1]), false)
+	testo.Error(t, kv.commitLogRecords(seqs[0]), false)
+	testo.EqualValues(t, writes, 1)
+
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_ConcurrentSetDurability(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname)
+	kv, err := connect(dir, GobExt)
+	testo.Error(t, err, false)
+
+	const goroutines = 50
+
+	errs := make(chan error, goroutines)
+	for ii := 0; ii < goroutines; ii++ {
+		go func(key string) {
+			errs <- kv.Set(key, strings.NewReader(key))
+		}(strconv.Itoa(ii))
+	}
+	for ii := 0; ii < goroutines; ii++ {
+		testo.Error(t, <-errs, false)
+	}
+
+	// all mutations are persisted once Set has returned
+	ckv, err := connect(dir, GobExt)
+	testo.Error(t, err, false)
+	keys, err := ckv.Keys()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(keys), goroutines)
+
+	for _, key := range keys {
+		ok, err := ckv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, logRecordsCleanup(), false)
+}
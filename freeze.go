@@ -0,0 +1,36 @@
+package kevlar
+
+import "errors"
+
+var ErrFrozen = errors.New("kevlar: storage is frozen")
+
+// Freeze makes all mutations return ErrFrozen until Thaw is called, while
+// reads continue to work. Freeze waits for mutations in progress to complete
+// and flushes pending log records, so that storage is quiescent on return
+// (e.g. for backups). Shared key values are frozen for all their users
+func (kv *keyValues) Freeze() error {
+	kv.freezeMtx.Lock()
+	kv.frozen = true
+	kv.freezeMtx.Unlock()
+
+	return kv.Flush()
+}
+
+// Thaw allows mutations again after Freeze
+func (kv *keyValues) Thaw() {
+	kv.freezeMtx.Lock()
+	kv.frozen = false
+	kv.freezeMtx.Unlock()
+}
+
+// mutating returns ErrFrozen when storage is frozen. Otherwise it prevents
+// Freeze from completing until the returned function is called at the end
+// of the mutation. Must not be nested, since that might deadlock with Freeze
+func (kv *keyValues) mutating() (func(), error) {
+	kv.freezeMtx.RLock()
+	if kv.frozen {
+		kv.freezeMtx.RUnlock()
+		return nil, ErrFrozen
+	}
+	return kv.freezeMtx.RUnlock, nil
+}
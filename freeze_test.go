@@ -0,0 +1,39 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_FreezeThaw(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("frozen", strings.NewReader("value")), false)
+
+	testo.Error(t, kv.Freeze(), false)
+
+	testo.EqualValues(t, errors.Is(kv.Set("frozen", strings.NewReader("new value")), ErrFrozen), true)
+	testo.EqualValues(t, errors.Is(kv.SetDerived("frozen", "variant", strings.NewReader("")), ErrFrozen), true)
+	testo.EqualValues(t, errors.Is(kv.SetRefreshAfter("frozen", 1), ErrFrozen), true)
+	_, err = kv.Cut("frozen")
+	testo.EqualValues(t, errors.Is(err, ErrFrozen), true)
+
+	// reads still work
+	preview, err := Preview(kv, "frozen", 1024)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, string(preview), "value")
+
+	kv.Thaw()
+
+	ok, err := kv.Cut("frozen")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,123 @@
+package kevlar
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	storeIdFilename    = "_id"
+	generationFilename = "_generation"
+)
+
+// Generation identifies the state of the log records: Id is a random UUID
+// of the store (created on first use) and N is incremented with every log
+// records mutation. Remote caches can compare generations to detect changes
+// without hashing values or comparing modification times
+type Generation struct {
+	Id string
+	N  uint64
+}
+
+// String formats generation as a single token, e.g. for HTTP ETag
+func (g Generation) String() string {
+	return g.Id + "-" + strconv.FormatUint(g.N, 10)
+}
+
+// Generation returns the current generation of the store,
+// reloading log records changed externally
+func (kv *keyValues) Generation() (Generation, error) {
+	if err := kv.refreshLogRecords(); err != nil {
+		return Generation{}, err
+	}
+
+	id, err := kv.storeId()
+	if err != nil {
+		return Generation{}, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	return Generation{Id: id, N: kv.generation}, nil
+}
+
+// storeId returns the store UUID, creating it when it doesn't exist.
+// Concurrent connections to the same directory create the same UUID
+func (kv *keyValues) storeId() (string, error) {
+	absIdFilename := filepath.Join(kv.dir, kevlarDirname, storeIdFilename)
+
+	for {
+		if id, err := os.ReadFile(absIdFilename); err == nil && len(id) > 0 {
+			return string(id), nil
+		} else if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(absIdFilename), 0755); err != nil {
+			return "", err
+		}
+
+		id, err := newUUID()
+		if err != nil {
+			return "", err
+		}
+
+		// write staged id with a link, so that it's only created when
+		// it doesn't exist and is never observed partially written
+		stagedFilename := absIdFilename + "-" + id
+		if err := os.WriteFile(stagedFilename, []byte(id), 0644); err != nil {
+			return "", err
+		}
+		err = os.Link(stagedFilename, absIdFilename)
+		if removeErr := os.Remove(stagedFilename); removeErr != nil {
+			return "", removeErr
+		}
+		if err == nil {
+			return id, nil
+		} else if !errors.Is(err, os.ErrExist) {
+			return "", err
+		}
+		// created by another connection, read it again
+	}
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", err
+	}
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+
+	h := hex.EncodeToString(uuid[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
+
+func (kv *keyValues) absGenerationFilename() string {
+	return filepath.Join(kv.dir, kevlarDirname, generationFilename)
+}
+
+// loadGeneration returns the generation written with log records.
+// Log records written before generations were introduced start
+// from the number of log records
+func (kv *keyValues) loadGeneration(lrs logRecords) (uint64, error) {
+	data, err := os.ReadFile(kv.absGenerationFilename())
+	if os.IsNotExist(err) {
+		return uint64(len(lrs)), nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// writeGeneration writes the generation after log records have been written
+func (kv *keyValues) writeGeneration(generation uint64) error {
+	return kv.writeStaged(kv.absGenerationFilename(), strings.NewReader(strconv.FormatUint(generation, 10)))
+}
@@ -0,0 +1,56 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_Generation(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname)
+
+	kv, err := NewKeyValues(dir, GobExt)
+	testo.Error(t, err, false)
+
+	g, err := kv.Generation()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(g.Id), 36)
+
+	tests := []struct {
+		mutate func() error
+		bump   bool
+	}{
+		{func() error { return kv.Set("generation", strings.NewReader("1")) }, true},
+		{func() error { return kv.Set("generation", strings.NewReader("1")) }, false},
+		{func() error { return kv.Set("generation", strings.NewReader("2")) }, true},
+		{func() error { _, err := kv.Cut("key-that-doesnt-exist"); return err }, false},
+		{func() error { _, err := kv.Cut("generation"); return err }, true},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			testo.Error(t, tt.mutate(), false)
+			ng, err := kv.Generation()
+			testo.Error(t, err, false)
+			testo.EqualValues(t, ng.Id, g.Id)
+			testo.EqualValues(t, ng.N > g.N, tt.bump)
+			g = ng
+		})
+	}
+
+	// a separate connection reads the same id and generation from disk
+	okv, err := connect(dir, GobExt)
+	testo.Error(t, err, false)
+
+	og, err := okv.Generation()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, og, g)
+	testo.EqualValues(t, og.String(), g.Id+"-"+strconv.FormatUint(g.N, 10))
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, okv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,47 @@
+package kevlar
+
+import "io"
+
+// GetIfModifiedSince returns the value same as Get when it has been created
+// or updated after the timestamp (e.g. Modified of the previously read
+// Record). Unchanged values return nil reader and false. Timestamps have
+// second resolution, so changes within the same second as ts are not
+// reported
+func (kv *keyValues) GetIfModifiedSince(key string, ts int64) (io.ReadCloser, bool, error) {
+	lmt, err := kv.lastModTime(key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// keys that don't exist are reported by Get
+	if lmt > 0 && lmt <= ts {
+		return nil, false, nil
+	}
+
+	rc, err := kv.Get(key)
+	if err != nil {
+		return nil, false, err
+	}
+	return rc, true, nil
+}
+
+// lastModTime returns the last create or update timestamp
+// of the key, or 0 if the key doesn't exist
+func (kv *keyValues) lastModTime(key string) (int64, error) {
+	if err := kv.refreshLogRecords(); err != nil {
+		return 0, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	created, updated := kv.currentLogRecords(key)
+	switch {
+	case updated != nil:
+		return updated.Ts, nil
+	case created != nil:
+		return created.Ts, nil
+	default:
+		return 0, nil
+	}
+}
@@ -0,0 +1,91 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyValues_GetIfModifiedSince(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("polled", strings.NewReader("value")), false)
+
+	record, ok := kv.GetRecord("polled")
+	testo.EqualValues(t, ok, true)
+
+	tests := []struct {
+		ts       int64
+		modified bool
+	}{
+		{0, true},
+		{record.Modified - 1, true},
+		{record.Modified, false},
+		{record.Modified + 1, false},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			rc, modified, err := kv.GetIfModifiedSince("polled", tt.ts)
+			testo.Error(t, err, false)
+			testo.EqualValues(t, modified, tt.modified)
+			testo.EqualValues(t, rc != nil, tt.modified)
+			if rc != nil {
+				data, err := io.ReadAll(rc)
+				testo.Error(t, err, false)
+				testo.Error(t, rc.Close(), false)
+				testo.EqualValues(t, string(data), "value")
+			}
+		})
+	}
+
+	_, _, err = kv.GetIfModifiedSince("key-that-doesnt-exist", 0)
+	testo.EqualValues(t, os.IsNotExist(err), true)
+
+	ok, err = kv.Cut("polled")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_GetIfModifiedSinceRecreated(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("recreated", strings.NewReader("1")), false)
+	testo.Error(t, kv.Set("recreated", strings.NewReader("2")), false)
+	_, err = kv.Cut("recreated")
+	testo.Error(t, err, false)
+	testo.Error(t, kv.Set("recreated", strings.NewReader("3")), false)
+
+	record, ok := kv.GetRecord("recreated")
+	testo.EqualValues(t, ok, true)
+
+	// timestamps have second resolution
+	time.Sleep(time.Second)
+	testo.Error(t, kv.Set("recreated", strings.NewReader("4")), false)
+
+	rc, modified, err := kv.GetIfModifiedSince("recreated", record.Modified)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, modified, true)
+	testo.EqualValues(t, rc != nil, true)
+	testo.Error(t, rc.Close(), false)
+
+	updated, err := kv.UpdatedAfter(record.Modified + 1)
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, updated, []string{"recreated"})
+
+	_, err = kv.Cut("recreated")
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,67 @@
+package kevlar
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// getManyWorkers is the number of values opened concurrently by GetMany.
+// Opening values is I/O bound (e.g. on network filesystems), so this
+// doesn't depend on the number of CPUs
+const getManyWorkers = 8
+
+// GetMany opens values concurrently and returns a map of key to value.
+// Keys without values are not included. When any value can't be opened,
+// values that have been opened are closed and the error is returned.
+// Callers are expected to close all returned values
+func GetMany(kv KeyValues, keys []string) (map[string]io.ReadCloser, error) {
+	values := make(map[string]io.ReadCloser, len(keys))
+	mtx := new(sync.Mutex)
+
+	keysCh := make(chan string)
+	errs := make(chan error, len(keys))
+
+	wg := new(sync.WaitGroup)
+	for ii := 0; ii < min(getManyWorkers, len(keys)); ii++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keysCh {
+				rc, err := kv.Get(key)
+				if os.IsNotExist(err) {
+					continue
+				} else if err != nil {
+					errs <- err
+					continue
+				}
+				mtx.Lock()
+				if _, ok := values[key]; ok {
+					// duplicate keys are opened once
+					rc.Close()
+				} else {
+					values[key] = rc
+				}
+				mtx.Unlock()
+			}
+		}()
+	}
+
+	for _, key := range keys {
+		keysCh <- key
+	}
+	close(keysCh)
+
+	wg.Wait()
+	close(errs)
+
+	if err, ok := <-errs; ok {
+		for _, rc := range values {
+			err = errors.Join(err, rc.Close())
+		}
+		return nil, err
+	}
+
+	return values, nil
+}
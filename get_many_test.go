@@ -0,0 +1,44 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_GetMany(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname, "get-many")
+	testo.Error(t, os.MkdirAll(dir, 0755), false)
+
+	kv, err := NewKeyValues(dir, GobExt)
+	testo.Error(t, err, false)
+
+	keys := make([]string, 0, 20)
+	for ii := 0; ii < 20; ii++ {
+		key := strconv.Itoa(ii)
+		testo.Error(t, kv.Set(key, strings.NewReader(key)), false)
+		keys = append(keys, key)
+	}
+
+	values, err := GetMany(kv, append(keys, "missing", "1"))
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(values), len(keys))
+
+	for key, rc := range values {
+		data, err := io.ReadAll(rc)
+		testo.Error(t, err, false)
+		testo.Error(t, rc.Close(), false)
+		testo.EqualValues(t, string(data), key)
+	}
+
+	values, err = GetMany(kv, nil)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(values), 0)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, os.RemoveAll(dir), false)
+}
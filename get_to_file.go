@@ -0,0 +1,25 @@
+package kevlar
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// GetToFile copies the value to the file at the provided path, creating
+// or truncating it with the provided permissions
+func GetToFile(kv KeyValues, key, path string, perm os.FileMode) error {
+	rc, err := kv.Get(key)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, rc)
+	return errors.Join(err, file.Close())
+}
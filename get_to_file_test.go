@@ -0,0 +1,39 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_GetToFile(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname)
+	kv, err := NewKeyValues(dir, JsonExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("to-file", strings.NewReader("{}")), false)
+
+	path := filepath.Join(dir, "get_to_file")
+	testo.Error(t, GetToFile(kv, "to-file", path, 0600), false)
+
+	bts, err := os.ReadFile(path)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, string(bts), "{}")
+
+	fi, err := os.Stat(path)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, fi.Mode().Perm(), os.FileMode(0600))
+
+	testo.Error(t, GetToFile(kv, "key-that-doesnt-exist", path, 0600), true)
+
+	testo.Error(t, os.Remove(path), false)
+
+	ok, err := kv.Cut("to-file")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
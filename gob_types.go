@@ -0,0 +1,84 @@
+package kevlar
+
+import (
+	"encoding/gob"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+var ErrNoGobTypes = errors.New("kevlar: no gob types registered")
+
+var (
+	gobTypes    = make([]reflect.Type, 0)
+	gobTypesMtx = new(sync.Mutex)
+)
+
+// RegisterGobTypes registers concrete types stored in gob values. Types are
+// registered with gob.Register (so they can be used as interface values) and
+// are used by VetGob to validate that stored values can be decoded
+func RegisterGobTypes(values ...any) {
+	gobTypesMtx.Lock()
+	defer gobTypesMtx.Unlock()
+
+	for _, value := range values {
+		gob.Register(value)
+
+		vt := reflect.TypeOf(value)
+		registered := false
+		for _, gt := range gobTypes {
+			if gt == vt {
+				registered = true
+				break
+			}
+		}
+		if !registered {
+			gobTypes = append(gobTypes, vt)
+		}
+	}
+}
+
+// VetGob attempts to decode every value into each registered type and
+// reports keys that can't be decoded into any of them, with the last
+// decoding error
+func VetGob(kv KeyValues) (map[string]error, error) {
+	gobTypesMtx.Lock()
+	types := make([]reflect.Type, len(gobTypes))
+	copy(types, gobTypes)
+	gobTypesMtx.Unlock()
+
+	if len(types) == 0 {
+		return nil, ErrNoGobTypes
+	}
+
+	keys, err := kv.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	undecodable := make(map[string]error)
+
+	for _, key := range keys {
+		var decodeErr error
+		for _, gt := range types {
+			if decodeErr = decodeGob(kv, key, gt); decodeErr == nil {
+				break
+			}
+		}
+		if decodeErr != nil {
+			undecodable[key] = decodeErr
+		}
+	}
+
+	return undecodable, nil
+}
+
+func decodeGob(kv KeyValues, key string, gt reflect.Type) error {
+	rc, err := kv.Get(key)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return gob.NewDecoder(rc).DecodeValue(reflect.New(gt))
+}
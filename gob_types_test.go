@@ -0,0 +1,51 @@
+package kevlar
+
+import (
+	"bytes"
+	"encoding/gob"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type gobTestRecord struct {
+	Title string
+	Year  int
+}
+
+func TestKeyValues_VetGob(t *testing.T) {
+	registeredTypes := gobTypes
+	gobTypes = nil
+	defer func() { gobTypes = registeredTypes }()
+
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	_, err = VetGob(kv)
+	testo.Error(t, err, true)
+
+	RegisterGobTypes(gobTestRecord{}, gobTestRecord{})
+	testo.EqualValues(t, len(gobTypes), 1)
+
+	buf := new(bytes.Buffer)
+	testo.Error(t, gob.NewEncoder(buf).Encode(gobTestRecord{Title: "title", Year: 2024}), false)
+
+	testo.Error(t, kv.Set("decodable", buf), false)
+	testo.Error(t, kv.Set("undecodable", strings.NewReader("not gob")), false)
+
+	undecodable, err := VetGob(kv)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(undecodable), 1)
+	testo.Error(t, undecodable["undecodable"], true)
+
+	for _, key := range []string{"decodable", "undecodable"} {
+		ok, err := kv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
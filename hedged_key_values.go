@@ -0,0 +1,131 @@
+package kevlar
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrNoReplicas = errors.New("kevlar: no replicas to hedge")
+
+// hedgedKeyValues reads from replicas with hedged requests,
+// other methods are passed to the primary replica
+type hedgedKeyValues struct {
+	KeyValues
+	replicas []KeyValues
+	delay    time.Duration
+}
+
+// HedgedKeyValues improves tail latency of reads from replicas of the same
+// values (e.g. served over the network): reads (Keys, Has, Get,
+// GetIfModifiedSince) are sent to the first replica and, when it
+// hasn't responded after the delay or has failed, to the next one. The first
+// successful response is returned and values opened by slower replicas are
+// closed. Writes and other methods are passed to the first (primary) replica,
+// replicas are expected to be kept in sync elsewhere
+func HedgedKeyValues(replicas []KeyValues, delay time.Duration) (KeyValues, error) {
+	if len(replicas) == 0 {
+		return nil, ErrNoReplicas
+	}
+	return &hedgedKeyValues{
+		KeyValues: replicas[0],
+		replicas:  replicas,
+		delay:     delay,
+	}, nil
+}
+
+type hedgedResult[T any] struct {
+	val T
+	err error
+}
+
+// hedge calls fn with the first replica, then with the next replica every
+// time the delay passes or a replica fails, and returns the first successful
+// result. Successful results that arrive later are passed to abandon. When
+// every replica fails, the first error is returned
+func hedge[T any](replicas []KeyValues, delay time.Duration, fn func(kv KeyValues) (T, error), abandon func(T)) (T, error) {
+	results := make(chan hedgedResult[T], len(replicas))
+
+	launched := 0
+	launch := func() {
+		kv := replicas[launched]
+		launched++
+		go func() {
+			val, err := fn(kv)
+			results <- hedgedResult[T]{val: val, err: err}
+		}()
+	}
+
+	launch()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var errs []error
+	for {
+		select {
+		case result := <-results:
+			if result.err == nil {
+				if pending := launched - len(errs) - 1; pending > 0 && abandon != nil {
+					go func() {
+						for ii := 0; ii < pending; ii++ {
+							if late := <-results; late.err == nil {
+								abandon(late.val)
+							}
+						}
+					}()
+				}
+				return result.val, nil
+			}
+			errs = append(errs, result.err)
+			if launched < len(replicas) {
+				launch()
+			} else if len(errs) == launched {
+				var zero T
+				return zero, errs[0]
+			}
+		case <-timer.C:
+			if launched < len(replicas) {
+				launch()
+				timer.Reset(delay)
+			}
+		}
+	}
+}
+
+func (hkv *hedgedKeyValues) Keys() ([]string, error) {
+	return hedge(hkv.replicas, hkv.delay, func(kv KeyValues) ([]string, error) {
+		return kv.Keys()
+	}, nil)
+}
+
+func (hkv *hedgedKeyValues) Has(key string) (bool, error) {
+	return hedge(hkv.replicas, hkv.delay, func(kv KeyValues) (bool, error) {
+		return kv.Has(key)
+	}, nil)
+}
+
+func (hkv *hedgedKeyValues) Get(key string) (io.ReadCloser, error) {
+	return hedge(hkv.replicas, hkv.delay, func(kv KeyValues) (io.ReadCloser, error) {
+		return kv.Get(key)
+	}, func(rc io.ReadCloser) {
+		rc.Close()
+	})
+}
+
+type modifiedValue struct {
+	rc       io.ReadCloser
+	modified bool
+}
+
+func (hkv *hedgedKeyValues) GetIfModifiedSince(key string, ts int64) (io.ReadCloser, bool, error) {
+	mv, err := hedge(hkv.replicas, hkv.delay, func(kv KeyValues) (modifiedValue, error) {
+		rc, modified, err := kv.GetIfModifiedSince(key, ts)
+		return modifiedValue{rc: rc, modified: modified}, err
+	}, func(mv modifiedValue) {
+		if mv.rc != nil {
+			mv.rc.Close()
+		}
+	})
+	return mv.rc, mv.modified, err
+}
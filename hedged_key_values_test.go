@@ -0,0 +1,109 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowKeyValues delays or fails Get to simulate a slow or broken replica
+type slowKeyValues struct {
+	KeyValues
+	delay  time.Duration
+	err    error
+	closed *atomic.Int64
+}
+
+func (skv *slowKeyValues) Get(key string) (io.ReadCloser, error) {
+	time.Sleep(skv.delay)
+	if skv.err != nil {
+		return nil, skv.err
+	}
+	rc, err := skv.KeyValues.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return &closeCounter{ReadCloser: rc, closed: skv.closed}, nil
+}
+
+type closeCounter struct {
+	io.ReadCloser
+	closed *atomic.Int64
+}
+
+func (cc *closeCounter) Close() error {
+	cc.closed.Add(1)
+	return cc.ReadCloser.Close()
+}
+
+func TestHedgedKeyValues(t *testing.T) {
+	_, err := HedgedKeyValues(nil, time.Millisecond)
+	testo.EqualValues(t, err, ErrNoReplicas)
+
+	errReplica := errors.New("replica failed")
+
+	tests := []struct {
+		primaryDelay time.Duration
+		primaryErr   error
+		secondaryErr error
+		expErr       error
+	}{
+		{0, nil, nil, nil},
+		{time.Second, nil, nil, nil},
+		{0, errReplica, nil, nil},
+		{0, errReplica, errReplica, errReplica},
+	}
+
+	dir := filepath.Join(os.TempDir(), testsDirname)
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			kv, err := NewKeyValues(dir, GobExt)
+			testo.Error(t, err, false)
+			testo.Error(t, kv.Set("hedged", strings.NewReader("value")), false)
+
+			closed := new(atomic.Int64)
+			primary := &slowKeyValues{KeyValues: kv, delay: tt.primaryDelay, err: tt.primaryErr, closed: closed}
+			secondary := &slowKeyValues{KeyValues: kv, err: tt.secondaryErr, closed: closed}
+
+			hkv, err := HedgedKeyValues([]KeyValues{primary, secondary}, 10*time.Millisecond)
+			testo.Error(t, err, false)
+
+			start := time.Now()
+			rc, err := hkv.Get("hedged")
+			testo.EqualValues(t, errors.Is(err, tt.expErr), true)
+			// slow primary doesn't delay reads
+			testo.EqualValues(t, time.Since(start) < tt.primaryDelay || tt.primaryDelay == 0, true)
+
+			if tt.expErr == nil {
+				data, err := io.ReadAll(rc)
+				testo.Error(t, err, false)
+				testo.Error(t, rc.Close(), false)
+				testo.EqualValues(t, string(data), "value")
+			}
+
+			if tt.primaryDelay > 0 {
+				// value opened by the slow primary is closed
+				deadline := time.Now().Add(5 * time.Second)
+				for closed.Load() < 2 && time.Now().Before(deadline) {
+					time.Sleep(10 * time.Millisecond)
+				}
+				testo.EqualValues(t, closed.Load(), int64(2))
+			}
+
+			ok, err := kv.Cut("hedged")
+			testo.EqualValues(t, ok, true)
+			testo.Error(t, err, false)
+
+			testo.Error(t, kv.Close(), false)
+			testo.Error(t, logRecordsCleanup(), false)
+		})
+	}
+}
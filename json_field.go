@@ -0,0 +1,114 @@
+package kevlar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrUnknownJSONField = errors.New("kevlar: unknown JSON field")
+
+// GetJSONField extracts a single field from the JSON value without decoding
+// the whole document. Path segments are separated with dots, array elements
+// are addressed with indexes, e.g. "data.items.0.name". Empty path
+// returns the whole document
+func GetJSONField(kv KeyValues, key, path string) (json.RawMessage, error) {
+	rc, err := kv.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var segments []string
+	if path != "" {
+		segments = strings.Split(path, ".")
+	}
+
+	decoder := json.NewDecoder(rc)
+
+	if ok, err := seekJSONField(decoder, segments); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJSONField, path)
+	}
+
+	var raw json.RawMessage
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// seekJSONField advances decoder to the value at the path, skipping
+// everything else token by token
+func seekJSONField(decoder *json.Decoder, segments []string) (bool, error) {
+	for _, segment := range segments {
+		token, err := decoder.Token()
+		if err != nil {
+			return false, err
+		}
+
+		switch token {
+		case json.Delim('{'):
+			found := false
+			for decoder.More() {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return false, err
+				}
+				if keyToken == segment {
+					found = true
+					break
+				}
+				if err := skipJSONValue(decoder); err != nil {
+					return false, err
+				}
+			}
+			if !found {
+				return false, nil
+			}
+		case json.Delim('['):
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 {
+				return false, nil
+			}
+			for ii := 0; ii < index; ii++ {
+				if !decoder.More() {
+					return false, nil
+				}
+				if err := skipJSONValue(decoder); err != nil {
+					return false, err
+				}
+			}
+			if !decoder.More() {
+				return false, nil
+			}
+		default:
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func skipJSONValue(decoder *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
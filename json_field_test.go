@@ -0,0 +1,54 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_GetJSONField(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), JsonExt)
+	testo.Error(t, err, false)
+
+	document := `{
+		"skipped": {"nested": [1, {"deep": true}], "value": "x"},
+		"data": {"items": [{"name": "first"}, {"name": "second", "tags": ["a", "b"]}]},
+		"count": 2
+	}`
+	testo.Error(t, kv.Set("document", strings.NewReader(document)), false)
+
+	tests := []struct {
+		path   string
+		exp    string
+		expErr bool
+	}{
+		{"count", `2`, false},
+		{"data.items.1.name", `"second"`, false},
+		{"data.items.1.tags", `["a", "b"]`, false},
+		{"data.items.0", `{"name": "first"}`, false},
+		{"skipped.nested.1.deep", `true`, false},
+		{"data.items.2", "", true},
+		{"data.items.name", "", true},
+		{"count.value", "", true},
+		{"field-that-doesnt-exist", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			raw, err := GetJSONField(kv, "document", tt.path)
+			testo.Error(t, err, tt.expErr)
+			testo.EqualValues(t, errors.Is(err, ErrUnknownJSONField), tt.expErr)
+			testo.EqualValues(t, string(raw), tt.exp)
+		})
+	}
+
+	ok, err := kv.Cut("document")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
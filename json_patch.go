@@ -0,0 +1,84 @@
+package kevlar
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+)
+
+// PatchJSON applies JSON merge patch (RFC 7386) to the value and sets the
+// result. Missing values are patched as if they were null. The result is set
+// with SetIfMatch and the patch is applied again when the value has been
+// changed concurrently, so that concurrent patches are not lost
+func (kv *keyValues) PatchJSON(key string, patch []byte) error {
+	var mp any
+	decoder := json.NewDecoder(bytes.NewReader(patch))
+	decoder.UseNumber()
+	if err := decoder.Decode(&mp); err != nil {
+		return err
+	}
+
+	for {
+		target, hash, err := kv.getJSON(key)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(mergePatch(target, mp))
+		if err != nil {
+			return err
+		}
+
+		if ok, err := kv.SetIfMatch(key, bytes.NewReader(data), hash); err != nil || ok {
+			return err
+		}
+	}
+}
+
+// getJSON decodes the value and returns it with the hash of the value
+// to set the patched value with. The hash is read first, so that the value
+// changed after reading the hash doesn't match it
+func (kv *keyValues) getJSON(key string) (any, string, error) {
+	hash, err := kv.currentHash(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var target any
+
+	rc, err := kv.Get(key)
+	if err == nil {
+		defer rc.Close()
+		decoder := json.NewDecoder(rc)
+		decoder.UseNumber()
+		if err := decoder.Decode(&target); err != nil {
+			return nil, "", err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, "", err
+	}
+
+	return target, hash, nil
+}
+
+func mergePatch(target, patch any) any {
+	po, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	to, ok := target.(map[string]any)
+	if !ok {
+		to = make(map[string]any)
+	}
+
+	for key, value := range po {
+		if value == nil {
+			delete(to, key)
+		} else {
+			to[key] = mergePatch(to[key], value)
+		}
+	}
+
+	return to
+}
@@ -0,0 +1,95 @@
+package kevlar
+
+import (
+	"encoding/json"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	// examples from RFC 7386 Appendix A
+	tests := []struct {
+		target, patch, exp string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			var target, patch any
+			testo.Error(t, json.Unmarshal([]byte(tt.target), &target), false)
+			testo.Error(t, json.Unmarshal([]byte(tt.patch), &patch), false)
+
+			data, err := json.Marshal(mergePatch(target, patch))
+			testo.Error(t, err, false)
+			testo.EqualValues(t, string(data), tt.exp)
+		})
+	}
+}
+
+func TestKeyValues_PatchJSON(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), JsonExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.PatchJSON("patched", []byte(`{"a":1}`)), false)
+	testo.Error(t, kv.PatchJSON("patched", []byte(`{"b":12345678901234567890}`)), false)
+	testo.Error(t, kv.PatchJSON("patched", []byte(`not json`)), true)
+
+	preview, err := Preview(kv, "patched", 1024)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, string(preview), `{"a":1,"b":12345678901234567890}`)
+
+	ok, err := kv.Cut("patched")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_PatchJSONConcurrently(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), JsonExt)
+	testo.Error(t, err, false)
+
+	const patches = 10
+
+	wg := new(sync.WaitGroup)
+	for ii := 0; ii < patches; ii++ {
+		wg.Add(1)
+		go func(ii int) {
+			defer wg.Done()
+			testo.Error(t, kv.PatchJSON("patched", []byte(`{"k`+strconv.Itoa(ii)+`":true}`)), false)
+		}(ii)
+	}
+	wg.Wait()
+
+	// every patch is applied
+	var patched map[string]bool
+	testo.Error(t, json.Unmarshal([]byte(readValue(t, kv, "patched")), &patched), false)
+	testo.EqualValues(t, len(patched), patches)
+
+	ok, err := kv.Cut("patched")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,149 @@
+package kevlartest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/boggydigital/kevlar"
+	"golang.org/x/exp/slices"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var ErrFault = errors.New("kevlartest: injected fault")
+
+// FaultPolicy configures failures injected by FaultyKeyValues
+type FaultPolicy struct {
+	// Probability of a fault for every call of a faulty method, from 0 to 1
+	Probability float64
+	// Methods limits faults to these methods (e.g. "Set", "Get"),
+	// by default faults are injected in all reads and writes
+	Methods []string
+	// Err is returned by faults, ErrFault by default
+	Err error
+	// NoSpace makes write faults return kevlar.ErrNoSpace wrapping ENOSPC,
+	// same as the filesystem store does when the device is full
+	NoSpace bool
+	// ReadLatency is added to every Read of a value returned by Get
+	ReadLatency time.Duration
+	// Seed makes faults reproducible between runs
+	Seed uint64
+}
+
+type faultyKeyValues struct {
+	kevlar.KeyValues
+	policy FaultPolicy
+	rndMtx sync.Mutex
+	rnd    *rand.Rand
+}
+
+// FaultyKeyValues wraps a real store and injects failures in reads
+// (Keys, Has, Get) and writes (Set, SetMany, SetFromFile, Cut,
+// CutMany) according to the policy. Other methods are passed through
+func FaultyKeyValues(kv kevlar.KeyValues, policy FaultPolicy) kevlar.KeyValues {
+	if policy.Err == nil {
+		policy.Err = ErrFault
+	}
+	return &faultyKeyValues{
+		KeyValues: kv,
+		policy:    policy,
+		rnd:       rand.New(rand.NewPCG(policy.Seed, 0)),
+	}
+}
+
+func (fkv *faultyKeyValues) fault(method string, write bool) error {
+	if len(fkv.policy.Methods) > 0 && !slices.Contains(fkv.policy.Methods, method) {
+		return nil
+	}
+
+	fkv.rndMtx.Lock()
+	faulty := fkv.rnd.Float64() < fkv.policy.Probability
+	fkv.rndMtx.Unlock()
+
+	if !faulty {
+		return nil
+	}
+	if write && fkv.policy.NoSpace {
+		return fmt.Errorf("%w: %w", kevlar.ErrNoSpace, syscall.ENOSPC)
+	}
+	return fkv.policy.Err
+}
+
+func (fkv *faultyKeyValues) Keys() ([]string, error) {
+	if err := fkv.fault("Keys", false); err != nil {
+		return nil, err
+	}
+	return fkv.KeyValues.Keys()
+}
+
+func (fkv *faultyKeyValues) Has(key string) (bool, error) {
+	if err := fkv.fault("Has", false); err != nil {
+		return false, err
+	}
+	return fkv.KeyValues.Has(key)
+}
+
+func (fkv *faultyKeyValues) Get(key string) (io.ReadCloser, error) {
+	if err := fkv.fault("Get", false); err != nil {
+		return nil, err
+	}
+	rc, err := fkv.KeyValues.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return fkv.slow(rc), nil
+}
+
+func (fkv *faultyKeyValues) Set(key string, data io.Reader) error {
+	if err := fkv.fault("Set", true); err != nil {
+		return err
+	}
+	return fkv.KeyValues.Set(key, data)
+}
+
+func (fkv *faultyKeyValues) SetMany(keyReaders map[string]io.Reader) error {
+	if err := fkv.fault("SetMany", true); err != nil {
+		return err
+	}
+	return fkv.KeyValues.SetMany(keyReaders)
+}
+
+func (fkv *faultyKeyValues) SetFromFile(key, path string, move bool) error {
+	if err := fkv.fault("SetFromFile", true); err != nil {
+		return err
+	}
+	return fkv.KeyValues.SetFromFile(key, path, move)
+}
+
+func (fkv *faultyKeyValues) Cut(key string) (bool, error) {
+	if err := fkv.fault("Cut", true); err != nil {
+		return false, err
+	}
+	return fkv.KeyValues.Cut(key)
+}
+
+func (fkv *faultyKeyValues) CutMany(keys ...string) (map[string]bool, error) {
+	if err := fkv.fault("CutMany", true); err != nil {
+		return nil, err
+	}
+	return fkv.KeyValues.CutMany(keys...)
+}
+
+func (fkv *faultyKeyValues) slow(rc io.ReadCloser) io.ReadCloser {
+	if fkv.policy.ReadLatency <= 0 {
+		return rc
+	}
+	return &slowReadCloser{ReadCloser: rc, latency: fkv.policy.ReadLatency}
+}
+
+type slowReadCloser struct {
+	io.ReadCloser
+	latency time.Duration
+}
+
+func (src *slowReadCloser) Read(p []byte) (int, error) {
+	time.Sleep(src.latency)
+	return src.ReadCloser.Read(p)
+}
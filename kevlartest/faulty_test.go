@@ -0,0 +1,83 @@
+package kevlartest
+
+import (
+	"errors"
+	"github.com/boggydigital/kevlar"
+	"github.com/boggydigital/testo"
+	"io"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFaultyKeyValues(t *testing.T) {
+	tests := []struct {
+		policy   FaultPolicy
+		setErr   error
+		getErr   error
+		failures bool
+	}{
+		{FaultPolicy{}, nil, nil, false},
+		{FaultPolicy{Probability: 1}, ErrFault, ErrFault, true},
+		{FaultPolicy{Probability: 1, Methods: []string{"Get"}}, nil, ErrFault, true},
+		{FaultPolicy{Probability: 1, NoSpace: true}, kevlar.ErrNoSpace, ErrFault, true},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			kv := NewKeyValues()
+			testo.Error(t, kv.Set("k1", strings.NewReader("v1")), false)
+
+			fkv := FaultyKeyValues(kv, tt.policy)
+
+			err := fkv.Set("k2", strings.NewReader("v2"))
+			testo.EqualValues(t, errors.Is(err, tt.setErr), true)
+			testo.EqualValues(t, err != nil, tt.setErr != nil)
+
+			_, err = fkv.Get("k1")
+			testo.EqualValues(t, errors.Is(err, tt.getErr), true)
+			testo.EqualValues(t, err != nil, tt.getErr != nil)
+
+			// faults never reach the wrapped store
+			testo.EqualValues(t, len(kv.Calls("Get")) == 0, tt.getErr != nil)
+		})
+	}
+}
+
+func TestFaultyKeyValues_NoSpace(t *testing.T) {
+	fkv := FaultyKeyValues(NewKeyValues(), FaultPolicy{Probability: 1, NoSpace: true})
+	testo.EqualValues(t, errors.Is(fkv.Set("k1", strings.NewReader("v1")), syscall.ENOSPC), true)
+}
+
+func TestFaultyKeyValues_Probability(t *testing.T) {
+	fkv := FaultyKeyValues(NewKeyValues(), FaultPolicy{Probability: 0.5, Seed: 1})
+
+	faults := 0
+	for ii := 0; ii < 1000; ii++ {
+		if _, err := fkv.Has("k1"); err != nil {
+			faults++
+		}
+	}
+
+	testo.EqualValues(t, faults > 400 && faults < 600, true)
+}
+
+func TestFaultyKeyValues_ReadLatency(t *testing.T) {
+	kv := NewKeyValues()
+	testo.Error(t, kv.Set("k1", strings.NewReader("v1")), false)
+
+	fkv := FaultyKeyValues(kv, FaultPolicy{ReadLatency: 10 * time.Millisecond})
+
+	rc, err := fkv.Get("k1")
+	testo.Error(t, err, false)
+
+	start := time.Now()
+	data, err := io.ReadAll(rc)
+	testo.Error(t, err, false)
+	testo.Error(t, rc.Close(), false)
+
+	testo.EqualValues(t, string(data), "v1")
+	testo.EqualValues(t, time.Since(start) >= 10*time.Millisecond, true)
+}
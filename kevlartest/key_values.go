@@ -0,0 +1,1031 @@
+package kevlartest
+
+import (
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/boggydigital/kevlar"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeyValues is an in-memory kevlar.KeyValues double. Values, timestamps
+// and annotations are kept in memory and every method call is recorded
+// by the embedded Spy. Methods that depend on storage internals (e.g.
+// Snapshot, ApplyRetention) return errors.ErrUnsupported
+type KeyValues struct {
+	Spy
+
+	mtx      sync.Mutex
+	values   map[string][]byte
+	created  map[string]int64
+	modified map[string]int64
+	derived  map[string]map[string][]byte
+	refresh  map[string]int64
+	expires  map[string]int64
+	pinned   map[string]int64
+	frozen   bool
+
+	id         string
+	generation uint64
+}
+
+var _ kevlar.KeyValues = (*KeyValues)(nil)
+
+func NewKeyValues() *KeyValues {
+	return &KeyValues{
+		values:   make(map[string][]byte),
+		created:  make(map[string]int64),
+		modified: make(map[string]int64),
+		derived:  make(map[string]map[string][]byte),
+		refresh:  make(map[string]int64),
+		expires:  make(map[string]int64),
+		pinned:   make(map[string]int64),
+	}
+}
+
+func notExist(op, key string) error {
+	return &fs.PathError{Op: op, Path: key, Err: fs.ErrNotExist}
+}
+
+func (kv *KeyValues) keys() []string {
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	keys := maps.Keys(kv.values)
+	slices.Sort(keys)
+	return keys
+}
+
+func (kv *KeyValues) value(key string) ([]byte, bool) {
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	val, ok := kv.values[key]
+	return val, ok
+}
+
+func (kv *KeyValues) Keys() ([]string, error) {
+	if err := kv.call("Keys"); err != nil {
+		return nil, err
+	}
+	return kv.keys(), nil
+}
+
+func (kv *KeyValues) KeysWithPrefix(prefix string) ([]string, error) {
+	if err := kv.call("KeysWithPrefix", prefix); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0)
+	for _, key := range kv.keys() {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (kv *KeyValues) KeysMatching(pattern string) ([]string, error) {
+	if err := kv.call("KeysMatching", pattern); err != nil {
+		return nil, err
+	}
+
+	match, err := kevlar.CompileKeyPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0)
+	for _, key := range kv.keys() {
+		if match(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (kv *KeyValues) KeysPage(offset, limit int) ([]string, error) {
+	if err := kv.call("KeysPage", offset, limit); err != nil {
+		return nil, err
+	}
+
+	keys := kv.keys()
+	slices.Sort(keys)
+
+	if offset < 0 || limit <= 0 || offset >= len(keys) {
+		return []string{}, nil
+	}
+	return keys[offset:min(offset+limit, len(keys))], nil
+}
+
+func (kv *KeyValues) KeysSorted(by kevlar.SortField, desc bool) ([]string, error) {
+	if err := kv.call("KeysSorted", by, desc); err != nil {
+		return nil, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	tss := make(map[string]int64, len(kv.values))
+	for key := range kv.values {
+		tss[key] = kv.created[key]
+		if by == kevlar.Modified {
+			tss[key] = max(kv.created[key], kv.modified[key])
+		}
+	}
+
+	keys := maps.Keys(tss)
+	slices.SortFunc(keys, func(a, b string) int {
+		c := cmp.Compare(tss[a], tss[b])
+		if desc {
+			c = -c
+		}
+		if c == 0 {
+			c = cmp.Compare(a, b)
+		}
+		return c
+	})
+	return keys, nil
+}
+
+func (kv *KeyValues) Len() (int, error) {
+	if err := kv.call("Len"); err != nil {
+		return 0, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	return len(kv.values), nil
+}
+
+func (kv *KeyValues) Has(key string) (bool, error) {
+	if err := kv.call("Has", key); err != nil {
+		return false, err
+	}
+	_, ok := kv.value(key)
+	return ok, nil
+}
+
+// SampleKeys returns up to n first keys in lexical order,
+// so that tests are deterministic
+func (kv *KeyValues) SampleKeys(n int) ([]string, error) {
+	if err := kv.call("SampleKeys", n); err != nil {
+		return nil, err
+	}
+	keys := kv.keys()
+	return keys[:max(0, min(n, len(keys)))], nil
+}
+
+func (kv *KeyValues) get(key string) (io.ReadCloser, error) {
+	val, ok := kv.value(key)
+	if !ok {
+		return nil, notExist("open", key)
+	}
+	return io.NopCloser(bytes.NewReader(val)), nil
+}
+
+func (kv *KeyValues) Get(key string) (io.ReadCloser, error) {
+	if err := kv.call("Get", key); err != nil {
+		return nil, err
+	}
+	return kv.get(key)
+}
+
+func (kv *KeyValues) GetContext(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := kv.call("GetContext", ctx, key); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return kv.get(key)
+}
+
+func (kv *KeyValues) GetIfModifiedSince(key string, ts int64) (io.ReadCloser, bool, error) {
+	if err := kv.call("GetIfModifiedSince", key, ts); err != nil {
+		return nil, false, err
+	}
+
+	kv.mtx.Lock()
+	lmt := max(kv.created[key], kv.modified[key])
+	kv.mtx.Unlock()
+
+	if lmt > 0 && lmt <= ts {
+		return nil, false, nil
+	}
+
+	rc, err := kv.get(key)
+	if err != nil {
+		return nil, false, err
+	}
+	return rc, true, nil
+}
+
+func (kv *KeyValues) GetArchive(w io.Writer, keys ...string) error {
+	if err := kv.call("GetArchive", w, keys); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		keys = kv.keys()
+	}
+	return kv.archive(w, keys)
+}
+
+func (kv *KeyValues) archive(w io.Writer, keys []string) error {
+	zw := zip.NewWriter(w)
+	for _, key := range keys {
+		val, ok := kv.value(key)
+		if !ok {
+			return notExist("open", key)
+		}
+		fw, err := zw.Create(key)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(val); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (kv *KeyValues) RenderValue(key string, data any, w io.Writer) error {
+	if err := kv.call("RenderValue", key, data, w); err != nil {
+		return err
+	}
+	return errors.ErrUnsupported
+}
+
+func (kv *KeyValues) set(key string, reader io.Reader) error {
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	return kv.setLocked(key, reader)
+}
+
+// setLocked sets the value, expects values to be locked
+func (kv *KeyValues) setLocked(key string, reader io.Reader) error {
+	val, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	if kv.frozen {
+		return kevlar.ErrFrozen
+	}
+
+	now := time.Now().Unix()
+	if current, ok := kv.values[key]; !ok {
+		kv.created[key] = now
+	} else if bytes.Equal(current, val) {
+		return nil
+	} else {
+		kv.modified[key] = now
+	}
+	kv.values[key] = val
+	kv.generation++
+
+	return nil
+}
+
+func (kv *KeyValues) Set(key string, data io.Reader) error {
+	if err := kv.call("Set", key, data); err != nil {
+		return err
+	}
+	return kv.set(key, data)
+}
+
+// SetContext reads the data before setting the value, so
+// that the context can be checked once the data is read
+func (kv *KeyValues) SetContext(ctx context.Context, key string, data io.Reader) error {
+	if err := kv.call("SetContext", ctx, key, data); err != nil {
+		return err
+	}
+	val, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	return kv.set(key, bytes.NewReader(val))
+}
+
+func (kv *KeyValues) SetIfMatch(key string, data io.Reader, expectedHash string) (bool, error) {
+	if err := kv.call("SetIfMatch", key, data, expectedHash); err != nil {
+		return false, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	currentHash := ""
+	if val, ok := kv.values[key]; ok {
+		var err error
+		if currentHash, err = kevlar.Sha256(bytes.NewReader(val)); err != nil {
+			return false, err
+		}
+	}
+	if currentHash != expectedHash {
+		return false, nil
+	}
+
+	return true, kv.setLocked(key, data)
+}
+
+func (kv *KeyValues) SetWithPolicy(key string, data io.Reader, policy kevlar.ConflictPolicy, ts int64) (string, error) {
+	if err := kv.call("SetWithPolicy", key, data, policy, ts); err != nil {
+		return "", err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	if policy == kevlar.AppendVersion {
+		return kv.setVersionLocked(key, data)
+	}
+
+	if _, ok := kv.values[key]; ok {
+		switch policy {
+		case kevlar.Overwrite:
+		case kevlar.ErrorIfExists:
+			return "", fmt.Errorf("%w: %s", kevlar.ErrKeyExists, key)
+		case kevlar.KeepNewest:
+			if max(kv.created[key], kv.modified[key]) > ts {
+				return "", nil
+			}
+		default:
+			return "", fmt.Errorf("kevlar: %s", policy)
+		}
+	}
+
+	return key, kv.setLocked(key, data)
+}
+
+// setVersionLocked sets the value as the version following
+// the latest existing version of the key
+func (kv *KeyValues) setVersionLocked(key string, data io.Reader) (string, error) {
+	// version keys are the key, separator and number, e.g. key~2
+	versionPrefix := strings.TrimSuffix(kevlar.VersionKey(key, 2), "2")
+
+	latest := 0
+	for vk := range kv.values {
+		if vk == key {
+			latest = max(latest, 1)
+		} else if suffix, ok := strings.CutPrefix(vk, versionPrefix); ok {
+			if n, err := strconv.Atoi(suffix); err == nil {
+				latest = max(latest, n)
+			}
+		}
+	}
+
+	versionKey := kevlar.VersionKey(key, latest+1)
+	return versionKey, kv.setLocked(versionKey, data)
+}
+
+func (kv *KeyValues) SetMany(keyReaders map[string]io.Reader) error {
+	if err := kv.call("SetMany", keyReaders); err != nil {
+		return err
+	}
+	for key, reader := range keyReaders {
+		if err := kv.set(key, reader); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (kv *KeyValues) SetWriter(key string) (io.WriteCloser, error) {
+	if err := kv.call("SetWriter", key); err != nil {
+		return nil, err
+	}
+	return &setWriter{kv: kv, key: key, buf: new(bytes.Buffer)}, nil
+}
+
+type setWriter struct {
+	kv  *KeyValues
+	key string
+	buf *bytes.Buffer
+}
+
+func (sw *setWriter) Write(p []byte) (int, error) {
+	if sw.buf == nil {
+		return 0, os.ErrClosed
+	}
+	return sw.buf.Write(p)
+}
+
+func (sw *setWriter) Close() error {
+	if sw.buf == nil {
+		return os.ErrClosed
+	}
+	buf := sw.buf
+	sw.buf = nil
+	return sw.kv.set(sw.key, buf)
+}
+
+func (kv *KeyValues) Append(key string, data io.Reader) error {
+	if err := kv.call("Append", key, data); err != nil {
+		return err
+	}
+	val, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	current, _ := kv.value(key)
+	return kv.set(key, io.MultiReader(bytes.NewReader(current), bytes.NewReader(val)))
+}
+
+func (kv *KeyValues) SetFromFile(key, path string, move bool) error {
+	if err := kv.call("SetFromFile", key, path, move); err != nil {
+		return err
+	}
+	val, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := kv.set(key, bytes.NewReader(val)); err != nil {
+		return err
+	}
+	if move {
+		return os.Remove(path)
+	}
+	return nil
+}
+
+func (kv *KeyValues) PatchJSON(key string, patch []byte) error {
+	if err := kv.call("PatchJSON", key, patch); err != nil {
+		return err
+	}
+	return errors.ErrUnsupported
+}
+
+// SeedFrom sets values from every file in fsys, keys are file paths
+// (without storage extension, since doubles don't have one)
+func (kv *KeyValues) SeedFrom(fsys fs.FS, overwrite bool) error {
+	if err := kv.call("SeedFrom", fsys, overwrite); err != nil {
+		return err
+	}
+	return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		key := path.Clean(p)
+		if _, ok := kv.value(key); ok && !overwrite {
+			return nil
+		}
+		val, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		return kv.set(key, bytes.NewReader(val))
+	})
+}
+
+func (kv *KeyValues) SetArchive(r io.ReaderAt, size int64, skipCorrupt bool) (map[string]error, error) {
+	if err := kv.call("SetArchive", r, size, skipCorrupt); err != nil {
+		return nil, err
+	}
+	return nil, errors.ErrUnsupported
+}
+
+func (kv *KeyValues) cut(key string) (bool, error) {
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	if kv.frozen {
+		return false, kevlar.ErrFrozen
+	}
+	if _, ok := kv.values[key]; !ok {
+		return false, nil
+	}
+
+	delete(kv.values, key)
+	delete(kv.created, key)
+	delete(kv.modified, key)
+	delete(kv.derived, key)
+	delete(kv.refresh, key)
+	delete(kv.expires, key)
+	delete(kv.pinned, key)
+	kv.generation++
+
+	return true, nil
+}
+
+func (kv *KeyValues) Cut(key string) (bool, error) {
+	if err := kv.call("Cut", key); err != nil {
+		return false, err
+	}
+	return kv.cut(key)
+}
+
+func (kv *KeyValues) CutContext(ctx context.Context, key string) (bool, error) {
+	if err := kv.call("CutContext", ctx, key); err != nil {
+		return false, err
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return kv.cut(key)
+}
+
+func (kv *KeyValues) CutMany(keys ...string) (map[string]bool, error) {
+	if err := kv.call("CutMany", keys); err != nil {
+		return nil, err
+	}
+	cut := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		ok, err := kv.cut(key)
+		if err != nil {
+			return cut, err
+		}
+		cut[key] = ok
+	}
+	return cut, nil
+}
+
+func (kv *KeyValues) Copy(src, dst string) error {
+	if err := kv.call("Copy", src, dst); err != nil {
+		return err
+	}
+	val, ok := kv.value(src)
+	if !ok {
+		return fmt.Errorf("%w: %s", kevlar.ErrUnknownKey, src)
+	}
+	return kv.set(dst, bytes.NewReader(val))
+}
+
+func (kv *KeyValues) Rename(src, dst string) error {
+	if err := kv.call("Rename", src, dst); err != nil {
+		return err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	if kv.frozen {
+		return kevlar.ErrFrozen
+	}
+	if _, ok := kv.values[src]; !ok {
+		return fmt.Errorf("%w: %s", kevlar.ErrUnknownKey, src)
+	}
+	if src == dst {
+		return nil
+	}
+
+	for _, m := range []map[string]int64{kv.created, kv.modified, kv.refresh, kv.expires, kv.pinned} {
+		if ts, ok := m[src]; ok {
+			m[dst] = ts
+		} else {
+			delete(m, dst)
+		}
+		delete(m, src)
+	}
+
+	kv.values[dst] = kv.values[src]
+	delete(kv.values, src)
+	kv.generation++
+
+	if derived, ok := kv.derived[src]; ok {
+		kv.derived[dst] = derived
+	} else {
+		delete(kv.derived, dst)
+	}
+	delete(kv.derived, src)
+
+	return nil
+}
+
+func (kv *KeyValues) SetDerived(key, variant string, data io.Reader) error {
+	if err := kv.call("SetDerived", key, variant, data); err != nil {
+		return err
+	}
+	val, err := io.ReadAll(data)
+	if err != nil {
+		return err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	if kv.frozen {
+		return kevlar.ErrFrozen
+	}
+	if _, ok := kv.values[key]; !ok {
+		return fmt.Errorf("%w: %s", kevlar.ErrUnknownKey, key)
+	}
+	if kv.derived[key] == nil {
+		kv.derived[key] = make(map[string][]byte)
+	}
+	kv.derived[key][variant] = val
+
+	return nil
+}
+
+func (kv *KeyValues) GetDerived(key, variant string) (io.ReadCloser, error) {
+	if err := kv.call("GetDerived", key, variant); err != nil {
+		return nil, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	if _, ok := kv.values[key]; !ok {
+		return nil, fmt.Errorf("%w: %s", kevlar.ErrUnknownKey, key)
+	}
+	val, ok := kv.derived[key][variant]
+	if !ok {
+		return nil, notExist("open", key)
+	}
+	return io.NopCloser(bytes.NewReader(val)), nil
+}
+
+func (kv *KeyValues) IsCurrent() (bool, int64) {
+	kv.call("IsCurrent")
+	return true, time.Now().Unix()
+}
+
+func (kv *KeyValues) after(timestamps ...map[string]int64) func(ts int64) []string {
+	return func(ts int64) []string {
+		kv.mtx.Lock()
+		defer kv.mtx.Unlock()
+
+		keys := make([]string, 0)
+		for _, tss := range timestamps {
+			for key, kts := range tss {
+				if kts >= ts && !slices.Contains(keys, key) {
+					keys = append(keys, key)
+				}
+			}
+		}
+		return keys
+	}
+}
+
+func (kv *KeyValues) CreatedAfter(ts int64) ([]string, error) {
+	if err := kv.call("CreatedAfter", ts); err != nil {
+		return nil, err
+	}
+	return kv.after(kv.created)(ts), nil
+}
+
+func (kv *KeyValues) UpdatedAfter(ts int64) ([]string, error) {
+	if err := kv.call("UpdatedAfter", ts); err != nil {
+		return nil, err
+	}
+	return kv.after(kv.modified)(ts), nil
+}
+
+func (kv *KeyValues) CreatedOrUpdatedAfter(ts int64) ([]string, error) {
+	if err := kv.call("CreatedOrUpdatedAfter", ts); err != nil {
+		return nil, err
+	}
+	return kv.after(kv.created, kv.modified)(ts), nil
+}
+
+func (kv *KeyValues) IsUpdatedAfter(key string, ts int64) (bool, error) {
+	if err := kv.call("IsUpdatedAfter", key, ts); err != nil {
+		return false, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	mts, ok := kv.modified[key]
+	return ok && mts >= ts, nil
+}
+
+func (kv *KeyValues) modTime(key string) int64 {
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	if mts, ok := kv.modified[key]; ok {
+		return mts
+	}
+	if cts, ok := kv.created[key]; ok {
+		return cts
+	}
+	return -1
+}
+
+func (kv *KeyValues) ModTime(key string) (int64, error) {
+	if err := kv.call("ModTime", key); err != nil {
+		return -1, err
+	}
+	return kv.modTime(key), nil
+}
+
+func (kv *KeyValues) Size(key string) (int64, error) {
+	if err := kv.call("Size", key); err != nil {
+		return -1, err
+	}
+	val, ok := kv.value(key)
+	if !ok {
+		return -1, fmt.Errorf("%w: %s", kevlar.ErrUnknownKey, key)
+	}
+	return int64(len(val)), nil
+}
+
+func (kv *KeyValues) TotalSize() (int64, error) {
+	if err := kv.call("TotalSize"); err != nil {
+		return 0, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	var total int64
+	for _, val := range kv.values {
+		total += int64(len(val))
+	}
+	return total, nil
+}
+
+func (kv *KeyValues) GetRecord(key string) (kevlar.Record, bool) {
+	if err := kv.call("GetRecord", key); err != nil {
+		return kevlar.Record{}, false
+	}
+
+	val, ok := kv.value(key)
+	if !ok {
+		return kevlar.Record{}, false
+	}
+
+	hash, err := kevlar.Sha256(bytes.NewReader(val))
+	if err != nil {
+		return kevlar.Record{}, false
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	return kevlar.Record{
+		Created:  kv.created[key],
+		Modified: max(kv.created[key], kv.modified[key]),
+		Hash:     hash,
+		Size:     int64(len(val)),
+		Meta:     make(map[string]string),
+	}, true
+}
+
+func (kv *KeyValues) Snapshot() (string, error) {
+	if err := kv.call("Snapshot"); err != nil {
+		return "", err
+	}
+	return "", errors.ErrUnsupported
+}
+
+func (kv *KeyValues) ChangedSince(snapshotId string) (*kevlar.Changes, error) {
+	if err := kv.call("ChangedSince", snapshotId); err != nil {
+		return nil, err
+	}
+	return nil, errors.ErrUnsupported
+}
+
+func (kv *KeyValues) Generation() (kevlar.Generation, error) {
+	if err := kv.call("Generation"); err != nil {
+		return kevlar.Generation{}, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	if kv.id == "" {
+		var id [16]byte
+		if _, err := rand.Read(id[:]); err != nil {
+			return kevlar.Generation{}, err
+		}
+		kv.id = hex.EncodeToString(id[:])
+	}
+
+	return kevlar.Generation{Id: kv.id, N: kv.generation}, nil
+}
+
+func (kv *KeyValues) SetRefreshAfter(key string, ts int64) error {
+	if err := kv.call("SetRefreshAfter", key, ts); err != nil {
+		return err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	if ts < 0 {
+		delete(kv.refresh, key)
+	} else {
+		kv.refresh[key] = ts
+	}
+	return nil
+}
+
+func (kv *KeyValues) DueForRefresh(ts int64) ([]string, error) {
+	if err := kv.call("DueForRefresh", ts); err != nil {
+		return nil, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	keys := make([]string, 0)
+	for key, rts := range kv.refresh {
+		if _, ok := kv.values[key]; ok && rts <= ts {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (kv *KeyValues) SetWithExpiry(key string, data io.Reader, ttl time.Duration) error {
+	if err := kv.call("SetWithExpiry", key, data, ttl); err != nil {
+		return err
+	}
+	if err := kv.set(key, data); err != nil {
+		return err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	kv.expires[key] = time.Now().Add(ttl).Unix()
+	return nil
+}
+
+func (kv *KeyValues) PruneExpired() ([]string, error) {
+	if err := kv.call("PruneExpired"); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+
+	kv.mtx.Lock()
+	expired := make([]string, 0)
+	for key, ets := range kv.expires {
+		if _, pinned := kv.pinned[key]; ets <= now && !pinned {
+			expired = append(expired, key)
+		}
+	}
+	kv.mtx.Unlock()
+
+	cut := make([]string, 0, len(expired))
+	for _, key := range expired {
+		if ok, err := kv.cut(key); err != nil {
+			return cut, err
+		} else if ok {
+			cut = append(cut, key)
+		}
+	}
+	return cut, nil
+}
+
+func (kv *KeyValues) Pin(key string) error {
+	if err := kv.call("Pin", key); err != nil {
+		return err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	if kv.frozen {
+		return kevlar.ErrFrozen
+	}
+	if _, ok := kv.values[key]; !ok {
+		return fmt.Errorf("%w: %s", kevlar.ErrUnknownKey, key)
+	}
+	kv.pinned[key] = time.Now().Unix()
+	return nil
+}
+
+func (kv *KeyValues) Unpin(key string) error {
+	if err := kv.call("Unpin", key); err != nil {
+		return err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	if kv.frozen {
+		return kevlar.ErrFrozen
+	}
+	delete(kv.pinned, key)
+	return nil
+}
+
+func (kv *KeyValues) PinnedKeys() ([]string, error) {
+	if err := kv.call("PinnedKeys"); err != nil {
+		return nil, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	pinned := make([]string, 0, len(kv.pinned))
+	for key := range kv.pinned {
+		if _, ok := kv.values[key]; ok {
+			pinned = append(pinned, key)
+		}
+	}
+	return pinned, nil
+}
+
+func (kv *KeyValues) Flush() error {
+	return kv.call("Flush")
+}
+
+func (kv *KeyValues) Freeze() error {
+	if err := kv.call("Freeze"); err != nil {
+		return err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	kv.frozen = true
+	return nil
+}
+
+func (kv *KeyValues) Thaw() {
+	kv.call("Thaw")
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	kv.frozen = false
+}
+
+func (kv *KeyValues) Close() error {
+	return kv.call("Close")
+}
+
+func (kv *KeyValues) VetContent(quarantine bool) ([]string, error) {
+	if err := kv.call("VetContent", quarantine); err != nil {
+		return nil, err
+	}
+	return []string{}, nil
+}
+
+func (kv *KeyValues) VetKeys() ([]string, error) {
+	if err := kv.call("VetKeys"); err != nil {
+		return nil, err
+	}
+	return []string{}, nil
+}
+
+func (kv *KeyValues) ApplyRetention(rules ...kevlar.RetentionRule) ([]string, error) {
+	if err := kv.call("ApplyRetention", rules); err != nil {
+		return nil, err
+	}
+	return nil, errors.ErrUnsupported
+}
+
+func (kv *KeyValues) Archive(pred func(kevlar.Record) bool, dst kevlar.KeyValues) (int, error) {
+	if err := kv.call("Archive", pred, dst); err != nil {
+		return 0, err
+	}
+	return 0, errors.ErrUnsupported
+}
+
+func (kv *KeyValues) PrefixStats(delimiter string) (map[string]kevlar.PrefixStat, error) {
+	if err := kv.call("PrefixStats", delimiter); err != nil {
+		return nil, err
+	}
+	return nil, errors.ErrUnsupported
+}
+
+// Stats returns counters of recorded calls. Bytes and cache
+// hits are not counted by the double
+func (kv *KeyValues) Stats() kevlar.Stats {
+	kv.call("Stats")
+	return kevlar.Stats{
+		Gets: int64(len(kv.Calls("Get"))),
+		Sets: int64(len(kv.Calls("Set"))),
+		Cuts: int64(len(kv.Calls("Cut"))),
+	}
+}
+
+func (kv *KeyValues) SizeDistribution(buckets []int64) (map[string]int, error) {
+	if err := kv.call("SizeDistribution", buckets); err != nil {
+		return nil, err
+	}
+	return nil, errors.ErrUnsupported
+}
+
+func (kv *KeyValues) SizePercentile(p float64) (int64, error) {
+	if err := kv.call("SizePercentile", p); err != nil {
+		return 0, err
+	}
+	return 0, errors.ErrUnsupported
+}
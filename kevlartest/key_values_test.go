@@ -0,0 +1,105 @@
+package kevlartest
+
+import (
+	"errors"
+	"github.com/boggydigital/kevlar"
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyValues_SetGetHasCut(t *testing.T) {
+	kv := NewKeyValues()
+
+	testo.Error(t, kv.Set("k1", strings.NewReader("v1")), false)
+
+	has, err := kv.Has("k1")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, has, true)
+
+	rc, err := kv.Get("k1")
+	testo.Error(t, err, false)
+	data, err := io.ReadAll(rc)
+	testo.Error(t, err, false)
+	testo.Error(t, rc.Close(), false)
+	testo.EqualValues(t, string(data), "v1")
+
+	cut, err := kv.Cut("k1")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, cut, true)
+
+	_, err = kv.Get("k1")
+	testo.EqualValues(t, os.IsNotExist(err), true)
+
+	testo.EqualValues(t, kv.Stats().Sets, int64(1))
+	testo.EqualValues(t, kv.Stats().Cuts, int64(1))
+}
+
+func TestKeyValues_FailOn(t *testing.T) {
+	kv := NewKeyValues()
+	errInjected := errors.New("injected")
+
+	kv.FailOn("Set", errInjected)
+	testo.EqualValues(t, errors.Is(kv.Set("k1", strings.NewReader("v1")), errInjected), true)
+
+	has, err := kv.Has("k1")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, has, false)
+
+	kv.FailOn("Set", nil)
+	testo.Error(t, kv.Set("k1", strings.NewReader("v1")), false)
+}
+
+func TestKeyValues_Delay(t *testing.T) {
+	kv := NewKeyValues()
+	kv.Delay("Keys", 10*time.Millisecond)
+
+	start := time.Now()
+	_, err := kv.Keys()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, time.Since(start) >= 10*time.Millisecond, true)
+}
+
+func TestKeyValues_Calls(t *testing.T) {
+	kv := NewKeyValues()
+
+	testo.Error(t, kv.Set("k1", strings.NewReader("v1")), false)
+	_, err := kv.Has("k2")
+	testo.Error(t, err, false)
+
+	calls := kv.Calls("Has")
+	testo.EqualValues(t, len(calls), 1)
+	testo.DeepEqual(t, calls[0].Args, []any{"k2"})
+	testo.EqualValues(t, len(kv.Calls("")), 2)
+
+	kv.Reset()
+	testo.EqualValues(t, len(kv.Calls("")), 0)
+}
+
+func TestKeyValues_Freeze(t *testing.T) {
+	kv := NewKeyValues()
+
+	testo.Error(t, kv.Freeze(), false)
+	testo.EqualValues(t, errors.Is(kv.Set("k1", strings.NewReader("v1")), kevlar.ErrFrozen), true)
+
+	kv.Thaw()
+	testo.Error(t, kv.Set("k1", strings.NewReader("v1")), false)
+}
+
+func TestRedux_AddValuesMatch(t *testing.T) {
+	rdx, err := NewRedux("a1")
+	testo.Error(t, err, false)
+
+	testo.Error(t, rdx.AddValues("a1", "k1", "v1"), false)
+	testo.DeepEqual(t, rdx.Match(map[string][]string{"a1": {"v1"}}), []string{"k1"})
+
+	errInjected := errors.New("injected")
+	rdx.FailOn("AddValues", errInjected)
+	testo.EqualValues(t, errors.Is(rdx.AddValues("a1", "k2", "v2"), errInjected), true)
+	testo.EqualValues(t, rdx.HasKey("a1", "k2"), false)
+
+	testo.EqualValues(t, len(rdx.Calls("AddValues")), 2)
+}
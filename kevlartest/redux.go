@@ -0,0 +1,248 @@
+package kevlartest
+
+import (
+	"github.com/boggydigital/kevlar"
+	"io"
+)
+
+// Redux is a kevlar.WriteableRedux double. Reductions are stored in the
+// in-memory KeyValues double and every method call is recorded by the
+// embedded Spy. Injected errors are returned by methods that return errors
+type Redux struct {
+	Spy
+
+	kv  *KeyValues
+	rdx kevlar.WriteableRedux
+}
+
+var _ kevlar.WriteableRedux = (*Redux)(nil)
+
+func NewRedux(assets ...string) (*Redux, error) {
+	kv := NewKeyValues()
+	rdx, err := kevlar.NewReduxWriterWith(kv, assets...)
+	if err != nil {
+		return nil, err
+	}
+	return &Redux{kv: kv, rdx: rdx}, nil
+}
+
+// KeyValues returns the double storing reductions,
+// e.g. to inject storage errors
+func (r *Redux) KeyValues() *KeyValues {
+	return r.kv
+}
+
+func (r *Redux) MustHave(assets ...string) error {
+	if err := r.call("MustHave", assets); err != nil {
+		return err
+	}
+	return r.rdx.MustHave(assets...)
+}
+
+func (r *Redux) Keys(asset string) []string {
+	r.call("Keys", asset)
+	return r.rdx.Keys(asset)
+}
+
+func (r *Redux) HasAsset(asset string) bool {
+	r.call("HasAsset", asset)
+	return r.rdx.HasAsset(asset)
+}
+
+func (r *Redux) HasKey(asset, key string) bool {
+	r.call("HasKey", asset, key)
+	return r.rdx.HasKey(asset, key)
+}
+
+func (r *Redux) HasValue(asset, key, val string) bool {
+	r.call("HasValue", asset, key, val)
+	return r.rdx.HasValue(asset, key, val)
+}
+
+func (r *Redux) GetAllValues(asset, key string) ([]string, bool) {
+	r.call("GetAllValues", asset, key)
+	return r.rdx.GetAllValues(asset, key)
+}
+
+func (r *Redux) GetLastVal(asset, key string) (string, bool) {
+	r.call("GetLastVal", asset, key)
+	return r.rdx.GetLastVal(asset, key)
+}
+
+func (r *Redux) AnyKeyWithVal(asset, val string) (string, bool) {
+	r.call("AnyKeyWithVal", asset, val)
+	return r.rdx.AnyKeyWithVal(asset, val)
+}
+
+func (r *Redux) ModTime() (int64, error) {
+	if err := r.call("ModTime"); err != nil {
+		return -1, err
+	}
+	return r.rdx.ModTime()
+}
+
+func (r *Redux) RefreshReader() (kevlar.ReadableRedux, error) {
+	if err := r.call("RefreshReader"); err != nil {
+		return nil, err
+	}
+	if _, err := r.rdx.RefreshReader(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Redux) MatchAsset(asset string, terms []string, scope []string, options ...kevlar.MatchOption) []string {
+	r.call("MatchAsset", asset, terms, scope, options)
+	return r.rdx.MatchAsset(asset, terms, scope, options...)
+}
+
+func (r *Redux) Match(query map[string][]string, options ...kevlar.MatchOption) []string {
+	r.call("Match", query, options)
+	return r.rdx.Match(query, options...)
+}
+
+func (r *Redux) Sort(ids []string, desc bool, sortBy ...string) ([]string, error) {
+	if err := r.call("Sort", ids, desc, sortBy); err != nil {
+		return nil, err
+	}
+	return r.rdx.Sort(ids, desc, sortBy...)
+}
+
+func (r *Redux) SortBy(ids []string, orders ...kevlar.SortOrder) ([]string, error) {
+	if err := r.call("SortBy", ids, orders); err != nil {
+		return nil, err
+	}
+	return r.rdx.SortBy(ids, orders...)
+}
+
+func (r *Redux) Export(w io.Writer, keys ...string) error {
+	if err := r.call("Export", w, keys); err != nil {
+		return err
+	}
+	return r.rdx.Export(w, keys...)
+}
+
+func (r *Redux) PinScope(keys map[string]bool) kevlar.ReadableRedux {
+	r.call("PinScope", keys)
+	return r.rdx.PinScope(keys)
+}
+
+func (r *Redux) Sum(asset string, keys []string) (float64, error) {
+	if err := r.call("Sum", asset, keys); err != nil {
+		return 0, err
+	}
+	return r.rdx.Sum(asset, keys)
+}
+
+func (r *Redux) Min(asset string, keys []string) (float64, error) {
+	if err := r.call("Min", asset, keys); err != nil {
+		return 0, err
+	}
+	return r.rdx.Min(asset, keys)
+}
+
+func (r *Redux) Max(asset string, keys []string) (float64, error) {
+	if err := r.call("Max", asset, keys); err != nil {
+		return 0, err
+	}
+	return r.rdx.Max(asset, keys)
+}
+
+func (r *Redux) Avg(asset string, keys []string) (float64, error) {
+	if err := r.call("Avg", asset, keys); err != nil {
+		return 0, err
+	}
+	return r.rdx.Avg(asset, keys)
+}
+
+func (r *Redux) GroupBy(groupAsset string, keys []string) map[string][]string {
+	r.call("GroupBy", groupAsset, keys)
+	return r.rdx.GroupBy(groupAsset, keys)
+}
+
+func (r *Redux) AddValues(asset, key string, values ...string) error {
+	if err := r.call("AddValues", asset, key, values); err != nil {
+		return err
+	}
+	return r.rdx.AddValues(asset, key, values...)
+}
+
+func (r *Redux) BatchAddValues(asset string, keyValues map[string][]string) error {
+	if err := r.call("BatchAddValues", asset, keyValues); err != nil {
+		return err
+	}
+	return r.rdx.BatchAddValues(asset, keyValues)
+}
+
+func (r *Redux) ReplaceValues(asset, key string, values ...string) error {
+	if err := r.call("ReplaceValues", asset, key, values); err != nil {
+		return err
+	}
+	return r.rdx.ReplaceValues(asset, key, values...)
+}
+
+func (r *Redux) BatchReplaceValues(asset string, keyValues map[string][]string) error {
+	if err := r.call("BatchReplaceValues", asset, keyValues); err != nil {
+		return err
+	}
+	return r.rdx.BatchReplaceValues(asset, keyValues)
+}
+
+func (r *Redux) ReplaceValuesDiff(asset, key string, values ...string) (kevlar.ValuesDiff, error) {
+	if err := r.call("ReplaceValuesDiff", asset, key, values); err != nil {
+		return kevlar.ValuesDiff{}, err
+	}
+	return r.rdx.ReplaceValuesDiff(asset, key, values...)
+}
+
+func (r *Redux) BatchReplaceValuesDiff(asset string, keyValues map[string][]string) (map[string]kevlar.ValuesDiff, error) {
+	if err := r.call("BatchReplaceValuesDiff", asset, keyValues); err != nil {
+		return nil, err
+	}
+	return r.rdx.BatchReplaceValuesDiff(asset, keyValues)
+}
+
+func (r *Redux) CutKeys(asset string, keys ...string) error {
+	if err := r.call("CutKeys", asset, keys); err != nil {
+		return err
+	}
+	return r.rdx.CutKeys(asset, keys...)
+}
+
+func (r *Redux) CutValues(asset, key string, values ...string) error {
+	if err := r.call("CutValues", asset, key, values); err != nil {
+		return err
+	}
+	return r.rdx.CutValues(asset, key, values...)
+}
+
+func (r *Redux) BatchCutValues(asset string, keyValues map[string][]string) error {
+	if err := r.call("BatchCutValues", asset, keyValues); err != nil {
+		return err
+	}
+	return r.rdx.BatchCutValues(asset, keyValues)
+}
+
+func (r *Redux) CompactAsset(asset string) (int, error) {
+	if err := r.call("CompactAsset", asset); err != nil {
+		return 0, err
+	}
+	return r.rdx.CompactAsset(asset)
+}
+
+func (r *Redux) CompactAll() (int, error) {
+	if err := r.call("CompactAll"); err != nil {
+		return 0, err
+	}
+	return r.rdx.CompactAll()
+}
+
+func (r *Redux) RefreshWriter() (kevlar.WriteableRedux, error) {
+	if err := r.call("RefreshWriter"); err != nil {
+		return nil, err
+	}
+	if _, err := r.rdx.RefreshWriter(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,97 @@
+// Package kevlartest provides test doubles for kevlar key values and
+// reductions, so that applications can test failure handling (injected
+// errors, slow storage) without filesystem fixtures
+package kevlartest
+
+import (
+	"sync"
+	"time"
+)
+
+// Call is a recorded method call with its arguments
+type Call struct {
+	Method string
+	Args   []any
+}
+
+// Spy records method calls, returns injected errors and simulates latency.
+// It's embedded in test doubles and is safe for concurrent use
+type Spy struct {
+	callsMtx sync.Mutex
+	calls    []Call
+	errs     map[string]error
+	latency  map[string]time.Duration
+}
+
+// FailOn makes every following call of the method return the error.
+// Nil error removes previously injected error
+func (s *Spy) FailOn(method string, err error) {
+	s.callsMtx.Lock()
+	defer s.callsMtx.Unlock()
+
+	if s.errs == nil {
+		s.errs = make(map[string]error)
+	}
+	if err == nil {
+		delete(s.errs, method)
+	} else {
+		s.errs[method] = err
+	}
+}
+
+// Delay makes every following call of the method sleep for the duration,
+// e.g. to test timeouts. Zero duration removes the delay
+func (s *Spy) Delay(method string, d time.Duration) {
+	s.callsMtx.Lock()
+	defer s.callsMtx.Unlock()
+
+	if s.latency == nil {
+		s.latency = make(map[string]time.Duration)
+	}
+	if d <= 0 {
+		delete(s.latency, method)
+	} else {
+		s.latency[method] = d
+	}
+}
+
+// Calls returns recorded calls of the method in the order they were
+// made. Empty method returns all recorded calls
+func (s *Spy) Calls(method string) []Call {
+	s.callsMtx.Lock()
+	defer s.callsMtx.Unlock()
+
+	calls := make([]Call, 0, len(s.calls))
+	for _, call := range s.calls {
+		if method == "" || call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset removes recorded calls, injected errors and delays
+func (s *Spy) Reset() {
+	s.callsMtx.Lock()
+	defer s.callsMtx.Unlock()
+
+	s.calls = nil
+	s.errs = nil
+	s.latency = nil
+}
+
+// call records the method call, sleeps for the method delay
+// and returns the method injected error
+func (s *Spy) call(method string, args ...any) error {
+	s.callsMtx.Lock()
+	s.calls = append(s.calls, Call{Method: method, Args: args})
+	d := s.latency[method]
+	err := s.errs[method]
+	s.callsMtx.Unlock()
+
+	if d > 0 {
+		time.Sleep(d)
+	}
+
+	return err
+}
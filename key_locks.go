@@ -0,0 +1,87 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/busan"
+	"golang.org/x/exp/slices"
+	"sync"
+)
+
+// Consistency model:
+//   - mutations of the same key (Set, SetFromFile, Cut, SetDerived, Copy, Rename) are
+//     serialized with a per-key lock, so that comparing hashes, writing value
+//     and sidecar files and adding a log record is atomic for other mutations
+//     of that key. Mutations of different keys proceed concurrently
+//   - storage lock (mtx) protects in-memory state (log records, keys, caches)
+//     and is never held during value I/O
+//   - log records writes are serialized and happen without holding storage lock.
+//     Mutations return after log records including their record have been written
+//   - reads don't take per-key locks. Values are renamed into place, so reads see
+//     either the previous or the new value, never a partially written one. Log
+//     records are updated after the value, so Has might report the key a moment
+//     after the value is readable
+//
+// Locks are acquired in this order: freeze, key, log records write, storage.
+// Mutations of several keys (Copy, Rename) lock them in the filename order.
+
+type keyLock struct {
+	mtx  sync.Mutex
+	refs int
+}
+
+// lockKey locks the key for mutation and returns a function to unlock it.
+// Keys are locked by their filename, since different keys might share it
+func (kv *keyValues) lockKey(key string) func() {
+	filename := busan.Sanitize(key)
+
+	kv.keyLocksMtx.Lock()
+	if kv.keyLocks == nil {
+		kv.keyLocks = make(map[string]*keyLock)
+	}
+	kl, ok := kv.keyLocks[filename]
+	if !ok {
+		kl = new(keyLock)
+		kv.keyLocks[filename] = kl
+	}
+	kl.refs++
+	kv.keyLocksMtx.Unlock()
+
+	kl.mtx.Lock()
+
+	return func() {
+		kl.mtx.Unlock()
+
+		kv.keyLocksMtx.Lock()
+		kl.refs--
+		if kl.refs == 0 {
+			delete(kv.keyLocks, filename)
+		}
+		kv.keyLocksMtx.Unlock()
+	}
+}
+
+// lockKeys locks several keys for mutation in the filename order, so that
+// concurrent mutations of the same keys can't deadlock, and returns
+// a function to unlock them. Keys that share the filename are locked once
+func (kv *keyValues) lockKeys(keys ...string) func() {
+	filenameKeys := make(map[string]string, len(keys))
+	for _, key := range keys {
+		filenameKeys[busan.Sanitize(key)] = key
+	}
+
+	filenames := make([]string, 0, len(filenameKeys))
+	for filename := range filenameKeys {
+		filenames = append(filenames, filename)
+	}
+	slices.Sort(filenames)
+
+	unlocks := make([]func(), 0, len(filenames))
+	for _, filename := range filenames {
+		unlocks = append(unlocks, kv.lockKey(filenameKeys[filename]))
+	}
+
+	return func() {
+		for ii := len(unlocks) - 1; ii >= 0; ii-- {
+			unlocks[ii]()
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package kevlar
+
+import (
+	"bytes"
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestKeyValues_LockKey(t *testing.T) {
+	kv := &keyValues{}
+
+	unlock := kv.lockKey("key")
+
+	locked := make(chan struct{})
+	released := make(chan struct{})
+	go func() {
+		unlockAgain := kv.lockKey("key")
+		close(locked)
+		unlockAgain()
+		close(released)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("key was locked twice")
+	default:
+	}
+
+	unlock()
+	<-released
+
+	kv.keyLocksMtx.Lock()
+	testo.EqualValues(t, len(kv.keyLocks), 0)
+	kv.keyLocksMtx.Unlock()
+}
+
+// Run with -race to validate locking
+func TestKeyValues_ConcurrentSameKey(t *testing.T) {
+	kv, err := connect(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	const goroutines = 20
+	key := "contended"
+
+	var wg sync.WaitGroup
+	for ii := 0; ii < goroutines; ii++ {
+		wg.Add(1)
+		go func(value string) {
+			defer wg.Done()
+			if err := kv.Set(key, strings.NewReader(value)); err != nil {
+				t.Error(err)
+			}
+			rc, err := kv.Get(key)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			// values are replaced atomically - partial values are never read
+			bts, err := io.ReadAll(rc)
+			if err != nil {
+				t.Error(err)
+			}
+			if _, err := strconv.Atoi(string(bts)); err != nil {
+				t.Error(err)
+			}
+			if err := rc.Close(); err != nil {
+				t.Error(err)
+			}
+		}(strconv.Itoa(ii))
+	}
+	wg.Wait()
+
+	// stored hash matches the last written value
+	rc, err := kv.Get(key)
+	testo.Error(t, err, false)
+	bts, err := io.ReadAll(rc)
+	testo.Error(t, err, false)
+	testo.Error(t, rc.Close(), false)
+
+	hash, err := kv.currentHash(key)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, hash, sha256Hex(bts))
+
+	// the key was created once and then updated
+	creates := 0
+	for _, lr := range kv.log {
+		if lr.Id == key && lr.Mt == create {
+			creates++
+		}
+	}
+	testo.EqualValues(t, creates, 1)
+
+	// concurrent Set and Cut leave value and log records consistent
+	for ii := 0; ii < goroutines; ii++ {
+		wg.Add(1)
+		go func(ii int) {
+			defer wg.Done()
+			var err error
+			if ii%2 == 0 {
+				err = kv.Set(key, bytes.NewReader([]byte(strconv.Itoa(ii))))
+			} else {
+				_, err = kv.Cut(key)
+			}
+			if err != nil {
+				t.Error(err)
+			}
+		}(ii)
+	}
+	wg.Wait()
+
+	ok, err := kv.Has(key)
+	testo.Error(t, err, false)
+	_, err = os.Stat(kv.absValueFilename(key))
+	testo.EqualValues(t, ok, err == nil)
+
+	if ok {
+		_, err = kv.Cut(key)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, logRecordsCleanup(), false)
+}
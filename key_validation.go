@@ -0,0 +1,101 @@
+package kevlar
+
+import (
+	"errors"
+	"fmt"
+	"github.com/boggydigital/busan"
+	"strings"
+)
+
+var (
+	ErrInvalidKey  = errors.New("kevlar: invalid key")
+	ErrKeyTooLong  = errors.New("kevlar: key is too long")
+	ErrTooManyKeys = errors.New("kevlar: too many keys")
+)
+
+// validateKey rejects keys that would produce surprising filenames: empty keys,
+// keys containing "..", keys that map to the reserved storage directory
+// (possible with an empty extension) and keys that don't match storage key
+// pattern, when one was set. Path separators are escaped with busan.Sanitize,
+// so prefix style keys (e.g. "user/1") are valid unless the key pattern
+// rejects them
+func (kv *keyValues) validateKey(key string) error {
+	switch {
+	case key == "":
+		return fmt.Errorf("%w: empty key", ErrInvalidKey)
+	case kv.maxKeyLength > 0 && len(key) > kv.maxKeyLength:
+		return fmt.Errorf("%w: %d > %d", ErrKeyTooLong, len(key), kv.maxKeyLength)
+	case strings.Contains(key, ".."):
+		return fmt.Errorf("%w: %q contains ..", ErrInvalidKey, key)
+	case busan.Sanitize(key)+kv.ext == kevlarDirname:
+		return fmt.Errorf("%w: %q maps to reserved filename", ErrInvalidKey, key)
+	case kv.keyPattern != nil && !kv.keyPattern.MatchString(key):
+		return fmt.Errorf("%w: %q doesn't match %s", ErrInvalidKey, key, kv.keyPattern)
+	}
+	return nil
+}
+
+// reserveKey returns ErrTooManyKeys when setting a new key would exceed
+// the maximum number of keys, when one was set. New keys are reserved
+// until the returned function is called, so that concurrent writes of
+// different new keys can't exceed the limit together
+func (kv *keyValues) reserveKey(key string) (func(), error) {
+	release := func() {}
+
+	if kv.maxKeys <= 0 {
+		return release, nil
+	}
+
+	if err := kv.refreshLogRecords(); err != nil {
+		return release, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	if _, ok := kv.keys[key]; ok {
+		return release, nil
+	}
+
+	count := len(kv.keys)
+	for reservedKey := range kv.reservedKeys {
+		// reserved keys are counted until they're released, even after
+		// they've been added to the log records
+		if _, ok := kv.keys[reservedKey]; !ok {
+			count++
+		}
+	}
+
+	if count >= kv.maxKeys {
+		return release, fmt.Errorf("%w: %d", ErrTooManyKeys, kv.maxKeys)
+	}
+
+	if kv.reservedKeys == nil {
+		kv.reservedKeys = make(map[string]any)
+	}
+	kv.reservedKeys[key] = nil
+
+	return func() {
+		kv.mtx.Lock()
+		defer kv.mtx.Unlock()
+		delete(kv.reservedKeys, key)
+	}, nil
+}
+
+// VetKeys returns existing keys that wouldn't be accepted by Set,
+// e.g. keys set before validation or the key pattern were introduced
+func (kv *keyValues) VetKeys() ([]string, error) {
+	keys, err := kv.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	invalid := make([]string, 0)
+	for _, key := range keys {
+		if err := kv.validateKey(key); err != nil {
+			invalid = append(invalid, key)
+		}
+	}
+
+	return invalid, nil
+}
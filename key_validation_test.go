@@ -0,0 +1,95 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_ValidateKey(t *testing.T) {
+	tests := []struct {
+		key string
+		exp bool
+	}{
+		{"", false},
+		{"..", false},
+		{"../etc", false},
+		{"a..b", false},
+		{"a/b", true},
+		{"key", true},
+		{"1234", true},
+		{"key.with.dots", true},
+		{".", true},
+		{kevlarDirname, false},
+	}
+
+	kv := &keyValues{}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			err := kv.validateKey(tt.key)
+			testo.EqualValues(t, err == nil, tt.exp)
+			if err != nil {
+				testo.EqualValues(t, errors.Is(err, ErrInvalidKey), true)
+			}
+		})
+	}
+}
+
+func TestKeyValues_VetKeys(t *testing.T) {
+	kv, err := connect(filepath.Join(os.TempDir(), testsDirname), JsonExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("valid", strings.NewReader("{}")), false)
+	testo.EqualValues(t, errors.Is(kv.Set("../invalid", strings.NewReader("{}")), ErrInvalidKey), true)
+
+	// simulate a key that was set before validation has been introduced
+	testo.Error(t, kv.committed(kv.createOrUpdateLogRecord("in..valid", 0)), false)
+
+	invalid, err := kv.VetKeys()
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, invalid, []string{"in..valid"})
+
+	for _, key := range []string{"valid", "in..valid"} {
+		ok, err := kv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func FuzzKeyValues_AbsValueFilename(f *testing.F) {
+	for _, key := range []string{"key", "a/b", ".", "..", "./", "\\..\\", kevlarDirname, "\x00", "\xff\xfe"} {
+		f.Add(key)
+	}
+
+	dir := filepath.Join(os.TempDir(), testsDirname)
+
+	f.Fuzz(func(t *testing.T, key string) {
+		for _, ext := range []string{"", GobExt} {
+			kv := &keyValues{dir: dir, ext: ext}
+			if kv.validateKey(key) != nil {
+				continue
+			}
+
+			// valid keys must map to files directly in the storage directory
+			absValueFilename := kv.absValueFilename(key)
+			if filepath.Dir(absValueFilename) != dir {
+				t.Fatalf("key %q maps to %s outside of %s", key, absValueFilename, dir)
+			}
+			if filename := filepath.Base(absValueFilename); filename == kevlarDirname {
+				t.Fatalf("key %q maps to reserved %s", key, filename)
+			}
+
+			absHashFilename := kv.absHashFilename(key)
+			if filepath.Dir(absHashFilename) != filepath.Join(dir, kevlarDirname) {
+				t.Fatalf("key %q hash maps to %s", key, absHashFilename)
+			}
+		}
+	})
+}
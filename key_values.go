@@ -2,17 +2,26 @@ package kevlar
 
 import (
 	"bytes"
+	"context"
 	"encoding/gob"
+	"errors"
+	"fmt"
 	"github.com/boggydigital/busan"
 	"golang.org/x/exp/maps"
+	"hash/crc64"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 )
 
+var ErrIndexFileMismatch = errors.New("kevlar: key is logged, but value file is missing")
+
 const (
 	kevlarDirname      = "_kevlar"
 	logRecordsFilename = "_log.gob"
@@ -20,17 +29,88 @@ const (
 )
 
 type keyValues struct {
-	dir  string
-	ext  string
-	lmt  int64
-	log  logRecords
-	keys map[string]any
-	mtx  *sync.Mutex
+	dir        string
+	ext        string
+	logState   logState
+	log        logRecords
+	keys       map[string]any
+	mtx        *sync.Mutex
+	stagingDir string
+	fastHash   bool
+
+	// sortedKeys caches ordered keys for KeysPage until keys change
+	sortedKeys []string
+
+	linkDuplicates bool
+	hashKeys       map[string]string
+
+	beforeLogWrite LogWriteHook
+	afterLogWrite  LogWriteHook
+
+	flushMutations   int
+	flushInterval    time.Duration
+	pendingMutations int
+	flushTimer       *time.Timer
+	flushErr         error
+
+	templates map[string]cachedTemplate
+
+	shared bool
+	refs   int
+
+	strict     bool
+	keyPattern *regexp.Regexp
+
+	maxKeyLength int
+	maxKeys      int
+	reservedKeys map[string]any
+
+	binaryLog bool
+
+	timeout time.Duration
+
+	retryAttempts   int
+	retryBackoff    time.Duration
+	retryClassifier func(err error) bool
+
+	slowOpThreshold time.Duration
+	slowOpLogger    *slog.Logger
+
+	htmlSniffing bool
+
+	conflictPolicy ConflictPolicy
+
+	labels map[string]string
+
+	freezeMtx sync.RWMutex
+	frozen    bool
+
+	logWriteMtx  sync.Mutex
+	logSeq       uint64
+	committedSeq uint64
+	generation   uint64
+
+	keyLocksMtx sync.Mutex
+	keyLocks    map[string]*keyLock
+
+	stats storeStats
+
+	maintenance *Maintenance
+	maintainer  *maintainer
 }
 
 // NewKeyValues connects a new local key value storage at the specified directory
-// and will use specified extension for the value files
-func NewKeyValues(dir, ext string) (KeyValues, error) {
+// and will use specified extension for the value files. Connecting to the same
+// directory and extension again within the process returns shared key values,
+// so that all users see each other's writes. Connecting without options uses
+// shared key values as configured, connecting with options that are different
+// from the ones shared key values have been connected with fails with
+// ErrOptionsConflict
+func NewKeyValues(dir, ext string, options ...KeyValuesOption) (KeyValues, error) {
+	return connectShared(dir, ext, options...)
+}
+
+func connect(dir, ext string, options ...KeyValuesOption) (*keyValues, error) {
 
 	// make sure dir we're connecting to exists
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
@@ -45,7 +125,21 @@ func NewKeyValues(dir, ext string) (KeyValues, error) {
 		mtx: new(sync.Mutex),
 	}
 
-	_, kv.lmt = kv.IsCurrent()
+	for _, option := range options {
+		option(kv)
+	}
+
+	if kv.stagingDir != "" {
+		if err := validateStagingDir(kv.stagingDir, dir); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateStoragePolicy(kv.conflictPolicy); err != nil {
+		return nil, err
+	}
+
+	kv.detectLogFormat()
 
 	if err := kv.refreshLogRecords(); os.IsNotExist(err) {
 		// do nothing
@@ -53,94 +147,149 @@ func NewKeyValues(dir, ext string) (KeyValues, error) {
 		return nil, err
 	}
 
+	if kv.maintenance != nil && kv.maintenance.Interval > 0 {
+		kv.startMaintenance()
+	}
+
 	return kv, nil
 }
 
+// IsCurrent reports whether log records haven't been changed externally
+// since they've been loaded and returns log records file modification
+// time (in seconds), -1 if it doesn't exist
 func (kv *keyValues) IsCurrent() (bool, int64) {
-	var lmt int64 = -1
-	if fi, err := os.Stat(kv.absLogRecordsFilename()); err == nil {
-		lmt = fi.ModTime().Unix()
+	ls := kv.statLogRecords()
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	lmt := ls.modTime
+	if lmt >= 0 {
+		lmt = time.Unix(0, lmt).Unix()
 	}
-	return lmt == kv.lmt, lmt
+
+	changed, err := kv.logRecordsChanged(ls)
+	return err == nil && !changed, lmt
 }
 
+// refreshLogRecords reloads log records (and keys) when the log records file
+// has been changed externally (see logRecordsChanged). Log records are not
+// reloaded while mutations are waiting to be committed, since in-memory log
+// records are more recent then
 func (kv *keyValues) refreshLogRecords() error {
-	if ok, lmt := kv.IsCurrent(); ok {
-		if kv.log != nil {
+	ls := kv.statLogRecords()
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	if kv.keys == nil {
+		kv.keys = kv.log.keys()
+		kv.sortedKeys = nil
+	}
+
+	if kv.log != nil {
+		if changed, err := kv.logRecordsChanged(ls); err != nil {
+			return err
+		} else if !changed {
 			return nil
 		}
-	} else {
-		kv.mtx.Lock()
-		kv.lmt = lmt
-		kv.mtx.Unlock()
 	}
+	if kv.log != nil && kv.logSeq != kv.committedSeq {
+		return nil
+	}
+
+	kv.logState = ls
 
-	absLogFilename := kv.absLogRecordsFilename()
-	if _, err := os.Stat(absLogFilename); os.IsNotExist(err) {
+	if ls.modTime < 0 {
+		// log records might have been removed externally
+		kv.log = nil
+		kv.keys = make(map[string]any)
+		kv.sortedKeys = nil
 		return nil
-	} else if err != nil {
+	}
+
+	var logFile *os.File
+	if err := kv.retry(func() (err error) {
+		logFile, err = os.Open(kv.absLogRecordsFilename())
+		return err
+	}); err != nil {
 		return err
 	}
+	defer logFile.Close()
+
+	h := crc64.New(crc64Table)
+	tr := io.TeeReader(logFile, h)
 
-	logFile, err := os.Open(absLogFilename)
+	lrs, err := decodeLogRecords(tr, kv.binaryLog)
 	if err != nil {
 		return err
 	}
-	defer logFile.Close()
+	// decoding might not read buffered trailing bytes
+	if _, err := io.Copy(io.Discard, tr); err != nil {
+		return err
+	}
+	kv.logState.sum = h.Sum64()
 
-	kv.mtx.Lock()
-	defer kv.mtx.Unlock()
+	lrs.intern()
 
-	if err := gob.NewDecoder(logFile).Decode(&kv.log); err == io.EOF {
-		// do nothing - empty log will be initialized later
-	} else if err != nil {
+	generation, err := kv.loadGeneration(lrs)
+	if err != nil {
 		return err
 	}
 
+	kv.log = lrs
+	kv.keys = lrs.keys()
+	kv.sortedKeys = nil
+	// generations never decrease, even when log records are older
+	kv.generation = max(kv.generation, generation)
+
 	return nil
 }
 
-func (kv *keyValues) refreshKeys() error {
-
+func (kv *keyValues) Keys() ([]string, error) {
 	if err := kv.refreshLogRecords(); err != nil {
-		return err
+		return nil, err
 	}
 
-	uks := make(map[string]any)
-	for _, lr := range kv.log {
-		switch lr.Mt {
-		case create:
-			fallthrough
-		case update:
-			uks[lr.Id] = nil
-		case cut:
-			delete(uks, lr.Id)
-		default:
-			panic("unknown log record mutation type")
-		}
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	return maps.Keys(kv.keys), nil
+}
+
+// KeysWithPrefix returns keys that start with the prefix (e.g. "user/123/"),
+// without copying all keys
+func (kv *keyValues) KeysWithPrefix(prefix string) ([]string, error) {
+	if err := kv.refreshLogRecords(); err != nil {
+		return nil, err
 	}
 
 	kv.mtx.Lock()
 	defer kv.mtx.Unlock()
 
-	kv.keys = uks
-
-	return nil
+	keys := make([]string, 0)
+	for key := range kv.keys {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
 }
 
-func (kv *keyValues) Keys() ([]string, error) {
-	if err := kv.refreshKeys(); err != nil {
-		return nil, err
+// Len returns the number of keys without copying them
+func (kv *keyValues) Len() (int, error) {
+	if err := kv.refreshLogRecords(); err != nil {
+		return 0, err
 	}
 
 	kv.mtx.Lock()
 	defer kv.mtx.Unlock()
 
-	return maps.Keys(kv.keys), nil
+	return len(kv.keys), nil
 }
 
 func (kv *keyValues) Has(key string) (bool, error) {
-	if err := kv.refreshKeys(); err != nil {
+	if err := kv.refreshLogRecords(); err != nil {
 		return false, err
 	}
 
@@ -153,6 +302,9 @@ func (kv *keyValues) Has(key string) (bool, error) {
 }
 
 func (kv *keyValues) absLogRecordsFilename() string {
+	if kv.binaryLog {
+		return filepath.Join(kv.dir, kevlarDirname, binaryLogRecordsFilename)
+	}
 	return filepath.Join(kv.dir, kevlarDirname, logRecordsFilename)
 }
 
@@ -165,7 +317,26 @@ func (kv *keyValues) absHashFilename(key string) string {
 }
 
 func (kv *keyValues) Get(key string) (io.ReadCloser, error) {
-	return os.Open(kv.absValueFilename(key))
+	return kv.GetContext(context.Background(), key)
+}
+
+func (kv *keyValues) get(key string) (*os.File, error) {
+	var file *os.File
+	err := kv.retry(func() (err error) {
+		file, err = os.Open(kv.absValueFilename(key))
+		return err
+	})
+	if os.IsNotExist(err) && kv.strict {
+		if ok, hasErr := kv.Has(key); hasErr != nil {
+			err = hasErr
+		} else if ok {
+			err = fmt.Errorf("%w: %s", ErrIndexFileMismatch, key)
+		}
+	}
+	if os.IsNotExist(err) {
+		err = kv.archivedErr(key, err)
+	}
+	return file, err
 }
 
 func (kv *keyValues) currentHash(key string) (string, error) {
@@ -177,26 +348,66 @@ func (kv *keyValues) currentHash(key string) (string, error) {
 		return "", err
 	}
 
-	absHashFilename := kv.absHashFilename(key)
-	if _, err := os.Stat(absHashFilename); err != nil {
+	return readSidecarFile(kv.absHashFilename(key))
+}
+
+// readSidecarFile returns content of a file stored alongside the value
+// (e.g. hash) or an empty string if that file doesn't exist
+func readSidecarFile(absFilename string) (string, error) {
+	if _, err := os.Stat(absFilename); err != nil {
 		return "", nil
 	}
-	hashFile, err := os.Open(absHashFilename)
+	file, err := os.Open(absFilename)
 	if err != nil {
 		return "", err
 	}
-	defer hashFile.Close()
+	defer file.Close()
 
 	sb := new(strings.Builder)
 
-	if _, err := io.Copy(sb, hashFile); err != nil {
+	if _, err := io.Copy(sb, file); err != nil {
 		return "", err
 	}
 
 	return sb.String(), nil
 }
 
+// createLogRecords encodes and writes log records, including all mutations
+// so far. Expects storage to be locked
 func (kv *keyValues) createLogRecords() error {
+	buf, err := kv.encodeLogRecords()
+	if err != nil {
+		return err
+	}
+
+	sum := crc64.Checksum(buf.Bytes(), crc64Table)
+	if err := kv.writeLogRecords(buf, kv.generation); err != nil {
+		return err
+	}
+
+	kv.logState = kv.statLogRecords()
+	kv.logState.sum = sum
+	kv.committedSeq = kv.logSeq
+	return nil
+}
+
+// encodeLogRecords encodes log records with the storage log encoding.
+// Expects storage to be locked
+func (kv *keyValues) encodeLogRecords() (*bytes.Buffer, error) {
+	buf := new(bytes.Buffer)
+	if kv.binaryLog {
+		if err := encodeBinaryLogRecords(buf, kv.log); err != nil {
+			return nil, err
+		}
+	} else if err := gob.NewEncoder(buf).Encode(kv.log); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// writeLogRecords writes encoded log records and the generation
+// they correspond to (see Generation)
+func (kv *keyValues) writeLogRecords(buf *bytes.Buffer, generation uint64) error {
 	absLogRecordsFilename := kv.absLogRecordsFilename()
 	dir, _ := filepath.Split(absLogRecordsFilename)
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
@@ -205,99 +416,163 @@ func (kv *keyValues) createLogRecords() error {
 		}
 	}
 
-	logFile, err := os.Create(absLogRecordsFilename)
-	if err != nil {
-		return err
+	if kv.beforeLogWrite != nil {
+		if err := kv.beforeLogWrite(absLogRecordsFilename); err != nil {
+			return err
+		}
 	}
-	defer logFile.Close()
 
-	if err := lockFd(logFile.Fd()); err != nil {
+	if err := kv.writeStaged(absLogRecordsFilename, buf); err != nil {
 		return err
 	}
 
-	if err := gob.NewEncoder(logFile).Encode(kv.log); err != nil {
+	if err := kv.writeGeneration(generation); err != nil {
 		return err
 	}
 
-	return unlockFd(logFile.Fd())
+	if kv.afterLogWrite != nil {
+		return kv.afterLogWrite(absLogRecordsFilename)
+	}
+
+	return nil
 }
 
-func (kv *keyValues) appendLogRecord(rec *logRecord) error {
-	if err := kv.refreshLogRecords(); err != nil {
+// committed commits log records up to the mutation with the sequence
+// number returned by persistLogRecords, when one was returned
+func (kv *keyValues) committed(seq uint64, err error) error {
+	if err != nil || seq == 0 {
 		return err
 	}
+	return kv.commitLogRecords(seq)
+}
 
-	kv.mtx.Lock()
-	defer kv.mtx.Unlock()
-
-	kv.log = append(kv.log, rec)
-
-	return kv.createLogRecords()
-
+// appendLogRecord appends log record and returns the sequence number of the
+// mutation that should be committed (see committed)
+func (kv *keyValues) appendLogRecord(rec *logRecord) (uint64, error) {
+	return kv.appendLogRecords(rec)
 }
 
-func (kv *keyValues) createLogRecord(key string) error {
-	// adding the key right away to respond to Has queries before log update
+// appendLogRecords appends log records as a single mutation
+// (see appendLogRecord)
+func (kv *keyValues) appendLogRecords(recs ...*logRecord) (uint64, error) {
+	if err := kv.refreshLogRecords(); err != nil {
+		return 0, err
+	}
+
 	kv.mtx.Lock()
-	kv.keys[key] = nil
+	for _, rec := range recs {
+		kv.log = append(kv.log, rec)
+		switch rec.Mt {
+		case create:
+			kv.keys[rec.Id] = nil
+		case cut:
+			delete(kv.keys, rec.Id)
+		}
+		if rec.Mt != update {
+			kv.sortedKeys = nil
+		}
+	}
+	seq, err := kv.persistLogRecords()
 	kv.mtx.Unlock()
 
+	return seq, err
+}
+
+func (kv *keyValues) createLogRecord(key string, size int64) (uint64, error) {
 	rec := &logRecord{
 		Ts: time.Now().Unix(),
 		Mt: create,
 		Id: key,
+		Sz: size,
 	}
 
 	return kv.appendLogRecord(rec)
 }
 
-func (kv *keyValues) updateLogRecord(key string) error {
+// updateLogRecord updates the update log record of the current value in
+// place. Update records before the latest create describe values that have
+// been cut, so these are never reused and a new record is appended instead
+func (kv *keyValues) updateLogRecord(key string, size int64) (uint64, error) {
 	kv.mtx.Lock()
-	updated := false
-	for _, rec := range kv.log {
-		if rec.Id == key && rec.Mt == update {
+	for ii := len(kv.log) - 1; ii >= 0; ii-- {
+		rec := kv.log[ii]
+		if rec.Id != key {
+			continue
+		}
+		if rec.Mt == update {
 			rec.Ts = time.Now().Unix()
-			updated = true
-			break
+			rec.Sz = size
+			seq, err := kv.persistLogRecords()
+			kv.mtx.Unlock()
+			return seq, err
 		}
+		// create or cut
+		break
 	}
 	kv.mtx.Unlock()
 
-	if updated {
-		return kv.createLogRecords()
-	} else {
-		rec := &logRecord{
-			Ts: time.Now().Unix(),
-			Mt: update,
-			Id: key,
+	rec := &logRecord{
+		Ts: time.Now().Unix(),
+		Mt: update,
+		Id: key,
+		Sz: size,
+	}
+	return kv.appendLogRecord(rec)
+}
+
+// currentLogRecords returns the latest create log record of the key and the
+// latest update log record after it. Both are nil when the key doesn't exist,
+// update is nil when the value hasn't been updated since it's been created.
+// Expects storage to be locked
+func (kv *keyValues) currentLogRecords(key string) (created, updated *logRecord) {
+	for ii := len(kv.log) - 1; ii >= 0; ii-- {
+		lr := kv.log[ii]
+		if lr.Id != key {
+			continue
+		}
+		switch lr.Mt {
+		case create:
+			return lr, updated
+		case update:
+			if updated == nil {
+				updated = lr
+			}
+		case cut:
+			return nil, nil
 		}
-		return kv.appendLogRecord(rec)
 	}
+	return nil, nil
 }
 
-func (kv *keyValues) createOrUpdateLogRecord(key string) error {
+// createOrUpdateLogRecord logs the value that has been set. Created keys
+// don't have any value timestamps (e.g. stubs of archived values), since
+// these describe values that have been cut. Expects mutations to be
+// allowed (see mutating)
+func (kv *keyValues) createOrUpdateLogRecord(key string, size int64) (uint64, error) {
 	if ok, err := kv.Has(key); err == nil {
 		if ok {
-			return kv.updateLogRecord(key)
-		} else {
-			return kv.createLogRecord(key)
+			return kv.updateLogRecord(key, size)
 		}
 	} else {
-		return err
+		return 0, err
+	}
+
+	for _, filename := range valueTimestampsFilenames {
+		if err := kv.cutTimestamps(filename, key); err != nil {
+			return 0, err
+		}
 	}
+
+	return kv.createLogRecord(key, size)
 }
 
-func (kv *keyValues) cutLogRecord(key string) error {
+func (kv *keyValues) cutLogRecord(key string) (uint64, error) {
 	rec := &logRecord{
 		Ts: time.Now().Unix(),
 		Mt: cut,
 		Id: key,
 	}
 
-	kv.mtx.Lock()
-	delete(kv.keys, key)
-	kv.mtx.Unlock()
-
 	return kv.appendLogRecord(rec)
 }
 
@@ -310,99 +585,239 @@ func (kv *keyValues) createHashFile(key, hash string) error {
 		}
 	}
 
-	hashFile, err := os.Create(absHashFilename)
+	return kv.writeStaged(absHashFilename, strings.NewReader(hash))
+}
+
+// Set writes the value to storage if the value has changed since the
+// last time it was written. This is validated with a SHA-256 hash that
+// is stored alongside the value in storage
+func (kv *keyValues) Set(key string, reader io.Reader) error {
+	return kv.SetContext(context.Background(), key, reader)
+}
+
+func (kv *keyValues) set(key string, reader io.Reader) (int64, error) {
+
+	done, err := kv.mutating()
 	if err != nil {
-		return err
+		return 0, err
 	}
-	defer hashFile.Close()
+	defer done()
 
-	if _, err := io.Copy(hashFile, strings.NewReader(hash)); err != nil {
-		return err
+	if kv.conflictPolicy != Overwrite {
+		return kv.setWithStoragePolicy(key, reader)
 	}
 
-	return nil
+	size, seq, err := kv.setValue(key, reader)
+	return size, kv.committed(seq, err)
 }
 
-// Set writes the value to storage if the value has changed since the
-// last time it was written. This is validated with a SHA-256 hash that
-// is stored alongside the value in storage
-func (kv *keyValues) Set(key string, reader io.Reader) error {
+// setValue writes the value and returns its size and the sequence number
+// of the log records mutation that should be committed (see committed).
+// Expects mutations to be allowed (see mutating)
+func (kv *keyValues) setValue(key string, reader io.Reader) (int64, uint64, error) {
+	size, _, seq, err := kv.setValueIf(key, reader, nil)
+	return size, seq, err
+}
+
+// setValueIf writes the value same as setValue when the current value hash
+// matches the expected hash (or unconditionally, when it's nil) and
+// reports whether it matched
+func (kv *keyValues) setValueIf(key string, reader io.Reader, expectedHash *string) (int64, bool, uint64, error) {
+	size, seq, err := kv.setMatchingValue(key, reader, expectedHash)
+	if errors.Is(err, errHashMismatch) {
+		return size, false, 0, nil
+	}
+	return size, true, seq, err
+}
 
-	var buf bytes.Buffer
-	tr := io.TeeReader(reader, &buf)
+// setMatchingValue returns errHashMismatch when the current
+// value hash doesn't match the expected one
+func (kv *keyValues) setMatchingValue(key string, reader io.Reader, expectedHash *string) (int64, uint64, error) {
+	if err := kv.validateKey(key); err != nil {
+		return 0, 0, err
+	}
 
-	// check if value already exists and has the same hash
-	hash, err := Sha256(tr)
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if _, err := io.Copy(buf, reader); err != nil {
+		return 0, 0, err
+	}
+
+	size := int64(buf.Len())
+
+	if err := kv.sniffValue(key, buf.Bytes()); err != nil {
+		return size, 0, err
+	}
+
+	unlock := kv.lockKey(key)
+	defer unlock()
+
+	release, err := kv.reserveKey(key)
 	if err != nil {
-		return err
+		return size, 0, err
 	}
+	defer release()
+
+	if expectedHash != nil {
+		if currentHash, err := kv.currentHash(key); err != nil {
+			return size, 0, err
+		} else if currentHash != *expectedHash {
+			return size, 0, errHashMismatch
+		}
+	}
+
+	// when enabled, compare fast hash first to avoid computing
+	// SHA-256 for values that haven't changed
+	var fh string
+	if kv.fastHash {
+		fh = fastHash(buf.Bytes())
+		if currentFastHash, err := kv.currentFastHash(key); err != nil {
+			return size, 0, err
+		} else if fh == currentFastHash {
+			kv.stats.unchangedSets.Add(1)
+			return size, 0, nil
+		}
+	}
+
+	// check if value already exists and has the same hash
+	hash := sha256Hex(buf.Bytes())
 
 	currentHash, err := kv.currentHash(key)
 	if err != nil {
-		return err
+		return size, 0, err
 	}
 
 	// the latest value is already set
 	if hash == currentHash {
-		return nil
+		kv.stats.unchangedSets.Add(1)
+		if kv.fastHash {
+			return size, 0, kv.createFastHashFile(key, fh)
+		}
+		return size, 0, nil
+	}
+
+	linked := false
+	if kv.linkDuplicates {
+		if linked, err = kv.linkDuplicate(key, hash); err != nil {
+			return size, 0, err
+		}
+	}
+
+	// write value first, so that a failed write (e.g. out of disk space)
+	// keeps existing value, hash and log record unchanged
+	if !linked {
+		if err := kv.writeStaged(kv.absValueFilename(key), buf); err != nil {
+			return size, 0, err
+		}
 	}
 
 	if err := kv.createHashFile(key, hash); err != nil {
-		return err
+		return size, 0, err
 	}
 
-	// write value
-	file, err := os.Create(kv.absValueFilename(key))
-	if err != nil {
-		return err
+	if kv.linkDuplicates {
+		kv.setHashKey(hash, key)
 	}
-	defer file.Close()
 
-	if _, err = io.Copy(file, &buf); err != nil {
-		return err
+	if kv.fastHash {
+		if err := kv.createFastHashFile(key, fh); err != nil {
+			return size, 0, err
+		}
 	}
 
-	return kv.createOrUpdateLogRecord(key)
+	seq, err := kv.createOrUpdateLogRecord(key, size)
+	return size, seq, err
 }
 
 // Cut removes the value from storage in the following sequence of events:
 // - cut operation log value is added
 // - stored hash value is removed
+// - derived variants are removed
 // - stored value is removed
 func (kv *keyValues) Cut(key string) (bool, error) {
+	return kv.CutContext(context.Background(), key)
+}
+
+func (kv *keyValues) cut(key string) (bool, error) {
+	done, err := kv.mutating()
+	if err != nil {
+		return false, err
+	}
+	defer done()
+
+	ok, seq, err := kv.cutValue(key, false)
+	return ok, kv.committed(seq, err)
+}
+
+// cutValue removes the value and returns the sequence number of the log
+// records mutation that should be committed (see committed). Values cut
+// by Archive keep the stub written before they're cut. Expects mutations
+// to be allowed (see mutating)
+func (kv *keyValues) cutValue(key string, archived bool) (bool, uint64, error) {
+	unlock := kv.lockKey(key)
+	defer unlock()
+
 	if ok, err := kv.Has(key); err == nil {
 		if !ok {
-			return false, nil
+			return false, 0, nil
 		}
 	} else {
-		return false, err
+		return false, 0, err
 	}
 
 	absHashFilename := kv.absHashFilename(key)
 	if _, err := os.Stat(absHashFilename); err == nil {
 		if err := os.Remove(absHashFilename); err != nil {
-			return false, err
+			return false, 0, err
+		}
+	}
+
+	for _, absSidecarFilename := range []string{kv.absFastHashFilename(key), kv.absHashStateFilename(key)} {
+		if _, err := os.Stat(absSidecarFilename); err == nil {
+			if err := os.Remove(absSidecarFilename); err != nil {
+				return false, 0, err
+			}
+		}
+	}
+
+	if err := kv.cutDerived(key); err != nil {
+		return false, 0, err
+	}
+
+	// timestamps must not apply to values set for the key later
+	for _, filename := range slices.Concat(keyTimestampsFilenames, valueTimestampsFilenames) {
+		if archived && filename == archivedFilename {
+			continue
+		}
+		if err := kv.cutTimestamps(filename, key); err != nil {
+			return false, 0, err
 		}
 	}
 
 	absValueFilename := kv.absValueFilename(key)
 	if _, err := os.Stat(absValueFilename); err == nil {
 		if err := os.Remove(absValueFilename); err != nil {
-			return false, err
+			return false, 0, err
 		}
 	}
 
-	if err := kv.cutLogRecord(key); err != nil {
-		return false, err
+	seq, err := kv.cutLogRecord(key)
+	if err != nil {
+		return false, 0, err
 	}
 
-	return true, nil
+	return true, seq, nil
 }
 
 func (kv *keyValues) filterLog(m func(*logRecord) bool) ([]string, error) {
 	if err := kv.refreshLogRecords(); err != nil {
 		return nil, err
 	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
 	matches := make(map[string]any)
 	for _, lr := range kv.log {
 		if m(lr) {
@@ -453,6 +868,8 @@ func (kv *keyValues) ModTime(key string) (int64, error) {
 		return fi.ModTime().Unix(), nil
 	} else if os.IsNotExist(err) {
 		// key could have been deleted - check the log
+		kv.mtx.Lock()
+		defer kv.mtx.Unlock()
 		for _, lr := range kv.log {
 			if lr.Id == key && lr.Mt == cut {
 				return lr.Ts, nil
@@ -1,16 +1,49 @@
 package kevlar
 
 import (
+	"context"
 	"io"
+	"io/fs"
+	"time"
 )
 
+// KeyValues are operations that need storage internals (the index, log records,
+// value files or storage extension). Helpers that only need other KeyValues
+// methods (e.g. Preview, GetMany) are functions that take KeyValues
 type KeyValues interface {
 	Keys() ([]string, error)
+	KeysWithPrefix(prefix string) ([]string, error)
+	KeysMatching(pattern string) ([]string, error)
+	KeysPage(offset, limit int) ([]string, error)
+	KeysSorted(by SortField, desc bool) ([]string, error)
+	Len() (int, error)
 	Has(key string) (bool, error)
+	SampleKeys(n int) ([]string, error)
 
 	Get(key string) (io.ReadCloser, error)
+	GetContext(ctx context.Context, key string) (io.ReadCloser, error)
+	GetIfModifiedSince(key string, ts int64) (io.ReadCloser, bool, error)
+	GetArchive(w io.Writer, keys ...string) error
+	RenderValue(key string, data any, w io.Writer) error
 	Set(key string, data io.Reader) error
+	SetContext(ctx context.Context, key string, data io.Reader) error
+	SetIfMatch(key string, data io.Reader, expectedHash string) (bool, error)
+	SetWithPolicy(key string, data io.Reader, policy ConflictPolicy, ts int64) (string, error)
+	SetMany(keyReaders map[string]io.Reader) error
+	SetWriter(key string) (io.WriteCloser, error)
+	Append(key string, data io.Reader) error
+	SetFromFile(key, path string, move bool) error
+	PatchJSON(key string, patch []byte) error
+	SeedFrom(fsys fs.FS, overwrite bool) error
+	SetArchive(r io.ReaderAt, size int64, skipCorrupt bool) (map[string]error, error)
 	Cut(key string) (bool, error)
+	CutContext(ctx context.Context, key string) (bool, error)
+	CutMany(keys ...string) (map[string]bool, error)
+	Copy(src, dst string) error
+	Rename(src, dst string) error
+
+	SetDerived(key, variant string, data io.Reader) error
+	GetDerived(key, variant string) (io.ReadCloser, error)
 
 	IsCurrent() (bool, int64)
 	CreatedAfter(ts int64) ([]string, error)
@@ -19,4 +52,35 @@ type KeyValues interface {
 	IsUpdatedAfter(key string, ts int64) (bool, error)
 
 	ModTime(key string) (int64, error)
+	Size(key string) (int64, error)
+	TotalSize() (int64, error)
+	GetRecord(key string) (Record, bool)
+
+	Snapshot() (string, error)
+	ChangedSince(snapshotId string) (*Changes, error)
+	Generation() (Generation, error)
+
+	SetRefreshAfter(key string, ts int64) error
+	DueForRefresh(ts int64) ([]string, error)
+
+	SetWithExpiry(key string, data io.Reader, ttl time.Duration) error
+	PruneExpired() ([]string, error)
+
+	Pin(key string) error
+	Unpin(key string) error
+	PinnedKeys() ([]string, error)
+
+	Flush() error
+	Freeze() error
+	Thaw()
+	Close() error
+
+	VetContent(quarantine bool) ([]string, error)
+	VetKeys() ([]string, error)
+	ApplyRetention(rules ...RetentionRule) ([]string, error)
+	Archive(pred func(Record) bool, dst KeyValues) (int, error)
+	PrefixStats(delimiter string) (map[string]PrefixStat, error)
+	Stats() Stats
+	SizeDistribution(buckets []int64) (map[string]int, error)
+	SizePercentile(p float64) (int64, error)
 }
@@ -0,0 +1,242 @@
+package kevlar
+
+import (
+	"log/slog"
+	"maps"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+// KeyValuesOption configures optional behaviour of the key values storage
+type KeyValuesOption func(kv *keyValues)
+
+// WithStagingDir sets the directory used to stage writes before they're
+// renamed into place. Staging directory must be on the same device as
+// the storage directory, otherwise renames won't be atomic
+func WithStagingDir(dir string) KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.stagingDir = dir
+	}
+}
+
+// WithFastHash enables storing a fast hash (value size and CRC-64)
+// alongside SHA-256. Set compares fast hashes first and skips computing
+// SHA-256 for values that haven't changed
+func WithFastHash() KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.fastHash = true
+	}
+}
+
+// WithLinkDuplicates enables hard-linking values that have the same content
+// (as determined by SHA-256) instead of storing separate copies
+func WithLinkDuplicates() KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.linkDuplicates = true
+	}
+}
+
+// LogWriteHook is called with the absolute filename of the log records file.
+// Hooks are called while log records writes are serialized and must not call
+// storage methods
+type LogWriteHook func(absFilename string) error
+
+// WithBeforeLogWrite sets a hook called before log records are written,
+// e.g. to throttle writes. Returning an error aborts the write
+func WithBeforeLogWrite(hook LogWriteHook) KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.beforeLogWrite = hook
+	}
+}
+
+// WithAfterLogWrite sets a hook called after log records have been written,
+// e.g. to mirror the log elsewhere
+func WithAfterLogWrite(hook LogWriteHook) KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.afterLogWrite = hook
+	}
+}
+
+// WithFlushEvery defers writing log records until the specified number of
+// mutations has accumulated. Flush or Close must be called to write
+// remaining log records
+func WithFlushEvery(mutations int) KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.flushMutations = mutations
+	}
+}
+
+// WithFlushInterval defers writing log records for up to the specified
+// duration after a mutation. Flush or Close must be called to write
+// remaining log records
+func WithFlushInterval(interval time.Duration) KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.flushInterval = interval
+	}
+}
+
+// WithStrict makes Get return ErrIndexFileMismatch when the key is present
+// in the log records, but the value file is missing, instead of an error
+// that is indistinguishable from a missing key
+func WithStrict() KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.strict = true
+	}
+}
+
+// WithKeyPattern restricts keys accepted by Set to the ones matching
+// the allow-list pattern, e.g. regexp.MustCompile(`^[0-9]+$`).
+// Keys are validated in addition to the checks applied to all keys
+func WithKeyPattern(pattern *regexp.Regexp) KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.keyPattern = pattern
+	}
+}
+
+// WithMaxKeyLength limits the length (in bytes) of keys accepted by Set.
+// Longer keys are rejected with ErrKeyTooLong
+func WithMaxKeyLength(n int) KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.maxKeyLength = n
+	}
+}
+
+// WithMaxKeys limits the number of keys in storage. Setting a new key
+// when the limit has been reached fails with ErrTooManyKeys. Updating
+// existing keys is not affected
+func WithMaxKeys(n int) KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.maxKeys = n
+	}
+}
+
+// WithBinaryLog stores log records in a compact binary encoding that is
+// faster to encode and decode than gob for large logs. Existing log records
+// are always used in their format, regardless of this option: gob log
+// records must be converted with ConvertLogToBinary first
+func WithBinaryLog() KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.binaryLog = true
+	}
+}
+
+// WithTimeout bounds the time Get, Set and Cut wait for filesystem operations
+// (e.g. on a hung network mount). Operations that take longer return an error
+// wrapping context.DeadlineExceeded, while the underlying filesystem call might
+// still complete in the background. Set readers must not be reused after timeout
+func WithTimeout(timeout time.Duration) KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.timeout = timeout
+	}
+}
+
+// WithRetry retries value and log records filesystem operations that fail with
+// transient errors (see IsTransient) up to the specified number of attempts,
+// waiting for backoff before the first retry and doubling it after each one
+func WithRetry(attempts int, backoff time.Duration) KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.retryAttempts = attempts
+		kv.retryBackoff = backoff
+	}
+}
+
+// WithRetryClassifier replaces IsTransient as the function that decides
+// whether an error should be retried. It has no effect without WithRetry
+func WithRetryClassifier(retryable func(err error) bool) KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.retryClassifier = retryable
+	}
+}
+
+// WithSlowOpLog logs Get, Set and Cut operations that take longer than the
+// threshold with their key and bytes, so that slow disks and huge values
+// are visible without tracing. Nil logger uses slog.Default()
+func WithSlowOpLog(threshold time.Duration, logger *slog.Logger) KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.slowOpThreshold = threshold
+		kv.slowOpLogger = logger
+	}
+}
+
+// WithHtmlSniffing rejects values that are clearly not HTML (e.g. JSON error
+// bodies or binary content) with ErrContentMismatch when they're set into
+// HtmlExt storage. Storage with other extensions is not affected
+func WithHtmlSniffing() KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.htmlSniffing = true
+	}
+}
+
+// WithConflictPolicy changes what writes do when the key already exists,
+// e.g. ErrorIfExists never replaces existing values. Set, SetMany,
+// SetFromFile and SetWriter resolve conflicts with the policy, while
+// Append, Copy and Rename fail with ErrKeyExists for existing keys unless
+// the policy is Overwrite. KeepNewest needs the timestamp of the new value
+// and can only be used with SetWithPolicy, connecting fails otherwise.
+// Policy can be overridden for a single value with SetWithPolicy
+func WithConflictPolicy(policy ConflictPolicy) KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.conflictPolicy = policy
+	}
+}
+
+// WithLabels attaches labels (e.g. app, dataset, tier) to the storage, so that
+// processes with several storages can attribute load to them. Labels are
+// reported in Stats (and published with PublishStats), slow operation logs
+// and spans that implement LabeledSpan
+func WithLabels(labels map[string]string) KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.labels = maps.Clone(labels)
+	}
+}
+
+// WithMaintenance runs maintenance tasks (retention, pruning expired values,
+// vetting content, compacting reductions) in a background goroutine when
+// storage has been idle, so that long-running servers maintain their
+// storage. Maintenance is stopped by Close. Runs and errors are counted
+// in Stats
+func WithMaintenance(m Maintenance) KeyValuesOption {
+	return func(kv *keyValues) {
+		kv.maintenance = &m
+	}
+}
+
+// sameOptions reports whether key values have been configured with the same
+// options. Hooks and classifiers are compared by function, so closures
+// created by the same function literal are considered the same. Binary log
+// is not compared, since existing log records are used in their format
+func (kv *keyValues) sameOptions(okv *keyValues) bool {
+	return kv.stagingDir == okv.stagingDir &&
+		kv.fastHash == okv.fastHash &&
+		kv.linkDuplicates == okv.linkDuplicates &&
+		sameFunc(kv.beforeLogWrite, okv.beforeLogWrite) &&
+		sameFunc(kv.afterLogWrite, okv.afterLogWrite) &&
+		kv.flushMutations == okv.flushMutations &&
+		kv.flushInterval == okv.flushInterval &&
+		kv.strict == okv.strict &&
+		samePattern(kv.keyPattern, okv.keyPattern) &&
+		kv.maxKeyLength == okv.maxKeyLength &&
+		kv.maxKeys == okv.maxKeys &&
+		kv.timeout == okv.timeout &&
+		kv.retryAttempts == okv.retryAttempts &&
+		kv.retryBackoff == okv.retryBackoff &&
+		sameFunc(kv.retryClassifier, okv.retryClassifier) &&
+		kv.slowOpThreshold == okv.slowOpThreshold &&
+		kv.slowOpLogger == okv.slowOpLogger &&
+		kv.htmlSniffing == okv.htmlSniffing &&
+		kv.conflictPolicy == okv.conflictPolicy &&
+		maps.Equal(kv.labels, okv.labels) &&
+		reflect.DeepEqual(kv.maintenance, okv.maintenance)
+}
+
+func sameFunc(f1, f2 any) bool {
+	return reflect.ValueOf(f1).Pointer() == reflect.ValueOf(f2).Pointer()
+}
+
+func samePattern(p1, p2 *regexp.Regexp) bool {
+	if p1 == nil || p2 == nil {
+		return p1 == p2
+	}
+	return p1.String() == p2.String()
+}
@@ -0,0 +1,214 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestKeyValues_LogWriteHooks(t *testing.T) {
+	calls := make([]string, 0)
+	before := func(absFilename string) error {
+		calls = append(calls, "before")
+		return nil
+	}
+	after := func(absFilename string) error {
+		_, err := os.Stat(absFilename)
+		calls = append(calls, "after")
+		return err
+	}
+
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt,
+		WithBeforeLogWrite(before),
+		WithAfterLogWrite(after))
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("hooks", strings.NewReader("hooks")), false)
+	testo.DeepEqual(t, calls, []string{"before", "after"})
+
+	ok, err := kv.Cut("hooks")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(calls), 4)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_BeforeLogWriteError(t *testing.T) {
+	ikv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt,
+		WithBeforeLogWrite(func(string) error { return errors.New("aborted") }))
+	testo.Error(t, err, false)
+
+	kv, ok := ikv.(*keyValues)
+	testo.EqualValues(t, ok, true)
+
+	testo.Error(t, kv.Set("hooks", strings.NewReader("hooks")), true)
+
+	_, err = os.Stat(kv.absLogRecordsFilename())
+	testo.EqualValues(t, os.IsNotExist(err), true)
+
+	kv.beforeLogWrite = nil
+	ok, err = kv.Cut("hooks")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, ikv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_WithStrict(t *testing.T) {
+	ikv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt, WithStrict())
+	testo.Error(t, err, false)
+
+	kv, ok := ikv.(*keyValues)
+	testo.EqualValues(t, ok, true)
+
+	testo.Error(t, kv.Set("strict", strings.NewReader("strict")), false)
+	testo.Error(t, os.Remove(kv.absValueFilename("strict")), false)
+
+	_, err = kv.Get("strict")
+	testo.EqualValues(t, errors.Is(err, ErrIndexFileMismatch), true)
+
+	_, err = kv.Get("key-that-doesnt-exist")
+	testo.Error(t, err, true)
+	testo.EqualValues(t, errors.Is(err, ErrIndexFileMismatch), false)
+
+	ok, err = kv.Cut("strict")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, ikv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestWithKeyPattern(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt,
+		WithKeyPattern(regexp.MustCompile(`^[0-9]+$`)))
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("1", strings.NewReader("value")), false)
+	testo.EqualValues(t, errors.Is(kv.Set("one", strings.NewReader("value")), ErrInvalidKey), true)
+
+	ok, err := kv.Cut("1")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestWithMaxKeyLength(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt, WithMaxKeyLength(4))
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("four", strings.NewReader("value")), false)
+	testo.EqualValues(t, errors.Is(kv.Set("fives", strings.NewReader("value")), ErrKeyTooLong), true)
+
+	ok, err := kv.Cut("four")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestWithMaxKeys(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt, WithMaxKeys(2))
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("1", strings.NewReader("value")), false)
+	testo.Error(t, kv.Set("2", strings.NewReader("value")), false)
+	testo.EqualValues(t, errors.Is(kv.Set("3", strings.NewReader("value")), ErrTooManyKeys), true)
+
+	// updating existing keys is allowed at the limit
+	testo.Error(t, kv.Set("2", strings.NewReader("updated value")), false)
+
+	for _, key := range []string{"1", "2"} {
+		ok, err := kv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestWithMaxKeys_Concurrent(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt, WithMaxKeys(4))
+	testo.Error(t, err, false)
+
+	errs := make(chan error, 16)
+	wg := new(sync.WaitGroup)
+	for ii := 0; ii < cap(errs); ii++ {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			errs <- kv.Set(key, strings.NewReader("value"))
+		}(strconv.Itoa(ii))
+	}
+	wg.Wait()
+	close(errs)
+
+	set := 0
+	for err := range errs {
+		if err == nil {
+			set++
+		} else {
+			testo.EqualValues(t, errors.Is(err, ErrTooManyKeys), true)
+		}
+	}
+	testo.EqualValues(t, set, 4)
+
+	keys, err := kv.Keys()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(keys), 4)
+
+	_, err = kv.CutMany(keys...)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestWithHtmlSniffing(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), HtmlExt, WithHtmlSniffing())
+	testo.Error(t, err, false)
+
+	tests := []struct {
+		content  string
+		mismatch bool
+	}{
+		{"<!DOCTYPE html><html></html>", false},
+		{"<span>fragment</span>", false},
+		{"", false},
+		{"  {\"error\": \"not found\"}", true},
+		{"[1, 2]", true},
+		{"\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR", true},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			err := kv.Set("sniffed", strings.NewReader(tt.content))
+			testo.EqualValues(t, errors.Is(err, ErrContentMismatch), tt.mismatch)
+		})
+	}
+
+	path := filepath.Join(os.TempDir(), "sniffed.json")
+	testo.Error(t, os.WriteFile(path, []byte("{}"), 0644), false)
+	testo.EqualValues(t, errors.Is(kv.SetFromFile("sniffed", path, false), ErrContentMismatch), true)
+	testo.Error(t, os.Remove(path), false)
+
+	ok, err := kv.Cut("sniffed")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
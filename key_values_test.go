@@ -9,6 +9,7 @@ import (
 	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"runtime/debug"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,9 +23,9 @@ const (
 
 func mockKeyValues() *keyValues {
 	return &keyValues{
-		dir: filepath.Join(os.TempDir(), testsDirname),
-		ext: GobExt,
-		lmt: -1,
+		dir:      filepath.Join(os.TempDir(), testsDirname),
+		ext:      GobExt,
+		logState: logState{modTime: -1},
 		log: []*logRecord{
 			{
 				Ts: 1,
@@ -68,6 +69,13 @@ func mockKeyValues() *keyValues {
 //	return os.Remove(logModPath)
 //}
 
+// corruptLogRecords replaces log records with data that can't be decoded,
+// e.g. to test that refresh errors are returned
+func corruptLogRecords(t *testing.T) {
+	logPath := filepath.Join(os.TempDir(), testsDirname, kevlarDirname, logRecordsFilename)
+	testo.Error(t, os.WriteFile(logPath, []byte("not log records"), 0644), false)
+}
+
 func logRecordsCleanup() error {
 	logPath := filepath.Join(os.TempDir(), testsDirname, kevlarDirname, logRecordsFilename)
 	if _, err := os.Stat(logPath); err != nil {
@@ -83,6 +91,8 @@ func TestNewKeyValues(t *testing.T) {
 	lkv, err := NewKeyValues(os.TempDir(), JsonExt)
 	testo.Nil(t, lkv, false)
 	testo.Error(t, err, false)
+
+	testo.Error(t, lkv.Close(), false)
 }
 
 func TestLocalKeyValuesSetHasGetCut(t *testing.T) {
@@ -109,6 +119,9 @@ func TestLocalKeyValuesSetHasGetCut(t *testing.T) {
 				testo.EqualValues(t, has, true)
 				testo.Error(t, err, false)
 			}
+			n, err := kv.Len()
+			testo.Error(t, err, false)
+			testo.EqualValues(t, n, len(tt.set))
 
 			// Get tests
 			for gk, expNil := range tt.get {
@@ -137,7 +150,11 @@ func TestLocalKeyValuesSetHasGetCut(t *testing.T) {
 				testo.EqualValues(t, ok, has)
 				testo.Error(t, err, false)
 			}
+			n, err = kv.Len()
+			testo.Error(t, err, false)
+			testo.EqualValues(t, n, 0)
 
+			testo.Error(t, kv.Close(), false)
 			testo.Error(t, logRecordsCleanup(), false)
 
 		})
@@ -283,6 +300,7 @@ func TestLocalKeyValues_ModTime(t *testing.T) {
 	testo.EqualValues(t, ok, true)
 	testo.Error(t, err, false)
 
+	testo.Error(t, kv.Close(), false)
 	testo.Error(t, logRecordsCleanup(), false)
 }
 
@@ -354,6 +372,7 @@ func TestKeyValues_GoroutineSafe(t *testing.T) {
 	testo.Error(t, err, false)
 	testo.EqualValues(t, len(keys), 0)
 
+	testo.Error(t, kv.Close(), false)
 	testo.Error(t, logRecordsCleanup(), false)
 }
 
@@ -447,5 +466,124 @@ func TestKeyValues_UpdatesPreventLogGrowth(t *testing.T) {
 	testo.EqualValues(t, ok, true)
 	testo.Error(t, err, false)
 
+	testo.Error(t, ikv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func openFileDescriptors(t *testing.T) int {
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skip("open file descriptors can't be counted on this platform")
+	}
+	return len(fds)
+}
+
+func TestKeyValues_NoFileDescriptorLeaks(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname, "fd-leaks")
+	testo.Error(t, os.MkdirAll(dir, 0755), false)
+
+	kv, err := NewKeyValues(dir, GobExt, WithStrict(), WithFastHash())
+	testo.Error(t, err, false)
+
+	// warm up, so that descriptors opened once by the runtime
+	// (e.g. network poller) are not counted as leaks
+	testo.Error(t, kv.Set("warm-up", strings.NewReader("warm-up")), false)
+	rc, err := kv.Get("warm-up")
+	testo.Error(t, err, false)
+	testo.Error(t, rc.Close(), false)
+
+	// leaked files would be closed by finalizers otherwise
+	defer debug.SetGCPercent(debug.SetGCPercent(-1))
+
+	before := openFileDescriptors(t)
+
+	const n = 2000
+	for ii := 0; ii < n; ii++ {
+		key := strconv.Itoa(ii)
+		testo.Error(t, kv.Set(key, strings.NewReader(key)), false)
+		// the same value doesn't write anything
+		testo.Error(t, kv.Set(key, strings.NewReader(key)), false)
+
+		rc, err := kv.Get(key)
+		testo.Error(t, err, false)
+		testo.Error(t, rc.Close(), false)
+
+		// error paths must not leak descriptors either
+		_, err = kv.Get("missing-" + key)
+		testo.Error(t, err, true)
+		testo.Error(t, kv.SetFromFile(key, filepath.Join(dir, "missing-"+key), false), true)
+		testo.Error(t, GetToFile(kv, key, filepath.Join(dir, "missing", key), 0644), true)
+	}
+
+	for ii := 0; ii < n; ii++ {
+		ok, err := kv.Cut(strconv.Itoa(ii))
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, kv.Close(), false)
+	testo.EqualValues(t, openFileDescriptors(t), before)
+
+	testo.Error(t, os.RemoveAll(dir), false)
+}
+
+func TestKeyValues_KeysWithPrefix(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	keys := []string{"user/1/avatar", "user/1/profile", "user/12/avatar", "group/1"}
+	for _, key := range keys {
+		testo.Error(t, kv.Set(key, strings.NewReader(key)), false)
+	}
+
+	tests := []struct {
+		prefix string
+		exp    []string
+	}{
+		{"", keys},
+		{"user/1/", []string{"user/1/avatar", "user/1/profile"}},
+		{"user/1", []string{"user/1/avatar", "user/1/profile", "user/12/avatar"}},
+		{"group/", []string{"group/1"}},
+		{"none/", []string{}},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			prefixed, err := kv.KeysWithPrefix(tt.prefix)
+			testo.Error(t, err, false)
+			testo.EqualValues(t, len(prefixed), len(tt.exp))
+			for _, key := range tt.exp {
+				testo.EqualValues(t, slices.Contains(prefixed, key), true)
+			}
+		})
+	}
+
+	_, err = kv.CutMany(keys...)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_RefreshErrors(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("len", strings.NewReader("len")), false)
+	corruptLogRecords(t)
+
+	// stale number of keys must not be returned
+	_, err = kv.Len()
+	testo.Error(t, err, true)
+	_, err = kv.KeysWithPrefix("")
+	testo.Error(t, err, true)
+	_, err = kv.KeysPage(0, 10)
+	testo.Error(t, err, true)
+	_, err = kv.KeysSorted(Created, false)
+	testo.Error(t, err, true)
+
+	testo.Error(t, kv.Close(), false)
 	testo.Error(t, logRecordsCleanup(), false)
+	testo.Error(t, os.Remove(kv.(*keyValues).absValueFilename("len")), false)
+	testo.Error(t, os.Remove(kv.(*keyValues).absHashFilename("len")), false)
 }
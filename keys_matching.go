@@ -0,0 +1,56 @@
+package kevlar
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// RegexpKeyPattern prefix makes KeysMatching pattern a regular expression
+const RegexpKeyPattern = "re:"
+
+// CompileKeyPattern compiles KeysMatching pattern into a key matcher. Patterns
+// are globs (see path.Match, e.g. order-2023-*), unless they start with
+// RegexpKeyPattern (e.g. re:^order-2023-[0-9]+$)
+func CompileKeyPattern(pattern string) (func(key string) bool, error) {
+	if expr, ok := strings.CutPrefix(pattern, RegexpKeyPattern); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+
+	// pattern is validated upfront, so that matching keys
+	// doesn't need to report errors
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	return func(key string) bool {
+		ok, _ := path.Match(pattern, key)
+		return ok
+	}, nil
+}
+
+// KeysMatching returns keys matching the pattern, see CompileKeyPattern
+func (kv *keyValues) KeysMatching(pattern string) ([]string, error) {
+	match, err := CompileKeyPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := kv.refreshLogRecords(); err != nil {
+		return nil, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	keys := make([]string, 0)
+	for key := range kv.keys {
+		if match(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,51 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"golang.org/x/exp/slices"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_KeysMatching(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	keys := []string{"order-2023-1", "order-2023-22", "order-2024-1", "invoice-2023-1"}
+	for _, key := range keys {
+		testo.Error(t, kv.Set(key, strings.NewReader(key)), false)
+	}
+
+	tests := []struct {
+		pattern string
+		exp     []string
+		expErr  bool
+	}{
+		{"order-2023-*", []string{"order-2023-1", "order-2023-22"}, false},
+		{"*-2023-?", []string{"order-2023-1", "invoice-2023-1"}, false},
+		{"order-202[34]-1", []string{"order-2023-1", "order-2024-1"}, false},
+		{"order-[", nil, true},
+		{"re:^order-2023-[0-9]{2}$", []string{"order-2023-22"}, false},
+		{"re:2023", []string{"order-2023-1", "order-2023-22", "invoice-2023-1"}, false},
+		{"re:(", nil, true},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			matching, err := kv.KeysMatching(tt.pattern)
+			testo.Error(t, err, tt.expErr)
+			slices.Sort(matching)
+			slices.Sort(tt.exp)
+			testo.DeepEqual(t, matching, tt.exp)
+		})
+	}
+
+	_, err = kv.CutMany(keys...)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
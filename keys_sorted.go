@@ -0,0 +1,72 @@
+package kevlar
+
+import (
+	"cmp"
+	"slices"
+)
+
+// SortField is the log records timestamp KeysSorted sorts keys by
+type SortField int
+
+const (
+	// Created sorts keys by the time they've been created
+	Created SortField = iota
+	// Modified sorts keys by the time they've been last created or updated
+	Modified
+)
+
+// KeysSorted returns keys sorted by the log records timestamp. Keys with the
+// same timestamp are sorted by key, so the order is deterministic
+func (kv *keyValues) KeysSorted(by SortField, desc bool) ([]string, error) {
+	tss, err := kv.keyTimestamps(by)
+	if err != nil {
+		return nil, err
+	}
+	return sortKeysByTimestamps(tss, desc), nil
+}
+
+// keyTimestamps returns the sort field timestamp of every key
+func (kv *keyValues) keyTimestamps(by SortField) (map[string]int64, error) {
+	if err := kv.refreshLogRecords(); err != nil {
+		return nil, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	tss := make(map[string]int64, len(kv.keys))
+	for _, lr := range kv.log {
+		switch lr.Mt {
+		case create:
+			tss[lr.Id] = lr.Ts
+		case update:
+			if by == Modified {
+				tss[lr.Id] = max(tss[lr.Id], lr.Ts)
+			}
+		case cut:
+			delete(tss, lr.Id)
+		}
+	}
+
+	return tss, nil
+}
+
+func sortKeysByTimestamps(tss map[string]int64, desc bool) []string {
+	keys := make([]string, 0, len(tss))
+	for key := range tss {
+		keys = append(keys, key)
+	}
+
+	slices.SortFunc(keys, func(a, b string) int {
+		c := cmp.Compare(tss[a], tss[b])
+		if desc {
+			c = -c
+		}
+		if c == 0 {
+			c = cmp.Compare(a, b)
+		}
+		return c
+	})
+
+	return keys
+}
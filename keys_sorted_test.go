@@ -0,0 +1,39 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"strconv"
+	"testing"
+)
+
+func TestKeyValues_KeysSorted(t *testing.T) {
+	kv := mockKeyValues()
+	// 2 is created before, but modified after 3
+	kv.log = append(kv.log, &logRecord{Ts: 6, Mt: update, Id: "2"})
+
+	tests := []struct {
+		by   SortField
+		desc bool
+		exp  []string
+	}{
+		{Created, false, []string{"2", "3"}},
+		{Created, true, []string{"3", "2"}},
+		{Modified, false, []string{"3", "2"}},
+		{Modified, true, []string{"2", "3"}},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			sorted, err := kv.KeysSorted(tt.by, tt.desc)
+			testo.Error(t, err, false)
+			testo.DeepEqual(t, sorted, tt.exp)
+		})
+	}
+}
+
+func TestSortKeysByTimestamps(t *testing.T) {
+	tss := map[string]int64{"c": 1, "a": 2, "b": 2, "d": 3}
+
+	testo.DeepEqual(t, sortKeysByTimestamps(tss, false), []string{"c", "a", "b", "d"})
+	testo.DeepEqual(t, sortKeysByTimestamps(tss, true), []string{"d", "a", "b", "c"})
+}
@@ -0,0 +1,99 @@
+package kevlar
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// linkDuplicate hard-links the value of another key with the same hash
+// into place. Stored values are always replaced with a rename, so linked
+// keys keep their content when one of them is set again or cut
+func (kv *keyValues) linkDuplicate(key, hash string) (bool, error) {
+	src, err := kv.keyWithHash(hash)
+	if err != nil {
+		return false, err
+	}
+	if src == "" || src == key {
+		return false, nil
+	}
+
+	absValueFilename := kv.absValueFilename(key)
+	dir, filename := filepath.Split(absValueFilename)
+
+	stagedFilename := filepath.Join(dir, "."+filename+"-link")
+	if err := os.Link(kv.absValueFilename(src), stagedFilename); err != nil {
+		// not every filesystem supports hard links, value will be written instead
+		return false, nil
+	}
+
+	if err := os.Rename(stagedFilename, absValueFilename); err != nil {
+		return false, errors.Join(err, os.Remove(stagedFilename))
+	}
+
+	return true, nil
+}
+
+// keyWithHash returns a key that currently has a value with the provided
+// hash. Hashes are loaded from hash files on first use and are validated
+// before being returned, since they might have been changed externally
+func (kv *keyValues) keyWithHash(hash string) (string, error) {
+	kv.mtx.Lock()
+	loaded := kv.hashKeys != nil
+	kv.mtx.Unlock()
+
+	if !loaded {
+		if err := kv.loadHashKeys(); err != nil {
+			return "", err
+		}
+	}
+
+	kv.mtx.Lock()
+	key, ok := kv.hashKeys[hash]
+	kv.mtx.Unlock()
+
+	if !ok {
+		return "", nil
+	}
+
+	if currentHash, err := kv.currentHash(key); err != nil {
+		return "", err
+	} else if currentHash != hash {
+		return "", nil
+	}
+
+	return key, nil
+}
+
+func (kv *keyValues) loadHashKeys() error {
+	keys, err := kv.Keys()
+	if err != nil {
+		return err
+	}
+
+	hashKeys := make(map[string]string, len(keys))
+	for _, key := range keys {
+		hash, err := readSidecarFile(kv.absHashFilename(key))
+		if err != nil {
+			return err
+		}
+		if hash != "" {
+			hashKeys[hash] = key
+		}
+	}
+
+	kv.mtx.Lock()
+	kv.hashKeys = hashKeys
+	kv.mtx.Unlock()
+
+	return nil
+}
+
+func (kv *keyValues) setHashKey(hash, key string) {
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	if kv.hashKeys != nil {
+		kv.hashKeys[hash] = key
+	}
+}
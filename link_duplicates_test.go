@@ -0,0 +1,74 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_WithLinkDuplicates(t *testing.T) {
+	ikv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt, WithLinkDuplicates())
+	testo.Error(t, err, false)
+
+	kv, ok := ikv.(*keyValues)
+	testo.EqualValues(t, ok, true)
+
+	testo.Error(t, kv.Set("original", strings.NewReader("same")), false)
+	testo.Error(t, kv.Set("duplicate", strings.NewReader("same")), false)
+
+	ofi, err := os.Stat(kv.absValueFilename("original"))
+	testo.Error(t, err, false)
+	dfi, err := os.Stat(kv.absValueFilename("duplicate"))
+	testo.Error(t, err, false)
+	testo.EqualValues(t, os.SameFile(ofi, dfi), true)
+
+	// setting new value should not affect linked value
+	testo.Error(t, kv.Set("original", strings.NewReader("different")), false)
+
+	bts, err := os.ReadFile(kv.absValueFilename("duplicate"))
+	testo.Error(t, err, false)
+	testo.EqualValues(t, string(bts), "same")
+
+	for _, key := range []string{"original", "duplicate"} {
+		ok, err := kv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, ikv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_LinkDuplicateRenameError(t *testing.T) {
+	ikv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt, WithLinkDuplicates())
+	testo.Error(t, err, false)
+
+	kv, ok := ikv.(*keyValues)
+	testo.EqualValues(t, ok, true)
+
+	testo.Error(t, kv.Set("original", strings.NewReader("same")), false)
+
+	// value can't be renamed over a directory that isn't empty
+	absBlockedDir := kv.absValueFilename("blocked")
+	testo.Error(t, os.MkdirAll(filepath.Join(absBlockedDir, "file"), 0755), false)
+
+	linked, err := kv.linkDuplicate("blocked", sha256Hex([]byte("same")))
+	testo.EqualValues(t, linked, false)
+	var linkErr *os.LinkError
+	testo.EqualValues(t, errors.As(err, &linkErr), true)
+
+	dir, filename := filepath.Split(absBlockedDir)
+	_, err = os.Stat(filepath.Join(dir, "."+filename+"-link"))
+	testo.EqualValues(t, os.IsNotExist(err), true)
+
+	ok, err = kv.Cut("original")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, ikv.Close(), false)
+	testo.Error(t, os.RemoveAll(absBlockedDir), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
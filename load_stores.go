@@ -0,0 +1,177 @@
+package kevlar
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// StoresConfig declares key values and reductions, see LoadStores
+type StoresConfig struct {
+	KeyValues map[string]KeyValuesConfig `json:"keyValues"`
+	Redux     map[string]ReduxConfig     `json:"redux"`
+}
+
+// KeyValuesConfig declares key values storage directory, extension and
+// options. Durations are formatted as time.ParseDuration strings (e.g. "5s")
+type KeyValuesConfig struct {
+	Dir            string            `json:"dir"`
+	Ext            string            `json:"ext"`
+	StagingDir     string            `json:"stagingDir,omitempty"`
+	FastHash       bool              `json:"fastHash,omitempty"`
+	LinkDuplicates bool              `json:"linkDuplicates,omitempty"`
+	BinaryLog      bool              `json:"binaryLog,omitempty"`
+	Strict         bool              `json:"strict,omitempty"`
+	KeyPattern     string            `json:"keyPattern,omitempty"`
+	MaxKeyLength   int               `json:"maxKeyLength,omitempty"`
+	MaxKeys        int               `json:"maxKeys,omitempty"`
+	FlushEvery     int               `json:"flushEvery,omitempty"`
+	FlushInterval  string            `json:"flushInterval,omitempty"`
+	Timeout        string            `json:"timeout,omitempty"`
+	RetryAttempts  int               `json:"retryAttempts,omitempty"`
+	RetryBackoff   string            `json:"retryBackoff,omitempty"`
+	SlowOpLog      string            `json:"slowOpLog,omitempty"`
+	HtmlSniffing   bool              `json:"htmlSniffing,omitempty"`
+	ConflictPolicy string            `json:"conflictPolicy,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+}
+
+// ReduxConfig declares reduction directory and assets
+type ReduxConfig struct {
+	Dir    string   `json:"dir"`
+	Assets []string `json:"assets"`
+}
+
+// LoadStores connects key values and reduction writers declared in the JSON
+// config file (see StoresConfig), so that storage layout can be changed
+// without recompiling the application. Relative directories are resolved
+// relative to the config file directory. Unknown config fields are rejected,
+// so that misspelled or unsupported options are not silently ignored
+func LoadStores(configPath string) (map[string]KeyValues, map[string]WriteableRedux, error) {
+	configFile, err := os.Open(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer configFile.Close()
+
+	var config StoresConfig
+	decoder := json.NewDecoder(configFile)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, nil, fmt.Errorf("kevlar: config %s: %w", configPath, err)
+	}
+
+	configDir := filepath.Dir(configPath)
+
+	stores := make(map[string]KeyValues, len(config.KeyValues))
+	for name, kvc := range config.KeyValues {
+		options, err := kvc.options(configDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("kevlar: config %s: %w", name, err)
+		}
+		if stores[name], err = NewKeyValues(resolveDir(configDir, kvc.Dir), kvc.Ext, options...); err != nil {
+			return nil, nil, fmt.Errorf("kevlar: config %s: %w", name, err)
+		}
+	}
+
+	reductions := make(map[string]WriteableRedux, len(config.Redux))
+	for name, rc := range config.Redux {
+		if reductions[name], err = NewReduxWriter(resolveDir(configDir, rc.Dir), rc.Assets...); err != nil {
+			return nil, nil, fmt.Errorf("kevlar: config %s: %w", name, err)
+		}
+	}
+
+	return stores, reductions, nil
+}
+
+func resolveDir(configDir, dir string) string {
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(configDir, dir)
+}
+
+func (kvc KeyValuesConfig) options(configDir string) ([]KeyValuesOption, error) {
+	var options []KeyValuesOption
+
+	if kvc.StagingDir != "" {
+		options = append(options, WithStagingDir(resolveDir(configDir, kvc.StagingDir)))
+	}
+	if kvc.FastHash {
+		options = append(options, WithFastHash())
+	}
+	if kvc.LinkDuplicates {
+		options = append(options, WithLinkDuplicates())
+	}
+	if kvc.BinaryLog {
+		options = append(options, WithBinaryLog())
+	}
+	if kvc.Strict {
+		options = append(options, WithStrict())
+	}
+	if kvc.KeyPattern != "" {
+		pattern, err := regexp.Compile(kvc.KeyPattern)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, WithKeyPattern(pattern))
+	}
+	if kvc.MaxKeyLength > 0 {
+		options = append(options, WithMaxKeyLength(kvc.MaxKeyLength))
+	}
+	if kvc.MaxKeys > 0 {
+		options = append(options, WithMaxKeys(kvc.MaxKeys))
+	}
+	if kvc.FlushEvery > 0 {
+		options = append(options, WithFlushEvery(kvc.FlushEvery))
+	}
+	if kvc.FlushInterval != "" {
+		interval, err := time.ParseDuration(kvc.FlushInterval)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, WithFlushInterval(interval))
+	}
+	if kvc.Timeout != "" {
+		timeout, err := time.ParseDuration(kvc.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, WithTimeout(timeout))
+	}
+	if kvc.RetryAttempts > 0 {
+		var backoff time.Duration
+		if kvc.RetryBackoff != "" {
+			var err error
+			if backoff, err = time.ParseDuration(kvc.RetryBackoff); err != nil {
+				return nil, err
+			}
+		}
+		options = append(options, WithRetry(kvc.RetryAttempts, backoff))
+	}
+	if kvc.SlowOpLog != "" {
+		threshold, err := time.ParseDuration(kvc.SlowOpLog)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, WithSlowOpLog(threshold, nil))
+	}
+	if kvc.HtmlSniffing {
+		options = append(options, WithHtmlSniffing())
+	}
+	if kvc.ConflictPolicy != "" {
+		policy, err := ParseConflictPolicy(kvc.ConflictPolicy)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, WithConflictPolicy(policy))
+	}
+	if len(kvc.Labels) > 0 {
+		options = append(options, WithLabels(kvc.Labels))
+	}
+
+	return options, nil
+}
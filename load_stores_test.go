@@ -0,0 +1,48 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadStores(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname, "load-stores")
+	testo.Error(t, os.MkdirAll(dir, 0755), false)
+
+	configPath := filepath.Join(dir, "stores.json")
+	testo.Error(t, os.WriteFile(configPath, []byte(`{
+		"keyValues": {
+			"pages": {"dir": "pages", "ext": ".html", "fastHash": true, "flushInterval": "5s"}
+		},
+		"redux": {
+			"metadata": {"dir": "metadata", "assets": ["title"]}
+		}
+	}`), 0644), false)
+
+	stores, reductions, err := LoadStores(configPath)
+	testo.Error(t, err, false)
+
+	kv, ok := stores["pages"].(*keyValues)
+	testo.EqualValues(t, ok, true)
+	testo.EqualValues(t, kv.dir, filepath.Join(dir, "pages"))
+	testo.EqualValues(t, kv.ext, HtmlExt)
+	testo.EqualValues(t, kv.fastHash, true)
+	testo.EqualValues(t, kv.flushInterval, 5*time.Second)
+
+	testo.EqualValues(t, reductions["metadata"].HasAsset("title"), true)
+
+	testo.Error(t, kv.Close(), false)
+
+	// unsupported options are rejected
+	testo.Error(t, os.WriteFile(configPath, []byte(`{
+		"keyValues": {"pages": {"dir": "pages", "ext": ".html", "compression": "gzip"}}
+	}`), 0644), false)
+
+	_, _, err = LoadStores(configPath)
+	testo.Error(t, err, true)
+
+	testo.Error(t, os.RemoveAll(dir), false)
+}
@@ -1,9 +1,89 @@
 package kevlar
 
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrLogRecordsCorrupted = errors.New("kevlar: log records are corrupted")
+
 type logRecord struct {
 	Ts int64
 	Mt mutationType
 	Id string
+	// Sz is the size of the value for create and update records.
+	// Records written before sizes were tracked have zero size
+	Sz int64
 }
 
 type logRecords []*logRecord
+
+// intern makes log records with the same key share a single string,
+// since decoding creates a separate copy for every record
+func (lrs logRecords) intern() {
+	ids := make(map[string]string, len(lrs))
+	for _, lr := range lrs {
+		if id, ok := ids[lr.Id]; ok {
+			lr.Id = id
+		} else {
+			ids[lr.Id] = lr.Id
+		}
+	}
+}
+
+// validate makes sure decoded log records can be used, since corrupted
+// (or hostile) log records might contain unknown mutation types
+func (lrs logRecords) validate() error {
+	for ii, lr := range lrs {
+		if lr == nil {
+			return fmt.Errorf("%w: record %d is missing", ErrLogRecordsCorrupted, ii)
+		}
+		switch lr.Mt {
+		case create, update, cut:
+		default:
+			return fmt.Errorf("%w: record %d has unknown mutation type %d", ErrLogRecordsCorrupted, ii, lr.Mt)
+		}
+	}
+	return nil
+}
+
+// decodeLogRecords decodes gob or binary log records and validates them.
+// Empty input is decoded as empty log records
+func decodeLogRecords(r io.Reader, binaryLog bool) (logRecords, error) {
+	var lrs logRecords
+	if binaryLog {
+		var err error
+		if lrs, err = decodeBinaryLogRecords(r); err != nil {
+			return nil, err
+		}
+	} else if err := gob.NewDecoder(r).Decode(&lrs); err != nil && err != io.EOF {
+		// io.EOF - empty log will be initialized later
+		return nil, err
+	}
+
+	if err := lrs.validate(); err != nil {
+		return nil, err
+	}
+
+	return lrs, nil
+}
+
+// keys returns keys that have been created or updated and not cut since
+func (lrs logRecords) keys() map[string]any {
+	uks := make(map[string]any)
+	for _, lr := range lrs {
+		switch lr.Mt {
+		case create:
+			fallthrough
+		case update:
+			uks[lr.Id] = nil
+		case cut:
+			delete(uks, lr.Id)
+		default:
+			panic("unknown log record mutation type")
+		}
+	}
+	return uks
+}
@@ -0,0 +1,75 @@
+package kevlar
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"github.com/boggydigital/testo"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestLogRecords_Intern(t *testing.T) {
+	lrs := logRecords{
+		{Ts: 1, Mt: create, Id: strings.Clone("1")},
+		{Ts: 2, Mt: update, Id: strings.Clone("1")},
+		{Ts: 3, Mt: create, Id: strings.Clone("2")},
+	}
+
+	testo.EqualValues(t, unsafe.StringData(lrs[0].Id) == unsafe.StringData(lrs[1].Id), false)
+
+	lrs.intern()
+
+	testo.EqualValues(t, lrs[0].Id, "1")
+	testo.EqualValues(t, lrs[1].Id, "1")
+	testo.EqualValues(t, lrs[2].Id, "2")
+	testo.EqualValues(t, unsafe.StringData(lrs[0].Id) == unsafe.StringData(lrs[1].Id), true)
+}
+
+func TestDecodeLogRecords_UnknownMutationType(t *testing.T) {
+	buf := new(bytes.Buffer)
+	testo.Error(t, gob.NewEncoder(buf).Encode(logRecords{{Ts: 1, Mt: 7, Id: "1"}}), false)
+
+	_, err := decodeLogRecords(buf, false)
+	testo.EqualValues(t, errors.Is(err, ErrLogRecordsCorrupted), true)
+}
+
+func FuzzDecodeLogRecords(f *testing.F) {
+	lrs := mockLogRecords(10)
+
+	gobBuf := new(bytes.Buffer)
+	if err := gob.NewEncoder(gobBuf).Encode(lrs); err != nil {
+		f.Fatal(err)
+	}
+	binBuf := new(bytes.Buffer)
+	if err := encodeBinaryLogRecords(binBuf, lrs); err != nil {
+		f.Fatal(err)
+	}
+
+	f.Add(gobBuf.Bytes())
+	f.Add(binBuf.Bytes())
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, binaryLog := range []bool{false, true} {
+			lrs, err := decodeLogRecords(bytes.NewReader(data), binaryLog)
+			if err != nil {
+				continue
+			}
+			// decoded log records must be usable without panics
+			lrs.intern()
+			lrs.keys()
+
+			if binaryLog {
+				buf := new(bytes.Buffer)
+				if err := encodeBinaryLogRecords(buf, lrs); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := decodeLogRecords(buf, binaryLog); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package kevlar
+
+import (
+	"hash/crc64"
+	"io"
+	"os"
+	"time"
+)
+
+// logModTimeResolution is the coarsest modification time resolution
+// expected from file systems (e.g. FAT). Log records modified within that
+// time from the last check might have been modified again without changing
+// modification time and size
+const logModTimeResolution = 2 * time.Second
+
+// logState identifies the version of log records that has been loaded
+// or written, to detect external changes
+type logState struct {
+	// modTime is the log records file modification time in nanoseconds,
+	// -1 when log records file doesn't exist
+	modTime int64
+	size    int64
+	// sum is CRC-64 checksum of log records file content
+	sum uint64
+	// checked is the time (in nanoseconds) log records file was last
+	// known to have this state
+	checked int64
+}
+
+// statLogRecords returns the state of the log records file without
+// a checksum, which is only computed when needed (see racy)
+func (kv *keyValues) statLogRecords() logState {
+	ls := logState{modTime: -1, checked: time.Now().UnixNano()}
+	if fi, err := os.Stat(kv.absLogRecordsFilename()); err == nil {
+		ls.modTime = fi.ModTime().UnixNano()
+		ls.size = fi.Size()
+	}
+	return ls
+}
+
+// sameFile reports whether log records file modification time
+// and size haven't changed
+func (ls logState) sameFile(other logState) bool {
+	return ls.modTime == other.modTime && ls.size == other.size
+}
+
+// racy reports whether log records file has been last checked within
+// modification time resolution from its modification time. Another write
+// within that time might keep both modification time and size unchanged,
+// so content needs to be compared
+func (ls logState) racy() bool {
+	return ls.modTime >= 0 &&
+		ls.checked <= ls.modTime+logModTimeResolution.Nanoseconds()
+}
+
+// logRecordsChanged reports whether log records file has been changed
+// since log records have been loaded or written. Expects storage to be locked
+func (kv *keyValues) logRecordsChanged(ls logState) (bool, error) {
+	if !kv.logState.sameFile(ls) {
+		return true, nil
+	}
+	if !kv.logState.racy() {
+		return false, nil
+	}
+
+	sum, err := kv.checksumLogRecords()
+	if os.IsNotExist(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	if sum != kv.logState.sum {
+		return true, nil
+	}
+
+	// content is known to be the same at the time of the stat
+	kv.logState.checked = ls.checked
+	return false, nil
+}
+
+func (kv *keyValues) checksumLogRecords() (uint64, error) {
+	logFile, err := os.Open(kv.absLogRecordsFilename())
+	if err != nil {
+		return 0, err
+	}
+	defer logFile.Close()
+
+	h := crc64.New(crc64Table)
+	if _, err := io.Copy(h, logFile); err != nil {
+		return 0, err
+	}
+	return h.Sum64(), nil
+}
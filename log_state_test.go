@@ -0,0 +1,64 @@
+package kevlar
+
+import (
+	"bytes"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_RefreshSameModTime(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("k1", strings.NewReader("value")), false)
+
+	current, _ := kv.IsCurrent()
+	testo.EqualValues(t, current, true)
+
+	// external write that keeps log records file modification time and size
+	absLogRecordsFilename := kv.(*keyValues).absLogRecordsFilename()
+	fi, err := os.Stat(absLogRecordsFilename)
+	testo.Error(t, err, false)
+
+	data, err := os.ReadFile(absLogRecordsFilename)
+	testo.Error(t, err, false)
+	data = bytes.ReplaceAll(data, []byte("k1"), []byte("k2"))
+	testo.Error(t, os.WriteFile(absLogRecordsFilename, data, 0644), false)
+	testo.Error(t, os.Chtimes(absLogRecordsFilename, fi.ModTime(), fi.ModTime()), false)
+
+	current, _ = kv.IsCurrent()
+	testo.EqualValues(t, current, false)
+
+	ok, err := kv.Has("k2")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, ok, true)
+
+	ok, err = kv.Has("k1")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, ok, false)
+
+	current, _ = kv.IsCurrent()
+	testo.EqualValues(t, current, true)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestLogState_Racy(t *testing.T) {
+	tests := []struct {
+		ls   logState
+		racy bool
+	}{
+		{logState{modTime: -1}, false},
+		{logState{modTime: 1, checked: 1}, true},
+		{logState{modTime: 1, checked: 1 + logModTimeResolution.Nanoseconds()}, true},
+		{logState{modTime: 1, checked: 2 + logModTimeResolution.Nanoseconds()}, false},
+	}
+
+	for _, tt := range tests {
+		testo.EqualValues(t, tt.ls.racy(), tt.racy)
+	}
+}
@@ -0,0 +1,138 @@
+package kevlar
+
+import (
+	"errors"
+	"time"
+)
+
+// Maintenance configures background maintenance of the key values,
+// see WithMaintenance
+type Maintenance struct {
+	// Interval between checks whether maintenance should run
+	Interval time.Duration
+	// Idle is the time without Get, Set and Cut operations after which
+	// maintenance runs. Maintenance runs once per idle period
+	Idle time.Duration
+	// Retention rules applied with ApplyRetention
+	Retention []RetentionRule
+	// PruneExpired cuts expired values with PruneExpired
+	PruneExpired bool
+	// VetContent quarantines values with content that doesn't
+	// match storage extension with VetContent
+	VetContent bool
+	// ReduxAssets are reduction assets stored in these key values
+	// that are compacted with CompactAll
+	ReduxAssets []string
+}
+
+// maintainer runs maintenance in a background goroutine until stopped
+type maintainer struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+func (kv *keyValues) startMaintenance() {
+	kv.maintainer = &maintainer{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go kv.maintain(kv.maintainer)
+}
+
+// stopMaintenance stops background maintenance and waits
+// for the maintenance in progress to complete
+func (kv *keyValues) stopMaintenance() {
+	if kv.maintainer == nil {
+		return
+	}
+
+	close(kv.maintainer.stop)
+	<-kv.maintainer.done
+	kv.maintainer = nil
+}
+
+func (kv *keyValues) maintain(m *maintainer) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(kv.maintenance.Interval)
+	defer ticker.Stop()
+
+	lastActivity := kv.activity()
+	lastChange := time.Now()
+	maintained := false
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			if activity := kv.activity(); activity != lastActivity {
+				lastActivity = activity
+				lastChange = now
+				maintained = false
+				continue
+			}
+			if maintained || now.Sub(lastChange) < kv.maintenance.Idle {
+				continue
+			}
+
+			err := kv.runMaintenance()
+
+			kv.stats.maintenanceRuns.Add(1)
+			kv.stats.lastMaintenance.Store(time.Now().Unix())
+			if err != nil {
+				kv.stats.maintenanceErrors.Add(1)
+			}
+
+			// own operations are not activity
+			lastActivity = kv.activity()
+			maintained = true
+		}
+	}
+}
+
+// activity returns the number of Get, Set and Cut operations so far
+func (kv *keyValues) activity() int64 {
+	return kv.stats.gets.Load() + kv.stats.sets.Load() + kv.stats.cuts.Load()
+}
+
+// runMaintenance runs every configured maintenance task, even when some
+// of them fail. Maintenance is skipped while storage is frozen
+func (kv *keyValues) runMaintenance() error {
+	var errs []error
+
+	if len(kv.maintenance.Retention) > 0 {
+		if _, err := kv.ApplyRetention(kv.maintenance.Retention...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if kv.maintenance.PruneExpired {
+		if _, err := kv.PruneExpired(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if kv.maintenance.VetContent {
+		if _, err := kv.VetContent(true); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(kv.maintenance.ReduxAssets) > 0 {
+		// reductions are not goroutine safe, so maintenance
+		// compacts its own reduction of the assets
+		if rdx, err := newReduxWith(kv, kv.dir, kv.maintenance.ReduxAssets...); err != nil {
+			errs = append(errs, err)
+		} else if _, err := rdx.CompactAll(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	err := errors.Join(errs...)
+	if errors.Is(err, ErrFrozen) {
+		return nil
+	}
+	return err
+}
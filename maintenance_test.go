@@ -0,0 +1,57 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyValues_WithMaintenance(t *testing.T) {
+	ikv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt,
+		WithMaintenance(Maintenance{
+			Interval:     10 * time.Millisecond,
+			Idle:         20 * time.Millisecond,
+			PruneExpired: true,
+			ReduxAssets:  []string{"a1"},
+		}))
+	testo.Error(t, err, false)
+
+	kv, ok := ikv.(*keyValues)
+	testo.EqualValues(t, ok, true)
+
+	testo.Error(t, kv.SetWithExpiry("expired", strings.NewReader("expired"), -time.Second), false)
+
+	rdx, err := NewReduxWriterWith(kv, "a1")
+	testo.Error(t, err, false)
+	testo.Error(t, rdx.AddValues("a1", "k1", ""), false)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for kv.Stats().MaintenanceRuns == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	testo.Error(t, kv.Close(), false)
+
+	stats := kv.Stats()
+	testo.EqualValues(t, stats.MaintenanceRuns, int64(1))
+	testo.EqualValues(t, stats.MaintenanceErrors, int64(0))
+	testo.EqualValues(t, stats.LastMaintenance > 0, true)
+
+	has, err := kv.Has("expired")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, has, false)
+
+	rdx, err = NewReduxWriterWith(kv, "a1")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, rdx.HasKey("a1", "k1"), false)
+
+	ok, err = kv.Cut("a1")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, os.Remove(kv.absTimestampsFilename(expiresFilename)), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
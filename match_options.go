@@ -1,8 +1,12 @@
 package kevlar
 
+// MatchOption changes how Match and MatchAsset compare terms with values.
+// By default terms match values that contain them, ignoring case
 type MatchOption int
 
 const (
-	CaseSensitive = iota
+	// CaseSensitive terms only match values with the same case
+	CaseSensitive MatchOption = iota
+	// FullMatch terms only match values equal to them
 	FullMatch
 )
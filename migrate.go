@@ -19,7 +19,7 @@ import (
 // 4) hash files are created for each index record with hash
 // 5) log is written as a single operation (vs kv.appendLogRecord calls)
 // 6) old index is removed to make sure calling migrate again doesn't overwrite new data
-func Migrate(dir string) error {
+func Migrate(dir string) (err error) {
 
 	// 1)
 
@@ -54,6 +54,7 @@ func Migrate(dir string) error {
 	if err != nil {
 		return err
 	}
+	defer func() { err = errors.Join(err, ikv.Close()) }()
 
 	kv, ok := ikv.(*keyValues)
 	if !ok {
@@ -91,9 +92,10 @@ func Migrate(dir string) error {
 	if err != nil {
 		return err
 	}
-	defer logRecordsFile.Close()
-
-	if err = gob.NewEncoder(logRecordsFile).Encode(kv.log); err != nil {
+	err = gob.NewEncoder(logRecordsFile).Encode(kv.log)
+	// close errors might mean the log wasn't written completely and
+	// index must not be removed in that case
+	if err = errors.Join(err, logRecordsFile.Close()); err != nil {
 		return err
 	}
 
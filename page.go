@@ -0,0 +1,84 @@
+package kevlar
+
+import "slices"
+
+// Page returns keys of the zero-based page and the total number of pages.
+// Pages past the last one are empty. Keys are expected to be ordered
+// (e.g. with Sort or SortBy), otherwise pages won't be stable
+func Page(keys []string, page, pageSize int) ([]string, int) {
+	if pageSize <= 0 {
+		return nil, 0
+	}
+
+	pages := (len(keys) + pageSize - 1) / pageSize
+
+	if page < 0 || page >= pages {
+		return []string{}, pages
+	}
+
+	from := page * pageSize
+	to := min(from+pageSize, len(keys))
+
+	return keys[from:to], pages
+}
+
+// PageAfter returns up to pageSize keys that follow the cursor key and the
+// cursor for the next page, which is empty for the last page. Empty cursor
+// returns the first page. Unlike Page, cursors don't skip or repeat keys when
+// keys are added before the cursor between requests. When the cursor key is
+// not present in keys, no keys are returned
+func PageAfter(keys []string, cursor string, pageSize int) ([]string, string) {
+	if pageSize <= 0 {
+		return nil, ""
+	}
+
+	from := 0
+	if cursor != "" {
+		from = -1
+		for ii, key := range keys {
+			if key == cursor {
+				from = ii + 1
+				break
+			}
+		}
+		if from < 0 {
+			return []string{}, ""
+		}
+	}
+
+	to := min(from+pageSize, len(keys))
+	page := keys[from:to]
+
+	next := ""
+	if to < len(keys) {
+		next = keys[to-1]
+	}
+
+	return page, next
+}
+
+// KeysPage returns up to limit keys starting at offset, in ascending order,
+// so that pages are stable while keys don't change. Ordered keys are cached
+// until keys are added or cut, so that paging doesn't sort keys for every page
+func (kv *keyValues) KeysPage(offset, limit int) ([]string, error) {
+	if err := kv.refreshLogRecords(); err != nil {
+		return nil, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	if kv.sortedKeys == nil {
+		kv.sortedKeys = make([]string, 0, len(kv.keys))
+		for key := range kv.keys {
+			kv.sortedKeys = append(kv.sortedKeys, key)
+		}
+		slices.Sort(kv.sortedKeys)
+	}
+
+	if offset < 0 || limit <= 0 || offset >= len(kv.sortedKeys) {
+		return []string{}, nil
+	}
+
+	return slices.Clone(kv.sortedKeys[offset:min(offset+limit, len(kv.sortedKeys))]), nil
+}
@@ -0,0 +1,112 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPage(t *testing.T) {
+	keys := []string{"1", "2", "3", "4", "5"}
+
+	tests := []struct {
+		keys     []string
+		page     int
+		pageSize int
+		exp      []string
+		expPages int
+	}{
+		{keys, 0, 2, []string{"1", "2"}, 3},
+		{keys, 1, 2, []string{"3", "4"}, 3},
+		{keys, 2, 2, []string{"5"}, 3},
+		{keys, 3, 2, []string{}, 3},
+		{keys, -1, 2, []string{}, 3},
+		{keys, 0, 5, keys, 1},
+		{keys, 0, 10, keys, 1},
+		{keys, 0, 0, nil, 0},
+		{nil, 0, 2, []string{}, 0},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			page, pages := Page(tt.keys, tt.page, tt.pageSize)
+			testo.DeepEqual(t, page, tt.exp)
+			testo.EqualValues(t, pages, tt.expPages)
+		})
+	}
+}
+
+func TestPageAfter(t *testing.T) {
+	keys := []string{"1", "2", "3", "4", "5"}
+
+	tests := []struct {
+		cursor   string
+		pageSize int
+		exp      []string
+		expNext  string
+	}{
+		{"", 2, []string{"1", "2"}, "2"},
+		{"2", 2, []string{"3", "4"}, "4"},
+		{"4", 2, []string{"5"}, ""},
+		{"3", 2, []string{"4", "5"}, ""},
+		{"5", 2, []string{}, ""},
+		{"key-that-doesnt-exist", 2, []string{}, ""},
+		{"", 0, nil, ""},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			page, next := PageAfter(keys, tt.cursor, tt.pageSize)
+			testo.DeepEqual(t, page, tt.exp)
+			testo.EqualValues(t, next, tt.expNext)
+		})
+	}
+}
+
+func TestKeyValues_KeysPage(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	for _, key := range []string{"c", "a", "e", "b", "d"} {
+		testo.Error(t, kv.Set(key, strings.NewReader(key)), false)
+	}
+
+	tests := []struct {
+		offset, limit int
+		exp           []string
+	}{
+		{0, 2, []string{"a", "b"}},
+		{2, 2, []string{"c", "d"}},
+		{4, 2, []string{"e"}},
+		{5, 2, []string{}},
+		{0, 0, []string{}},
+		{-1, 2, []string{}},
+		{0, 10, []string{"a", "b", "c", "d", "e"}},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			page, err := kv.KeysPage(tt.offset, tt.limit)
+			testo.Error(t, err, false)
+			testo.DeepEqual(t, page, tt.exp)
+		})
+	}
+
+	// cached ordered keys are updated when keys are added and cut
+	testo.Error(t, kv.Set("0", strings.NewReader("0")), false)
+	ok, err := kv.Cut("e")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+	page, err := kv.KeysPage(0, 10)
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, page, []string{"0", "a", "b", "c", "d"})
+
+	_, err = kv.CutMany("0", "a", "b", "c", "d")
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,39 @@
+package kevlar
+
+import (
+	"golang.org/x/exp/slices"
+	"hash/fnv"
+)
+
+// KeyPartition returns the partition (from 0 to n-1) of the key. Partition
+// only depends on the key and the number of partitions, so it's the same
+// across runs and doesn't change when other keys are added or cut
+func KeyPartition(key string, n int) int {
+	if n <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int(h.Sum64() % uint64(n))
+}
+
+// PartitionKeys splits keys into n disjoint partitions (see KeyPartition),
+// e.g. to assign them to parallel workers. Keys are sorted within every
+// partition. Some partitions might be empty when there are few keys
+func PartitionKeys(keys []string, n int) [][]string {
+	if n <= 0 {
+		return nil
+	}
+
+	partitions := make([][]string, n)
+	for _, key := range keys {
+		p := KeyPartition(key, n)
+		partitions[p] = append(partitions[p], key)
+	}
+
+	for _, partition := range partitions {
+		slices.Sort(partition)
+	}
+
+	return partitions
+}
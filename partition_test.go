@@ -0,0 +1,57 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"golang.org/x/exp/slices"
+	"strconv"
+	"testing"
+)
+
+func TestPartitionKeys(t *testing.T) {
+	keys := make([]string, 0, 100)
+	for ii := 0; ii < 100; ii++ {
+		keys = append(keys, strconv.Itoa(ii))
+	}
+
+	tests := []struct {
+		keys []string
+		n    int
+	}{
+		{keys, 1},
+		{keys, 4},
+		{keys, 7},
+		{keys[:2], 8},
+		{nil, 3},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			partitions := PartitionKeys(tt.keys, tt.n)
+			testo.EqualValues(t, len(partitions), tt.n)
+
+			all := make([]string, 0, len(tt.keys))
+			for p, partition := range partitions {
+				testo.EqualValues(t, slices.IsSorted(partition), true)
+				for _, key := range partition {
+					testo.EqualValues(t, KeyPartition(key, tt.n), p)
+				}
+				all = append(all, partition...)
+			}
+
+			// partitions are disjoint and cover all keys
+			slices.Sort(all)
+			expected := append([]string{}, tt.keys...)
+			slices.Sort(expected)
+			testo.DeepEqual(t, all, expected)
+		})
+	}
+
+	testo.EqualValues(t, PartitionKeys(keys, 0) == nil, true)
+}
+
+func TestKeyPartition_Stable(t *testing.T) {
+	// partitions must not change between runs and releases
+	testo.EqualValues(t, KeyPartition("key", 16), 12)
+	testo.EqualValues(t, KeyPartition("key", 1), 0)
+	testo.EqualValues(t, KeyPartition("key", 0), 0)
+}
@@ -0,0 +1,51 @@
+package kevlar
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const pinnedFilename = "_pinned.gob"
+
+// Pin exempts the key from PruneExpired, ApplyRetention and Archive, e.g. for
+// critical values that must never be removed by policies. The key can still
+// be cut explicitly. Pins are kept until Unpin or Cut, same as expiration,
+// setting the value again doesn't change the pin
+func (kv *keyValues) Pin(key string) error {
+	if ok, err := kv.Has(key); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, key)
+	}
+	return kv.setTimestamp(pinnedFilename, key, time.Now().Unix())
+}
+
+// Unpin removes the pin, see Pin
+func (kv *keyValues) Unpin(key string) error {
+	return kv.setTimestamp(pinnedFilename, key, -1)
+}
+
+// PinnedKeys returns existing keys that have been pinned, e.g. for audits
+func (kv *keyValues) PinnedKeys() ([]string, error) {
+	return kv.keysBefore(pinnedFilename, math.MaxInt64)
+}
+
+// unpinned removes pinned keys
+func (kv *keyValues) unpinned(keys []string) ([]string, error) {
+	kv.mtx.Lock()
+	pinned, err := kv.loadTimestamps(pinnedFilename)
+	kv.mtx.Unlock()
+
+	if err != nil || len(pinned) == 0 {
+		return keys, err
+	}
+
+	unpinned := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := pinned[key]; !ok {
+			unpinned = append(unpinned, key)
+		}
+	}
+	return unpinned, nil
+}
@@ -0,0 +1,74 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyValues_Pin(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname, "pinned")
+	coldDir := filepath.Join(os.TempDir(), testsDirname, "cold")
+	for _, d := range []string{dir, coldDir} {
+		testo.Error(t, os.MkdirAll(d, 0755), false)
+	}
+
+	kv, err := NewKeyValues(dir, GobExt)
+	testo.Error(t, err, false)
+	cold, err := NewKeyValues(coldDir, GobExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Pin("key-that-doesnt-exist"), true)
+
+	for _, key := range []string{"critical", "expired", "retained", "archived"} {
+		testo.Error(t, kv.SetWithExpiry(key, strings.NewReader(key), -time.Hour), false)
+	}
+	testo.Error(t, kv.Pin("critical"), false)
+
+	pinned, err := kv.PinnedKeys()
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, pinned, []string{"critical"})
+
+	record, ok := kv.GetRecord("critical")
+	testo.EqualValues(t, ok, true)
+	_, ok = record.Meta[MetaPinned]
+	testo.EqualValues(t, ok, true)
+
+	// pinned keys are exempt from expiration, retention and archiving
+	cut, err := kv.PruneExpired()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(cut), 4-1)
+
+	testo.Error(t, kv.Set("retained", strings.NewReader("retained")), false)
+	cut, err = kv.ApplyRetention(RetentionRule{MaxAge: -time.Hour})
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, cut, []string{"retained"})
+
+	testo.Error(t, kv.Set("archived", strings.NewReader("archived")), false)
+	n, err := kv.Archive(func(Record) bool { return true }, cold)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, n, 1)
+
+	ok, err = kv.Has("critical")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, ok, true)
+
+	testo.Error(t, kv.Unpin("critical"), false)
+
+	pinned, err = kv.PinnedKeys()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(pinned), 0)
+
+	cut, err = kv.PruneExpired()
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, cut, []string{"critical"})
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, cold.Close(), false)
+	for _, d := range []string{dir, coldDir} {
+		testo.Error(t, os.RemoveAll(d), false)
+	}
+}
@@ -0,0 +1,46 @@
+package kevlar
+
+import (
+	"os"
+	"strings"
+)
+
+// PrefixStat summarizes values that share the same top-level key prefix
+type PrefixStat struct {
+	Count   int
+	Bytes   int64
+	ModTime int64
+}
+
+// PrefixStats groups keys by the top-level prefix (the part of the key before
+// the first delimiter) and reports count, total size and the newest modification
+// time per prefix. Keys that don't contain delimiter are grouped under empty prefix
+func (kv *keyValues) PrefixStats(delimiter string) (map[string]PrefixStat, error) {
+	lmts, err := kv.lastModTimes()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]PrefixStat)
+
+	for key, lmt := range lmts {
+		prefix := ""
+		if before, _, ok := strings.Cut(key, delimiter); ok {
+			prefix = before
+		}
+
+		ps := stats[prefix]
+		ps.Count++
+		if fi, err := os.Stat(kv.absValueFilename(key)); err == nil {
+			ps.Bytes += fi.Size()
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+		if lmt > ps.ModTime {
+			ps.ModTime = lmt
+		}
+		stats[prefix] = ps
+	}
+
+	return stats, nil
+}
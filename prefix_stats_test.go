@@ -0,0 +1,46 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_PrefixStats(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	keyValues := map[string]string{
+		"user/1/avatar": "1",
+		"user/2/avatar": "22",
+		"order/1":       "333",
+		"readme":        "4444",
+	}
+
+	for key, value := range keyValues {
+		testo.Error(t, kv.Set(key, strings.NewReader(value)), false)
+	}
+
+	stats, err := kv.PrefixStats("/")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(stats), 3)
+
+	testo.EqualValues(t, stats["user"].Count, 2)
+	testo.EqualValues(t, stats["user"].Bytes, int64(3))
+	testo.EqualValues(t, stats["order"].Count, 1)
+	testo.EqualValues(t, stats["order"].Bytes, int64(3))
+	testo.EqualValues(t, stats[""].Count, 1)
+	testo.EqualValues(t, stats[""].Bytes, int64(4))
+	testo.CompareInt64(t, stats["user"].ModTime, 0, testo.Greater)
+
+	for key := range keyValues {
+		ok, err := kv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
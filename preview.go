@@ -0,0 +1,35 @@
+package kevlar
+
+import (
+	"bytes"
+	"io"
+)
+
+// Preview returns up to the first n bytes of the value
+func Preview(kv KeyValues, key string, n int) ([]byte, error) {
+	rc, err := kv.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	buf := new(bytes.Buffer)
+	if _, err := io.CopyN(buf, rc, int64(n)); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Previews returns up to the first n bytes of every value
+func Previews(kv KeyValues, keys []string, n int) (map[string][]byte, error) {
+	previews := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		preview, err := Preview(kv, key, n)
+		if err != nil {
+			return nil, err
+		}
+		previews[key] = preview
+	}
+	return previews, nil
+}
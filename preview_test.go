@@ -0,0 +1,43 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_Preview(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), JsonExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("short", strings.NewReader("{}")), false)
+	testo.Error(t, kv.Set("long", strings.NewReader("{\"key\":\"value\"}")), false)
+
+	preview, err := Preview(kv, "long", 5)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, string(preview), "{\"key")
+
+	preview, err = Preview(kv, "short", 5)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, string(preview), "{}")
+
+	_, err = Preview(kv, "key-that-doesnt-exist", 5)
+	testo.Error(t, err, true)
+
+	previews, err := Previews(kv, []string{"short", "long"}, 1)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(previews), 2)
+	testo.EqualValues(t, string(previews["short"]), "{")
+	testo.EqualValues(t, string(previews["long"]), "{")
+
+	for _, key := range []string{"short", "long"} {
+		ok, err := kv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
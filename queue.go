@@ -0,0 +1,258 @@
+package kevlar
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	QueueStateAsset    = "queue-state"
+	QueueWorkerAsset   = "queue-worker"
+	QueueLeaseAsset    = "queue-lease"
+	QueueAttemptsAsset = "queue-attempts"
+)
+
+const (
+	queuePending   = "pending"
+	queueClaimed   = "claimed"
+	queueCompleted = "completed"
+)
+
+const queueLockFilename = "_queue.lock"
+
+var queueAssets = []string{
+	QueueStateAsset,
+	QueueWorkerAsset,
+	QueueLeaseAsset,
+	QueueAttemptsAsset,
+}
+
+var ErrNotClaimed = errors.New("kevlar: queue key is not claimed by worker")
+
+// Queue coordinates processing of keys between workers. Queue state is
+// persisted as reduction assets, so workers in different processes can
+// share the same directory. Queue operations are goroutine safe and take
+// a file lock in the directory, so that claims are exclusive across
+// processes. Queue state is refreshed from storage before every operation
+type Queue struct {
+	kv           KeyValues
+	rdx          WriteableRedux
+	mtx          *sync.Mutex
+	lockFilename string
+}
+
+func NewQueue(dir string) (*Queue, error) {
+	kv, err := NewKeyValues(dir, GobExt)
+	if err != nil {
+		return nil, err
+	}
+
+	rdx, err := NewReduxWriterWith(kv, queueAssets...)
+	if err != nil {
+		return nil, err
+	}
+
+	lockDir := filepath.Join(dir, kevlarDirname)
+	if err := os.MkdirAll(lockDir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &Queue{
+		kv:           kv,
+		rdx:          rdx,
+		mtx:          new(sync.Mutex),
+		lockFilename: filepath.Join(lockDir, queueLockFilename),
+	}, nil
+}
+
+// lock locks the queue for goroutines of this process and other processes
+// sharing the directory and refreshes the queue state. Returned function
+// unlocks the queue
+func (q *Queue) lock() (func(), error) {
+	q.mtx.Lock()
+
+	file, err := os.OpenFile(q.lockFilename, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		q.mtx.Unlock()
+		return nil, err
+	}
+
+	unlock := func() {
+		file.Close() // closing the file releases the lock
+		q.mtx.Unlock()
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		unlock()
+		return nil, err
+	}
+
+	if err := q.refresh(); err != nil {
+		unlock()
+		return nil, err
+	}
+
+	return unlock, nil
+}
+
+// refresh reloads the queue state. Reductions are refreshed when they've
+// been modified in a later second (see RefreshWriter), which would miss
+// claims made by other processes within the same second. Reductions are
+// reloaded from the same key values, that reload log records changed by
+// other processes
+func (q *Queue) refresh() error {
+	rdx, err := NewReduxWriterWith(q.kv, queueAssets...)
+	if err != nil {
+		return err
+	}
+	q.rdx = rdx
+	return nil
+}
+
+// Enqueue adds keys to the queue as pending. Keys that are already in
+// the queue (including completed ones) become pending again
+func (q *Queue) Enqueue(keys ...string) error {
+	unlock, err := q.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	states := make(map[string][]string, len(keys))
+	for _, key := range keys {
+		states[key] = []string{queuePending}
+	}
+
+	if err := q.rdx.BatchReplaceValues(QueueStateAsset, states); err != nil {
+		return err
+	}
+
+	return q.release(keys...)
+}
+
+// Claim assigns the next pending key (or a claimed key with an expired
+// lease) to the worker for the lease duration. When there are no keys
+// to claim, Claim returns false. Claim state is written last, so claims
+// that haven't been written completely (e.g. the process has crashed)
+// leave the key pending
+func (q *Queue) Claim(workerId string, lease time.Duration) (string, bool, error) {
+	unlock, err := q.lock()
+	if err != nil {
+		return "", false, err
+	}
+	defer unlock()
+
+	now := time.Now()
+
+	keys := q.rdx.Keys(QueueStateAsset)
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		state, _ := q.rdx.GetLastVal(QueueStateAsset, key)
+		switch state {
+		case queuePending:
+			// claim pending key
+		case queueClaimed:
+			if !q.leaseExpired(key, now) {
+				continue
+			}
+		default:
+			continue
+		}
+
+		attempts := q.attempts(key) + 1
+
+		if err := q.rdx.ReplaceValues(QueueWorkerAsset, key, workerId); err != nil {
+			return "", false, err
+		}
+		expires := strconv.FormatInt(now.Add(lease).Unix(), 10)
+		if err := q.rdx.ReplaceValues(QueueLeaseAsset, key, expires); err != nil {
+			return "", false, err
+		}
+		if err := q.rdx.ReplaceValues(QueueAttemptsAsset, key, strconv.Itoa(attempts)); err != nil {
+			return "", false, err
+		}
+		if err := q.rdx.ReplaceValues(QueueStateAsset, key, queueClaimed); err != nil {
+			return "", false, err
+		}
+
+		return key, true, nil
+	}
+
+	return "", false, nil
+}
+
+// Complete marks the key claimed by the worker as completed
+func (q *Queue) Complete(key, workerId string) error {
+	return q.finish(key, workerId, queueCompleted)
+}
+
+// Fail returns the key claimed by the worker to pending, so that it can be
+// claimed again. Attempts can be used to stop retrying failing keys
+func (q *Queue) Fail(key, workerId string) error {
+	return q.finish(key, workerId, queuePending)
+}
+
+// Attempts returns the number of times the key has been claimed
+func (q *Queue) Attempts(key string) int {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	return q.attempts(key)
+}
+
+// attempts returns the number of times the key has been claimed,
+// expects the queue to be locked
+func (q *Queue) attempts(key string) int {
+	if val, ok := q.rdx.GetLastVal(QueueAttemptsAsset, key); ok {
+		if attempts, err := strconv.Atoi(val); err == nil {
+			return attempts
+		}
+	}
+	return 0
+}
+
+func (q *Queue) finish(key, workerId, state string) error {
+	unlock, err := q.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if s, _ := q.rdx.GetLastVal(QueueStateAsset, key); s != queueClaimed ||
+		!q.rdx.HasValue(QueueWorkerAsset, key, workerId) {
+		return fmt.Errorf("%w: %s by %s", ErrNotClaimed, key, workerId)
+	}
+
+	if err := q.rdx.ReplaceValues(QueueStateAsset, key, state); err != nil {
+		return err
+	}
+
+	return q.release(key)
+}
+
+func (q *Queue) leaseExpired(key string, now time.Time) bool {
+	val, ok := q.rdx.GetLastVal(QueueLeaseAsset, key)
+	if !ok {
+		return true
+	}
+	expires, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return true
+	}
+	return now.Unix() >= expires
+}
+
+func (q *Queue) release(keys ...string) error {
+	if err := q.rdx.CutKeys(QueueWorkerAsset, keys...); err != nil {
+		return err
+	}
+	return q.rdx.CutKeys(QueueLeaseAsset, keys...)
+}
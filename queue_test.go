@@ -0,0 +1,142 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueue(t *testing.T) {
+	q, err := NewQueue(filepath.Join(os.TempDir(), testsDirname))
+	testo.Error(t, err, false)
+
+	testo.Error(t, q.Enqueue("1", "2"), false)
+
+	key, ok, err := q.Claim("w1", time.Minute)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, ok, true)
+	testo.EqualValues(t, key, "1")
+
+	key, ok, err = q.Claim("w2", time.Minute)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, ok, true)
+	testo.EqualValues(t, key, "2")
+
+	// nothing left to claim
+	_, ok, err = q.Claim("w3", time.Minute)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, ok, false)
+
+	// only the worker that claimed the key can complete it
+	testo.EqualValues(t, errors.Is(q.Complete("1", "w2"), ErrNotClaimed), true)
+	testo.Error(t, q.Complete("1", "w1"), false)
+
+	// failed keys can be claimed again
+	testo.Error(t, q.Fail("2", "w2"), false)
+	key, ok, err = q.Claim("w3", 0)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, ok, true)
+	testo.EqualValues(t, key, "2")
+	testo.EqualValues(t, q.Attempts("2"), 2)
+
+	// expired leases can be claimed by other workers
+	key, ok, err = q.Claim("w4", time.Minute)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, ok, true)
+	testo.EqualValues(t, key, "2")
+	testo.EqualValues(t, q.Attempts("2"), 3)
+
+	testo.EqualValues(t, errors.Is(q.Complete("2", "w3"), ErrNotClaimed), true)
+	testo.Error(t, q.Complete("2", "w4"), false)
+
+	testo.Error(t, closeRedux(q.rdx), false)
+	testo.Error(t, os.Remove(q.lockFilename), false)
+	testo.Error(t, reduxCleanup(QueueStateAsset, QueueWorkerAsset, QueueLeaseAsset, QueueAttemptsAsset), false)
+}
+
+func TestQueue_AttemptsWhileClaiming(t *testing.T) {
+	q, err := NewQueue(filepath.Join(os.TempDir(), testsDirname))
+	testo.Error(t, err, false)
+
+	testo.Error(t, q.Enqueue("1", "2", "3"), false)
+
+	// Attempts must be safe to call while claims refresh the queue
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ii := 0; ii < 100; ii++ {
+			q.Attempts("1")
+		}
+	}()
+
+	for {
+		_, ok, err := q.Claim("w1", time.Minute)
+		testo.Error(t, err, false)
+		if !ok {
+			break
+		}
+	}
+	<-done
+
+	testo.EqualValues(t, q.Attempts("1"), 1)
+
+	testo.Error(t, closeRedux(q.rdx), false)
+	testo.Error(t, os.Remove(q.lockFilename), false)
+	testo.Error(t, reduxCleanup(QueueStateAsset, QueueWorkerAsset, QueueLeaseAsset, QueueAttemptsAsset), false)
+}
+
+func TestQueue_ClaimsAreExclusiveAcrossQueues(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname)
+
+	// queues connected to the same directory stand in for processes
+	queues := make([]*Queue, 3)
+	for ii := range queues {
+		q, err := NewQueue(dir)
+		testo.Error(t, err, false)
+		queues[ii] = q
+	}
+
+	keys := make([]string, 0, 20)
+	for ii := 0; ii < cap(keys); ii++ {
+		keys = append(keys, strconv.Itoa(ii))
+	}
+	testo.Error(t, queues[0].Enqueue(keys...), false)
+
+	mtx := new(sync.Mutex)
+	claimed := make(map[string]int)
+
+	wg := new(sync.WaitGroup)
+	for ii, q := range queues {
+		wg.Add(1)
+		go func(workerId string, q *Queue) {
+			defer wg.Done()
+			for {
+				key, ok, err := q.Claim(workerId, time.Minute)
+				testo.Error(t, err, false)
+				if !ok || err != nil {
+					return
+				}
+				mtx.Lock()
+				claimed[key]++
+				mtx.Unlock()
+			}
+		}("w"+strconv.Itoa(ii), q)
+	}
+	wg.Wait()
+
+	testo.EqualValues(t, len(claimed), len(keys))
+	for _, key := range keys {
+		testo.EqualValues(t, claimed[key], 1)
+	}
+
+	for _, q := range queues {
+		testo.Error(t, closeRedux(q.rdx), false)
+	}
+	testo.Error(t, os.Remove(queues[0].lockFilename), false)
+	testo.Error(t, reduxCleanup(QueueStateAsset, QueueWorkerAsset, QueueLeaseAsset, QueueAttemptsAsset), false)
+}
@@ -0,0 +1,51 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/busan"
+	"os"
+	"path/filepath"
+)
+
+// ReExt converts values stored in the directory from one extension to
+// another (e.g. HtmlExt to JsonExt after a pipeline change). Value files are
+// renamed in place, so log records (with created and updated timestamps)
+// and hashes are preserved
+func ReExt(dir, fromExt, toExt string) (err error) {
+	if fromExt == toExt {
+		return nil
+	}
+
+	ikv, err := NewKeyValues(dir, fromExt)
+	if err != nil {
+		return err
+	}
+
+	defer func() { err = errors.Join(err, ikv.Close()) }()
+
+	kv, ok := ikv.(*keyValues)
+	if !ok {
+		return errors.New("kevlar: unable to cast interface to a specific type")
+	}
+
+	keys, err := kv.Keys()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		absFromFilename := kv.absValueFilename(key)
+		if _, err := os.Stat(absFromFilename); os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		absToFilename := filepath.Join(dir, busan.Sanitize(key)+toExt)
+		if err := os.Rename(absFromFilename, absToFilename); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
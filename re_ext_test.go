@@ -0,0 +1,47 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReExt(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname)
+
+	hkv, err := NewKeyValues(dir, HtmlExt)
+	testo.Error(t, err, false)
+	testo.Error(t, hkv.Set("re-ext", strings.NewReader("{}")), false)
+
+	created, err := hkv.CreatedAfter(0)
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, created, []string{"re-ext"})
+
+	testo.Error(t, ReExt(dir, HtmlExt, JsonExt), false)
+
+	_, err = os.Stat(filepath.Join(dir, "re-ext"+HtmlExt))
+	testo.EqualValues(t, os.IsNotExist(err), true)
+
+	jkv, err := NewKeyValues(dir, JsonExt)
+	testo.Error(t, err, false)
+
+	created, err = jkv.CreatedAfter(0)
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, created, []string{"re-ext"})
+
+	// setting the same content shouldn't be considered an update
+	testo.Error(t, jkv.Set("re-ext", strings.NewReader("{}")), false)
+	updated, err := jkv.UpdatedAfter(0)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(updated), 0)
+
+	ok, err := jkv.Cut("re-ext")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, hkv.Close(), false)
+	testo.Error(t, jkv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
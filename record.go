@@ -0,0 +1,153 @@
+package kevlar
+
+import (
+	"os"
+	"strconv"
+)
+
+const (
+	MetaFastHash     = "fast-hash"
+	MetaRefreshAfter = "refresh-after"
+	MetaExpires      = "expires"
+	MetaPinned       = "pinned"
+)
+
+// Record describes a stored value: timestamps of the log records that
+// created and last modified it, its SHA-256 hash and size. Meta contains
+// optional annotations (see MetaFastHash, MetaRefreshAfter,
+// MetaExpires, MetaPinned)
+type Record struct {
+	Created  int64
+	Modified int64
+	Hash     string
+	Size     int64
+	Meta     map[string]string
+}
+
+// GetRecord returns the record of an existing key. Keys that don't
+// exist (or can't be described) are reported as not found
+func (kv *keyValues) GetRecord(key string) (Record, bool) {
+	record, err := kv.getRecord(key)
+	if err != nil {
+		return Record{}, false
+	}
+	return record, record.Created > 0
+}
+
+func (kv *keyValues) getRecord(key string) (Record, error) {
+	records, err := kv.getRecords([]string{key})
+	return records[key], err
+}
+
+// recordMetaFilenames are timestamps files with Record.Meta annotations
+var recordMetaFilenames = map[string]string{
+	MetaRefreshAfter: refreshAfterFilename,
+	MetaExpires:      expiresFilename,
+	MetaPinned:       pinnedFilename,
+}
+
+// getRecords describes existing keys with a single pass over log records,
+// reading timestamps files once. Keys that don't exist are not included
+func (kv *keyValues) getRecords(keys []string) (map[string]Record, error) {
+	if err := kv.refreshLogRecords(); err != nil {
+		return nil, err
+	}
+
+	// create and the latest update log records after it,
+	// same as currentLogRecords
+	type current struct{ created, updated *logRecord }
+	currents := make(map[string]current, len(keys))
+	for _, key := range keys {
+		currents[key] = current{}
+	}
+
+	kv.mtx.Lock()
+	for _, lr := range kv.log {
+		cur, ok := currents[lr.Id]
+		if !ok {
+			continue
+		}
+		switch lr.Mt {
+		case create:
+			cur = current{created: lr}
+		case update:
+			if cur.created != nil {
+				cur.updated = lr
+			}
+		case cut:
+			cur = current{}
+		}
+		currents[lr.Id] = cur
+	}
+
+	metaTimestamps := make(map[string]timestamps, len(recordMetaFilenames))
+	var err error
+	for meta, filename := range recordMetaFilenames {
+		if metaTimestamps[meta], err = kv.loadTimestamps(filename); err != nil {
+			break
+		}
+	}
+	kv.mtx.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	records := make(map[string]Record, len(keys))
+	for key, cur := range currents {
+		if cur.created == nil {
+			continue
+		}
+
+		record := Record{
+			Created:  cur.created.Ts,
+			Modified: cur.created.Ts,
+			Size:     cur.created.Sz,
+		}
+		if cur.updated != nil {
+			record.Modified = cur.updated.Ts
+			record.Size = cur.updated.Sz
+		}
+
+		if err := kv.describeRecord(key, &record, metaTimestamps); err != nil {
+			return records, err
+		}
+		records[key] = record
+	}
+
+	return records, nil
+}
+
+// describeRecord adds value file details and annotations to the record
+func (kv *keyValues) describeRecord(key string, record *Record, metaTimestamps map[string]timestamps) error {
+	// values written before sizes were logged (see Size)
+	if record.Size == 0 {
+		fi, err := os.Stat(kv.absValueFilename(key))
+		if err != nil {
+			return err
+		}
+		record.Size = fi.Size()
+	}
+
+	var err error
+
+	if record.Hash, err = readSidecarFile(kv.absHashFilename(key)); err != nil {
+		return err
+	}
+
+	record.Meta = make(map[string]string)
+
+	if fh, err := readSidecarFile(kv.absFastHashFilename(key)); err != nil {
+		return err
+	} else if fh != "" {
+		record.Meta[MetaFastHash] = fh
+	}
+
+	for meta, tss := range metaTimestamps {
+		if ts, ok := tss[key]; ok {
+			record.Meta[meta] = strconv.FormatInt(ts, 10)
+		}
+	}
+
+	return nil
+}
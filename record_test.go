@@ -0,0 +1,74 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyValues_GetRecord(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt, WithFastHash())
+	testo.Error(t, err, false)
+
+	_, ok := kv.GetRecord("record")
+	testo.EqualValues(t, ok, false)
+
+	testo.Error(t, kv.Set("record", strings.NewReader("value")), false)
+	testo.Error(t, kv.SetRefreshAfter("record", 42), false)
+
+	record, ok := kv.GetRecord("record")
+	testo.EqualValues(t, ok, true)
+	testo.EqualValues(t, record.Created > 0, true)
+	testo.EqualValues(t, record.Modified, record.Created)
+	testo.EqualValues(t, record.Hash, sha256Hex([]byte("value")))
+	testo.EqualValues(t, record.Size, int64(len("value")))
+	testo.EqualValues(t, record.Meta[MetaFastHash], fastHash([]byte("value")))
+	testo.EqualValues(t, record.Meta[MetaRefreshAfter], "42")
+
+	testo.Error(t, kv.Set("record", strings.NewReader("updated value")), false)
+
+	record, ok = kv.GetRecord("record")
+	testo.EqualValues(t, ok, true)
+	testo.EqualValues(t, record.Modified >= record.Created, true)
+	testo.EqualValues(t, record.Size, int64(len("updated value")))
+
+	testo.Error(t, kv.SetRefreshAfter("record", -1), false)
+	ok, err = kv.Cut("record")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	_, ok = kv.GetRecord("record")
+	testo.EqualValues(t, ok, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_GetRecordRecreated(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("recreated", strings.NewReader("1")), false)
+	testo.Error(t, kv.Set("recreated", strings.NewReader("22")), false)
+	_, err = kv.Cut("recreated")
+	testo.Error(t, err, false)
+	testo.Error(t, kv.Set("recreated", strings.NewReader("333")), false)
+
+	// timestamps have second resolution
+	time.Sleep(time.Second)
+	testo.Error(t, kv.Set("recreated", strings.NewReader("4444")), false)
+
+	record, ok := kv.GetRecord("recreated")
+	testo.EqualValues(t, ok, true)
+	testo.EqualValues(t, record.Modified > record.Created, true)
+	testo.EqualValues(t, record.Size, int64(4))
+
+	_, err = kv.Cut("recreated")
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
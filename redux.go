@@ -17,6 +17,8 @@ type redux struct {
 	akv map[string]map[string][]string
 	lmt map[string]int64
 	mtx *sync.Mutex
+	// pinned keys limit the scope of the redux view, see PinScope
+	pinned map[string]bool
 }
 
 func newRedux(dir string, assets ...string) (*redux, error) {
@@ -24,7 +26,11 @@ func newRedux(dir string, assets ...string) (*redux, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newReduxWith(kv, dir, assets...)
+}
 
+func newReduxWith(kv KeyValues, dir string, assets ...string) (*redux, error) {
+	var err error
 	assetKeyValues := make(map[string]map[string][]string)
 	amts := make(map[string]int64)
 	for _, asset := range assets {
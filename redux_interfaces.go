@@ -12,12 +12,20 @@ type ReadableRedux interface {
 	HasValue(asset, key, val string) bool
 	GetAllValues(asset, key string) ([]string, bool)
 	GetLastVal(asset, key string) (string, bool)
+	AnyKeyWithVal(asset, val string) (string, bool)
 	ModTime() (int64, error)
 	RefreshReader() (ReadableRedux, error)
 	MatchAsset(asset string, terms []string, scope []string, options ...MatchOption) []string
 	Match(query map[string][]string, options ...MatchOption) []string
 	Sort(ids []string, desc bool, sortBy ...string) ([]string, error)
+	SortBy(ids []string, orders ...SortOrder) ([]string, error)
 	Export(w io.Writer, keys ...string) error
+	PinScope(keys map[string]bool) ReadableRedux
+	Sum(asset string, keys []string) (float64, error)
+	Min(asset string, keys []string) (float64, error)
+	Max(asset string, keys []string) (float64, error)
+	Avg(asset string, keys []string) (float64, error)
+	GroupBy(groupAsset string, keys []string) map[string][]string
 }
 
 type WriteableRedux interface {
@@ -26,8 +34,12 @@ type WriteableRedux interface {
 	BatchAddValues(asset string, keyValues map[string][]string) error
 	ReplaceValues(asset, key string, values ...string) error
 	BatchReplaceValues(asset string, keyValues map[string][]string) error
+	ReplaceValuesDiff(asset, key string, values ...string) (ValuesDiff, error)
+	BatchReplaceValuesDiff(asset string, keyValues map[string][]string) (map[string]ValuesDiff, error)
 	CutKeys(asset string, keys ...string) error
 	CutValues(asset, key string, values ...string) error
 	BatchCutValues(asset string, keyValues map[string][]string) error
+	CompactAsset(asset string) (int, error)
+	CompactAll() (int, error)
 	RefreshWriter() (WriteableRedux, error)
 }
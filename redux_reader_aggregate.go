@@ -0,0 +1,93 @@
+package kevlar
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/exp/slices"
+	"strconv"
+)
+
+var ErrNoValues = errors.New("kevlar: no values to aggregate")
+
+// numericValues parses all values of the keys for the asset as numbers.
+// Keys that don't have values are skipped
+func (rdx *redux) numericValues(asset string, keys []string) ([]float64, error) {
+	if err := rdx.MustHave(asset); err != nil {
+		return nil, err
+	}
+
+	numbers := make([]float64, 0, len(keys))
+	for _, key := range keys {
+		values, _ := rdx.GetAllValues(asset, key)
+		for _, v := range values {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", asset, key, err)
+			}
+			numbers = append(numbers, n)
+		}
+	}
+
+	return numbers, nil
+}
+
+// Sum adds up values of the keys for the asset, treating them as numbers.
+// Sum of no values is 0
+func (rdx *redux) Sum(asset string, keys []string) (float64, error) {
+	numbers, err := rdx.numericValues(asset, keys)
+	if err != nil {
+		return 0, err
+	}
+
+	var sum float64
+	for _, n := range numbers {
+		sum += n
+	}
+	return sum, nil
+}
+
+// Min returns the smallest value of the keys for the asset, treating values
+// as numbers. ErrNoValues is returned when the keys don't have values
+func (rdx *redux) Min(asset string, keys []string) (float64, error) {
+	numbers, err := rdx.numericValues(asset, keys)
+	if err != nil {
+		return 0, err
+	}
+	if len(numbers) == 0 {
+		return 0, ErrNoValues
+	}
+
+	return slices.Min(numbers), nil
+}
+
+// Max returns the largest value of the keys for the asset, treating values
+// as numbers. ErrNoValues is returned when the keys don't have values
+func (rdx *redux) Max(asset string, keys []string) (float64, error) {
+	numbers, err := rdx.numericValues(asset, keys)
+	if err != nil {
+		return 0, err
+	}
+	if len(numbers) == 0 {
+		return 0, ErrNoValues
+	}
+
+	return slices.Max(numbers), nil
+}
+
+// Avg returns the mean of values of the keys for the asset, treating values
+// as numbers. ErrNoValues is returned when the keys don't have values
+func (rdx *redux) Avg(asset string, keys []string) (float64, error) {
+	numbers, err := rdx.numericValues(asset, keys)
+	if err != nil {
+		return 0, err
+	}
+	if len(numbers) == 0 {
+		return 0, ErrNoValues
+	}
+
+	var sum float64
+	for _, n := range numbers {
+		sum += n
+	}
+	return sum / float64(len(numbers)), nil
+}
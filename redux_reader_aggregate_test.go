@@ -0,0 +1,47 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"strconv"
+	"testing"
+)
+
+func TestRedux_Aggregate(t *testing.T) {
+	rdx := &redux{akv: sortableAKV}
+
+	ids := []string{"id1", "id2", "id3", "id-without-values"}
+
+	tests := []struct {
+		aggregate func(string, []string) (float64, error)
+		exp       float64
+	}{
+		{rdx.Sum, 6666},
+		{rdx.Min, 1111},
+		{rdx.Max, 3333},
+		{rdx.Avg, 2222},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			n, err := tt.aggregate("number", ids)
+			testo.Error(t, err, false)
+			testo.EqualValues(t, n, tt.exp)
+
+			_, err = tt.aggregate("title", ids)
+			testo.Error(t, err, true)
+
+			_, err = tt.aggregate("asset-that-doesnt-exist", ids)
+			testo.Error(t, err, true)
+		})
+	}
+
+	sum, err := rdx.Sum("number", nil)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, sum, 0.0)
+
+	for _, aggregate := range []func(string, []string) (float64, error){rdx.Min, rdx.Max, rdx.Avg} {
+		_, err = aggregate("number", nil)
+		testo.EqualValues(t, errors.Is(err, ErrNoValues), true)
+	}
+}
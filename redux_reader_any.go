@@ -0,0 +1,14 @@
+package kevlar
+
+import "golang.org/x/exp/slices"
+
+// AnyKeyWithVal returns a key that has the value for the asset. When
+// several keys have the value, any one of them might be returned
+func (rdx *redux) AnyKeyWithVal(asset, val string) (string, bool) {
+	for key, values := range rdx.akv[asset] {
+		if slices.Contains(values, val) {
+			return key, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,30 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"strconv"
+	"testing"
+)
+
+func TestRedux_AnyKeyWithVal(t *testing.T) {
+	tests := []struct {
+		asset, val string
+		exp        string
+		expOk      bool
+	}{
+		{"a1", "v22", "k2", true},
+		{"a2", "v55", "k5", true},
+		{"a1", "v55", "", false},
+		{"asset-that-doesnt-exist", "v11", "", false},
+	}
+
+	rdx := mockRedux()
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			key, ok := rdx.AnyKeyWithVal(tt.asset, tt.val)
+			testo.EqualValues(t, ok, tt.expOk)
+			testo.EqualValues(t, key, tt.exp)
+		})
+	}
+}
@@ -1,27 +1,45 @@
 package kevlar
 
 import (
-	"github.com/boggydigital/wits"
+	"bufio"
 	"golang.org/x/exp/maps"
 	"io"
 	"sort"
+	"strings"
 )
 
+// Export writes values of the keys in wits section key values format
+// (sections are keys, keys are assets). Output is deterministic: keys and
+// assets are sorted, so that identical contents produce identical exports
 func (rdx *redux) Export(w io.Writer, keys ...string) error {
 
 	assets := maps.Keys(rdx.akv)
 	sort.Strings(assets)
 
-	skv := make(wits.SectionKeyValues)
+	sortedKeys := make([]string, len(keys))
+	copy(sortedKeys, keys)
+	sort.Strings(sortedKeys)
 
-	for _, key := range keys {
-		skv[key] = make(wits.KeyValues)
+	bw := bufio.NewWriter(w)
+
+	for ii, key := range sortedKeys {
+		if ii > 0 && key == sortedKeys[ii-1] {
+			continue
+		}
+		if _, err := bw.WriteString(key + "\n"); err != nil {
+			return err
+		}
 		for _, asset := range assets {
-			if values := rdx.akv[asset][key]; len(values) > 0 {
-				skv[key][asset] = values
+			values := rdx.akv[asset][key]
+			if len(values) == 0 {
+				continue
+			}
+			// matches wits formatting of section key values
+			if _, err := bw.WriteString(" " + asset + "=" + strings.Join(values, ";") + "\n"); err != nil {
+				return err
 			}
 		}
 	}
 
-	return skv.Write(w)
+	return bw.Flush()
 }
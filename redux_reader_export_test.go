@@ -2,6 +2,8 @@ package kevlar
 
 import (
 	"github.com/boggydigital/testo"
+	"github.com/boggydigital/wits"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -14,3 +16,27 @@ func TestRedux_Export(t *testing.T) {
 	testo.Error(t, rdx.Export(sb, rdx.Keys("a1")...), false)
 	testo.CompareInt64(t, int64(sb.Len()), 0, testo.Greater)
 }
+
+func TestRedux_ExportDeterministic(t *testing.T) {
+	rdx := mockRedux()
+
+	keys := []string{"k3", "k1", "k5", "k2", "k4", "k1"}
+
+	exports := make([]string, 0, 3)
+	for ii := 0; ii < 3; ii++ {
+		sb := &strings.Builder{}
+		testo.Error(t, rdx.Export(sb, keys...), false)
+		exports = append(exports, sb.String())
+		// reverse keys order, which shouldn't affect the output
+		slices.Reverse(keys)
+	}
+
+	testo.EqualValues(t, exports[0], exports[1])
+	testo.EqualValues(t, exports[1], exports[2])
+
+	skv, err := wits.ReadSectionKeyValues(strings.NewReader(exports[0]))
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(skv), 5)
+	testo.DeepEqual(t, skv["k2"]["a1"], []string{"v21", "v22"})
+	testo.DeepEqual(t, skv["k4"]["a2"], []string{"v41", "v42", "v43", "v44"})
+}
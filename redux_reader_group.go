@@ -0,0 +1,28 @@
+package kevlar
+
+import "golang.org/x/exp/slices"
+
+// GroupBy clusters keys by their values for the asset (e.g. games by
+// developer). Keys with multiple values are added to every group, keys
+// without values are grouped under an empty string. Keys within a group
+// keep the order they were provided in
+func (rdx *redux) GroupBy(groupAsset string, keys []string) map[string][]string {
+	groups := make(map[string][]string)
+
+	for _, key := range keys {
+		values, _ := rdx.GetAllValues(groupAsset, key)
+		if len(values) == 0 {
+			groups[""] = append(groups[""], key)
+			continue
+		}
+		for ii, v := range values {
+			// keys with duplicate values are only added to a group once
+			if slices.Contains(values[:ii], v) {
+				continue
+			}
+			groups[v] = append(groups[v], key)
+		}
+	}
+
+	return groups
+}
@@ -0,0 +1,26 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"testing"
+)
+
+func TestRedux_GroupBy(t *testing.T) {
+	rdx := &redux{akv: map[string]map[string][]string{
+		"developer": {
+			"g1": {"d1"},
+			"g2": {"d2", "d1"},
+			"g3": {"d2", "d2"},
+			"g4": {},
+		},
+	}}
+
+	groups := rdx.GroupBy("developer", []string{"g1", "g2", "g3", "g4", "g5"})
+	testo.DeepEqual(t, groups, map[string][]string{
+		"d1": {"g1", "g2"},
+		"d2": {"g2", "g3"},
+		"":   {"g4", "g5"},
+	})
+
+	testo.DeepEqual(t, rdx.GroupBy("asset-that-doesnt-exist", []string{"g1"}), map[string][]string{"": {"g1"}})
+}
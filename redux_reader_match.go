@@ -4,9 +4,18 @@ import (
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// MatchAsset returns keys with at least one value of the asset matching
+// at least one of the terms (see MatchOption). Only keys in scope are
+// matched, nil scope matches all asset keys. Matches are returned in
+// no particular order
 func (rdx *redux) MatchAsset(asset string, terms []string, scope []string, options ...MatchOption) []string {
+	span := tracer.Start(opMatch, asset)
+	defer span.End(nil)
+
 	if scope == nil {
 		scope = rdx.Keys(asset)
 	}
@@ -14,7 +23,7 @@ func (rdx *redux) MatchAsset(asset string, terms []string, scope []string, optio
 	matches := make(map[string]interface{})
 	for _, term := range terms {
 		if !slices.Contains(options, CaseSensitive) {
-			term = strings.ToLower(term)
+			term = foldCase(term)
 		}
 		for _, key := range scope {
 			if values, ok := rdx.GetAllValues(asset, key); !ok {
@@ -28,6 +37,10 @@ func (rdx *redux) MatchAsset(asset string, terms []string, scope []string, optio
 	return maps.Keys(matches)
 }
 
+// Match returns keys matching every asset of the query, as if MatchAsset was
+// applied to each asset in turn, scoped to the keys matched so far. Query
+// assets missing in the reduction are ignored and an empty query has no
+// matches. Matches are returned in no particular order
 func (rdx *redux) Match(query map[string][]string, options ...MatchOption) []string {
 	var matches []string
 	for asset, terms := range query {
@@ -50,7 +63,7 @@ func anyValueMatchesTerm(term string, values []string, options ...MatchOption) b
 
 	for _, val := range values {
 		if anyCase {
-			val = strings.ToLower(val)
+			val = foldCase(val)
 		}
 		if contains {
 			if strings.Contains(val, term) {
@@ -64,3 +77,26 @@ func anyValueMatchesTerm(term string, values []string, options ...MatchOption) b
 	}
 	return false
 }
+
+// foldCase maps every rune to a single rune of its case folding orbit, so
+// that strings differing only in case are equal after folding. Unlike
+// strings.ToLower this handles runes like long s (ſ) or Kelvin sign (K)
+// that fold to ASCII letters. ASCII runes are mapped to lower case, so
+// lower case ASCII strings are returned without allocations
+func foldCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf {
+			return unicode.ToLower(r)
+		}
+		folded := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < utf8.RuneSelf {
+				return unicode.ToLower(f)
+			}
+			if f < folded {
+				folded = f
+			}
+		}
+		return folded
+	}, s)
+}
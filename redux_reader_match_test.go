@@ -2,8 +2,11 @@ package kevlar
 
 import (
 	"github.com/boggydigital/testo"
+	"golang.org/x/exp/slices"
+	"math/rand/v2"
 	"sort"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -94,3 +97,170 @@ func TestRedux_Match(t *testing.T) {
 		})
 	}
 }
+
+func TestFoldCase(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp string
+	}{
+		{"", ""},
+		{"title", "title"},
+		{"TITLE", "title"},
+		{"ſ", "s"},
+		{"\u212A", "k"},
+		{"Éé", "ÉÉ"},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			testo.EqualValues(t, foldCase(tt.in), tt.exp)
+		})
+	}
+}
+
+// matchRunes are used to generate values and terms for property tests,
+// including runes that fold to ASCII letters
+var matchRunes = []rune("aAbBsSſkK\u212AéÉ")
+
+const matchPropertyIterations = 500
+
+func randomMatchString(rnd *rand.Rand) string {
+	rs := make([]rune, rnd.IntN(4))
+	for ii := range rs {
+		rs[ii] = matchRunes[rnd.IntN(len(matchRunes))]
+	}
+	return string(rs)
+}
+
+func randomMatchableRedux(rnd *rand.Rand) *redux {
+	akv := make(map[string]map[string][]string)
+	for _, asset := range []string{"t", "v"} {
+		akv[asset] = make(map[string][]string)
+		for ii := 0; ii < 8; ii++ {
+			if rnd.IntN(4) == 0 {
+				continue
+			}
+			values := make([]string, rnd.IntN(3))
+			for jj := range values {
+				values[jj] = randomMatchString(rnd)
+			}
+			akv[asset][strconv.Itoa(ii)] = values
+		}
+	}
+	return &redux{akv: akv}
+}
+
+func randomMatchTerms(rnd *rand.Rand) []string {
+	terms := make([]string, 1+rnd.IntN(2))
+	for ii := range terms {
+		terms[ii] = randomMatchString(rnd)
+	}
+	return terms
+}
+
+var matchOptionSets = [][]MatchOption{
+	nil,
+	{CaseSensitive},
+	{FullMatch},
+	{CaseSensitive, FullMatch},
+}
+
+func sorted(keys []string) []string {
+	keys = slices.Clone(keys)
+	sort.Strings(keys)
+	return keys
+}
+
+func union(a, b []string) []string {
+	u := slices.Clone(a)
+	for _, key := range b {
+		if !slices.Contains(u, key) {
+			u = append(u, key)
+		}
+	}
+	return sorted(u)
+}
+
+func intersection(a, b []string) []string {
+	i := make([]string, 0)
+	for _, key := range a {
+		if slices.Contains(b, key) {
+			i = append(i, key)
+		}
+	}
+	return sorted(i)
+}
+
+func subset(a, b []string) bool {
+	for _, key := range a {
+		if !slices.Contains(b, key) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRedux_MatchProperties(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+
+	for ii := 0; ii < matchPropertyIterations; ii++ {
+		rdx := randomMatchableRedux(rnd)
+		t1, t2 := randomMatchTerms(rnd), randomMatchTerms(rnd)
+		scope := []string{"1", "3", "5", "7"}
+
+		for _, options := range matchOptionSets {
+			matches := rdx.MatchAsset("t", t1, nil, options...)
+
+			// matches are asset keys
+			testo.EqualValues(t, subset(matches, rdx.Keys("t")), true)
+
+			// scoped matches are matches in scope
+			testo.DeepEqual(t,
+				sorted(rdx.MatchAsset("t", t1, scope, options...)),
+				intersection(matches, scope))
+
+			// terms are alternatives
+			testo.DeepEqual(t,
+				sorted(rdx.MatchAsset("t", append(slices.Clone(t1), t2...), nil, options...)),
+				union(matches, rdx.MatchAsset("t", t2, nil, options...)))
+
+			// query assets are conjunctions
+			testo.DeepEqual(t,
+				sorted(rdx.Match(map[string][]string{"t": t1, "v": t2}, options...)),
+				intersection(matches, rdx.MatchAsset("v", t2, nil, options...)))
+
+			// unknown query assets are ignored
+			testo.DeepEqual(t,
+				sorted(rdx.Match(map[string][]string{"t": t1, "unknown": t2}, options...)),
+				sorted(matches))
+		}
+
+		// options only narrow matches
+		anyMatches := rdx.MatchAsset("t", t1, nil)
+		for _, options := range matchOptionSets {
+			testo.EqualValues(t, subset(rdx.MatchAsset("t", t1, nil, options...), anyMatches), true)
+		}
+		testo.EqualValues(t, subset(
+			rdx.MatchAsset("t", t1, nil, CaseSensitive, FullMatch),
+			rdx.MatchAsset("t", t1, nil, FullMatch)), true)
+
+		// case doesn't matter by default
+		for _, term := range t1 {
+			for _, options := range [][]MatchOption{nil, {FullMatch}} {
+				exp := sorted(rdx.MatchAsset("t", []string{term}, nil, options...))
+				testo.DeepEqual(t, sorted(rdx.MatchAsset("t", []string{strings.ToUpper(term)}, nil, options...)), exp)
+				testo.DeepEqual(t, sorted(rdx.MatchAsset("t", []string{strings.ToLower(term)}, nil, options...)), exp)
+			}
+		}
+
+		// full match ignoring case is the same as strings.EqualFold
+		term := t1[0]
+		exp := make([]string, 0)
+		for key, values := range rdx.akv["t"] {
+			if slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, term) }) {
+				exp = append(exp, key)
+			}
+		}
+		testo.DeepEqual(t, sorted(rdx.MatchAsset("t", []string{term}, nil, FullMatch)), sorted(exp))
+	}
+}
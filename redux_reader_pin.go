@@ -0,0 +1,44 @@
+package kevlar
+
+import (
+	"sync"
+)
+
+// PinScope returns a reader view limited to the pinned keys: getters, Keys,
+// Match, Sort and Export only see values of those keys. The view shares
+// values with the redux, so it's cheap to create per request. Refreshing
+// the view reloads updated assets and keeps them limited to pinned keys
+func (rdx *redux) PinScope(keys map[string]bool) ReadableRedux {
+	pinned := make(map[string]bool, len(keys))
+	for key, ok := range keys {
+		if ok {
+			pinned[key] = true
+		}
+	}
+
+	akv := make(map[string]map[string][]string, len(rdx.akv))
+	lmt := make(map[string]int64, len(rdx.lmt))
+	for asset, keyValues := range rdx.akv {
+		akv[asset] = pinKeyValues(keyValues, pinned)
+		lmt[asset] = rdx.lmt[asset]
+	}
+
+	return &redux{
+		dir:    rdx.dir,
+		kv:     rdx.kv,
+		akv:    akv,
+		lmt:    lmt,
+		mtx:    new(sync.Mutex),
+		pinned: pinned,
+	}
+}
+
+func pinKeyValues(keyValues map[string][]string, pinned map[string]bool) map[string][]string {
+	pkv := make(map[string][]string)
+	for key := range pinned {
+		if values, ok := keyValues[key]; ok {
+			pkv[key] = values
+		}
+	}
+	return pkv
+}
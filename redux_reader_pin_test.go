@@ -0,0 +1,34 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"golang.org/x/exp/slices"
+	"testing"
+)
+
+func TestRedux_PinScope(t *testing.T) {
+	rdx := mockRedux()
+
+	pinned := rdx.PinScope(map[string]bool{"k1": true, "k2": true, "k3": false, "k4": true})
+
+	keys := pinned.Keys("a1")
+	slices.Sort(keys)
+	testo.DeepEqual(t, keys, []string{"k1", "k2"})
+	testo.DeepEqual(t, pinned.Keys("a2"), []string{"k4"})
+
+	testo.EqualValues(t, pinned.HasKey("a1", "k3"), false)
+	testo.EqualValues(t, pinned.HasValue("a1", "k3", "v31"), false)
+	_, ok := pinned.GetAllValues("a2", "k5")
+	testo.EqualValues(t, ok, false)
+
+	values, ok := pinned.GetAllValues("a1", "k2")
+	testo.EqualValues(t, ok, true)
+	testo.DeepEqual(t, values, []string{"v21", "v22"})
+
+	// matching values outside of the pinned scope are not returned
+	testo.DeepEqual(t, pinned.Match(map[string][]string{"a1": {"v3"}}), []string{})
+	testo.DeepEqual(t, pinned.Match(map[string][]string{"a1": {"v2"}}), []string{"k2"})
+
+	// original redux is not affected
+	testo.EqualValues(t, rdx.HasKey("a1", "k3"), true)
+}
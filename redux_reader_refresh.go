@@ -45,6 +45,9 @@ func (rdx *redux) refresh() (*redux, error) {
 			if err != nil {
 				return nil, err
 			}
+			if rdx.pinned != nil {
+				ckv = pinKeyValues(ckv, rdx.pinned)
+			}
 			rdx.akv[asset] = ckv
 			rdx.lmt[asset] = amts[asset]
 		}
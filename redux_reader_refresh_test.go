@@ -41,6 +41,7 @@ func TestRedux_ModTime(t *testing.T) {
 	testo.Error(t, err, false)
 	testo.EqualValues(t, ok, true)
 
+	testo.Error(t, closeRedux(rdx), false)
 	testo.Error(t, logRecordsCleanup(), false)
 }
 
@@ -88,5 +89,6 @@ func TestRedux_RefreshReader(t *testing.T) {
 	testo.Error(t, err, false)
 	testo.EqualValues(t, newMt, startModTime)
 
+	testo.Error(t, closeRedux(rdx), false)
 	testo.Error(t, logRecordsCleanup(), false)
 }
@@ -63,3 +63,51 @@ func (rdx *redux) Sort(ids []string, desc bool, sortBy ...string) ([]string, err
 
 	return sorted, nil
 }
+
+// SortOrder specifies asset to sort by and the direction
+type SortOrder struct {
+	Asset string
+	Desc  bool
+}
+
+// SortBy sorts ids by the last values of multiple assets, each in its own
+// direction (e.g. release date descending, then title ascending). Ids that
+// have the same values for all assets are sorted by id, so the result
+// doesn't depend on the order of provided ids
+func (rdx *redux) SortBy(ids []string, orders ...SortOrder) ([]string, error) {
+	for _, order := range orders {
+		if err := rdx.MustHave(order.Asset); err != nil {
+			return nil, err
+		}
+	}
+
+	ivs := make([]idValues, 0, len(ids))
+	for _, id := range ids {
+		iv := idValues{id: id, values: make([]string, 0, len(orders))}
+		for _, order := range orders {
+			v, _ := rdx.GetLastVal(order.Asset, id)
+			iv.values = append(iv.values, v)
+		}
+		ivs = append(ivs, iv)
+	}
+
+	sort.SliceStable(ivs, func(i, j int) bool {
+		for p, order := range orders {
+			if ivs[i].values[p] == ivs[j].values[p] {
+				continue
+			}
+			if order.Desc {
+				return ivs[i].values[p] > ivs[j].values[p]
+			}
+			return ivs[i].values[p] < ivs[j].values[p]
+		}
+		return ivs[i].id < ivs[j].id
+	})
+
+	sorted := make([]string, 0, len(ivs))
+	for _, iv := range ivs {
+		sorted = append(sorted, iv.id)
+	}
+
+	return sorted, nil
+}
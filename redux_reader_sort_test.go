@@ -62,3 +62,35 @@ func TestRedux_Sort(t *testing.T) {
 		})
 	}
 }
+
+func TestRedux_SortBy(t *testing.T) {
+
+	ids := []string{"id3", "id1", "id2"}
+
+	tests := []struct {
+		ids    []string
+		orders []SortOrder
+		exp    []string
+		expErr bool
+	}{
+		{nil, nil, []string{}, false},
+		// no orders - sorted by id
+		{ids, nil, []string{"id1", "id2", "id3"}, false},
+		{ids, []SortOrder{{"title", true}}, []string{"id3", "id2", "id1"}, false},
+		{ids, []SortOrder{{"binary", true}, {"number", false}}, []string{"id1", "id2", "id3"}, false},
+		{ids, []SortOrder{{"binary", true}, {"number", true}}, []string{"id2", "id1", "id3"}, false},
+		{ids, []SortOrder{{"binary", false}, {"subtitle", false}}, []string{"id3", "id2", "id1"}, false},
+		// ties are broken by id
+		{ids, []SortOrder{{"binary", true}}, []string{"id1", "id2", "id3"}, false},
+		{ids, []SortOrder{{"title", false}, {"asset-that-doesnt-exist", false}}, nil, true},
+	}
+
+	rdx := &redux{akv: sortableAKV}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			tr, err := rdx.SortBy(tt.ids, tt.orders...)
+			testo.Error(t, err, tt.expErr)
+			testo.DeepEqual(t, tr, tt.exp)
+		})
+	}
+}
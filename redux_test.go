@@ -22,6 +22,11 @@ func reduxCleanup(assets ...string) error {
 	return logRecordsCleanup()
 }
 
+// closeRedux releases shared key values connected with the redux
+func closeRedux(rdx ReadableRedux) error {
+	return rdx.(*redux).kv.Close()
+}
+
 func mockRedux() *redux {
 	return &redux{
 		dir: filepath.Join(os.TempDir(), testsDirname),
@@ -10,66 +10,89 @@ func NewReduxWriter(dir string, assets ...string) (WriteableRedux, error) {
 	return newRedux(dir, assets...)
 }
 
-func (rdx *redux) addValues(asset, key string, values ...string) error {
+// NewReduxWriterWith connects reduction writer to assets stored in the
+// provided key values (e.g. a test double, see kevlartest) instead of
+// connecting key values in a directory
+func NewReduxWriterWith(kv KeyValues, assets ...string) (WriteableRedux, error) {
+	return newReduxWith(kv, "", assets...)
+}
+
+// addValues adds values that the key doesn't have yet and returns true
+// when asset values have changed. Same as Set skips values that haven't
+// changed, assets are only written when mutations have changed them
+func (rdx *redux) addValues(asset, key string, values ...string) (bool, error) {
 	if !rdx.HasAsset(asset) {
-		return ErrUnknownAsset(asset)
+		return false, ErrUnknownAsset(asset)
 	}
 	newValues := make([]string, 0, len(values))
 	for _, v := range values {
-		if !rdx.HasValue(asset, key, v) {
+		if !rdx.HasValue(asset, key, v) && !slices.Contains(newValues, v) {
 			newValues = append(newValues, v)
 		}
 	}
+	if len(newValues) == 0 {
+		return false, nil
+	}
 	rdx.akv[asset][key] = append(rdx.akv[asset][key], newValues...)
-	return rdx.write(asset)
+	return true, nil
 }
 
 func (rdx *redux) AddValues(asset, key string, values ...string) error {
-	return rdx.addValues(asset, key, values...)
+	if changed, err := rdx.addValues(asset, key, values...); err != nil || !changed {
+		return err
+	}
+	return rdx.write(asset)
 }
 
 func (rdx *redux) BatchAddValues(asset string, keyValues map[string][]string) error {
-	for key, values := range keyValues {
-		if err := rdx.addValues(asset, key, values...); err != nil {
-			return err
-		}
-	}
-	return nil
+	return rdx.batch(asset, keyValues, rdx.addValues)
 }
 
-func (rdx *redux) replaceValues(asset, key string, values ...string) error {
+func (rdx *redux) replaceValues(asset, key string, values ...string) (bool, error) {
 	if !rdx.HasAsset(asset) {
-		return ErrUnknownAsset(asset)
+		return false, ErrUnknownAsset(asset)
+	}
+	if current, ok := rdx.akv[asset][key]; ok && slices.Equal(current, values) {
+		return false, nil
 	}
 	rdx.akv[asset][key] = values
-	return nil
+	return true, nil
 }
 
 func (rdx *redux) ReplaceValues(asset, key string, values ...string) error {
-	if err := rdx.replaceValues(asset, key, values...); err != nil {
+	if changed, err := rdx.replaceValues(asset, key, values...); err != nil || !changed {
 		return err
 	}
 	return rdx.write(asset)
 }
 
 func (rdx *redux) BatchReplaceValues(asset string, keyValues map[string][]string) error {
-	if len(keyValues) == 0 {
-		return nil
-	}
+	return rdx.batch(asset, keyValues, rdx.replaceValues)
+}
+
+// batch applies mutation to every key and writes the asset once,
+// when any values have changed
+func (rdx *redux) batch(asset string, keyValues map[string][]string, mutate func(asset, key string, values ...string) (bool, error)) error {
+	changed := false
 	for key, values := range keyValues {
-		if err := rdx.replaceValues(asset, key, values...); err != nil {
+		if ch, err := mutate(asset, key, values...); err != nil {
 			return err
+		} else if ch {
+			changed = true
 		}
 	}
+	if !changed {
+		return nil
+	}
 	return rdx.write(asset)
 }
 
-func (rdx *redux) cutValues(asset, key string, values ...string) error {
+func (rdx *redux) cutValues(asset, key string, values ...string) (bool, error) {
 	if !rdx.HasAsset(asset) {
-		return ErrUnknownAsset(asset)
+		return false, ErrUnknownAsset(asset)
 	}
 	if !rdx.HasKey(asset, key) {
-		return nil
+		return false, nil
 	}
 
 	newValues := make([]string, 0, len(rdx.akv[asset][key]))
@@ -81,17 +104,19 @@ func (rdx *redux) cutValues(asset, key string, values ...string) error {
 		newValues = append(newValues, v)
 	}
 
+	changed := len(newValues) < len(rdx.akv[asset][key])
 	rdx.akv[asset][key] = newValues
 
 	// remove keys if there are no values left
 	if len(rdx.akv[asset][key]) == 0 {
 		delete(rdx.akv[asset], key)
+		changed = true
 	}
-	return nil
+	return changed, nil
 }
 
 func (rdx *redux) CutValues(asset, key string, values ...string) error {
-	if err := rdx.cutValues(asset, key, values...); err != nil {
+	if changed, err := rdx.cutValues(asset, key, values...); err != nil || !changed {
 		return err
 	}
 	return rdx.write(asset)
@@ -101,26 +126,22 @@ func (rdx *redux) CutKeys(asset string, keys ...string) error {
 	if !rdx.HasAsset(asset) {
 		return ErrUnknownAsset(asset)
 	}
-	if len(keys) == 0 {
-		return nil
-	}
 
+	changed := false
 	for _, key := range keys {
-		delete(rdx.akv[asset], key)
+		if _, ok := rdx.akv[asset][key]; ok {
+			delete(rdx.akv[asset], key)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
 	}
 	return rdx.write(asset)
 }
 
 func (rdx *redux) BatchCutValues(asset string, keyValues map[string][]string) error {
-	if len(keyValues) == 0 {
-		return nil
-	}
-	for key, values := range keyValues {
-		if err := rdx.cutValues(asset, key, values...); err != nil {
-			return err
-		}
-	}
-	return rdx.write(asset)
+	return rdx.batch(asset, keyValues, rdx.cutValues)
 }
 
 func (rdx *redux) write(asset string) error {
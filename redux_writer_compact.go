@@ -0,0 +1,50 @@
+package kevlar
+
+// CompactAsset removes empty string values and keys without values from
+// the asset and returns the number of dropped records (values and keys).
+// Asset is only written when something has been dropped
+func (rdx *redux) CompactAsset(asset string) (int, error) {
+	if !rdx.HasAsset(asset) {
+		return 0, ErrUnknownAsset(asset)
+	}
+
+	dropped := 0
+
+	for key, values := range rdx.akv[asset] {
+		compacted := make([]string, 0, len(values))
+		for _, v := range values {
+			if v == "" {
+				dropped++
+				continue
+			}
+			compacted = append(compacted, v)
+		}
+
+		if len(compacted) == 0 {
+			delete(rdx.akv[asset], key)
+			dropped++
+		} else if len(compacted) < len(values) {
+			rdx.akv[asset][key] = compacted
+		}
+	}
+
+	if dropped == 0 {
+		return 0, nil
+	}
+
+	return dropped, rdx.write(asset)
+}
+
+// CompactAll compacts every asset (see CompactAsset) and returns
+// the total number of dropped records
+func (rdx *redux) CompactAll() (int, error) {
+	total := 0
+	for asset := range rdx.akv {
+		dropped, err := rdx.CompactAsset(asset)
+		total += dropped
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,45 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"testing"
+)
+
+func TestReduxCompactAsset(t *testing.T) {
+	rdx := mockRedux()
+	rdx.akv["a1"]["k1"] = []string{"v11", ""}
+	rdx.akv["a1"]["empty"] = []string{}
+	rdx.akv["a1"]["empty-strings"] = []string{"", ""}
+
+	dropped, err := rdx.CompactAsset("a1")
+	testo.Error(t, err, false)
+	// 1 empty value in k1, 2 empty values and 2 keys without values
+	testo.EqualValues(t, dropped, 5)
+
+	testo.DeepEqual(t, rdx.akv["a1"]["k1"], []string{"v11"})
+	testo.EqualValues(t, rdx.HasKey("a1", "empty"), false)
+	testo.EqualValues(t, rdx.HasKey("a1", "empty-strings"), false)
+
+	dropped, err = rdx.CompactAsset("a1")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, dropped, 0)
+
+	_, err = rdx.CompactAsset("unknown")
+	testo.Error(t, err, true)
+
+	testo.Error(t, reduxCleanup("a1"), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestReduxCompactAll(t *testing.T) {
+	rdx := mockRedux()
+	rdx.akv["a1"]["empty"] = nil
+	rdx.akv["a2"]["k4"] = append(rdx.akv["a2"]["k4"], "")
+
+	dropped, err := rdx.CompactAll()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, dropped, 2)
+
+	testo.Error(t, reduxCleanup("a1", "a2"), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,55 @@
+package kevlar
+
+import "golang.org/x/exp/slices"
+
+// ValuesDiff contains values added and removed by a replacement.
+// Changes of values order are not reported
+type ValuesDiff struct {
+	Added   []string
+	Removed []string
+}
+
+// Changed returns true when any values were added or removed
+func (vd ValuesDiff) Changed() bool {
+	return len(vd.Added) > 0 || len(vd.Removed) > 0
+}
+
+func diffValues(current, values []string) ValuesDiff {
+	var vd ValuesDiff
+	for _, v := range values {
+		if !slices.Contains(current, v) && !slices.Contains(vd.Added, v) {
+			vd.Added = append(vd.Added, v)
+		}
+	}
+	for _, v := range current {
+		if !slices.Contains(values, v) && !slices.Contains(vd.Removed, v) {
+			vd.Removed = append(vd.Removed, v)
+		}
+	}
+	return vd
+}
+
+// ReplaceValuesDiff replaces values same as ReplaceValues and returns
+// values that were added and removed
+func (rdx *redux) ReplaceValuesDiff(asset, key string, values ...string) (ValuesDiff, error) {
+	if !rdx.HasAsset(asset) {
+		return ValuesDiff{}, ErrUnknownAsset(asset)
+	}
+	vd := diffValues(rdx.akv[asset][key], values)
+	return vd, rdx.ReplaceValues(asset, key, values...)
+}
+
+// BatchReplaceValuesDiff replaces values same as BatchReplaceValues and
+// returns values that were added and removed for the keys that changed
+func (rdx *redux) BatchReplaceValuesDiff(asset string, keyValues map[string][]string) (map[string]ValuesDiff, error) {
+	if !rdx.HasAsset(asset) {
+		return nil, ErrUnknownAsset(asset)
+	}
+	diffs := make(map[string]ValuesDiff)
+	for key, values := range keyValues {
+		if vd := diffValues(rdx.akv[asset][key], values); vd.Changed() {
+			diffs[key] = vd
+		}
+	}
+	return diffs, rdx.BatchReplaceValues(asset, keyValues)
+}
@@ -0,0 +1,50 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"strconv"
+	"testing"
+)
+
+func TestDiffValues(t *testing.T) {
+	tests := []struct {
+		current, values []string
+		exp             ValuesDiff
+	}{
+		{nil, nil, ValuesDiff{}},
+		{[]string{"1", "2"}, []string{"2", "1"}, ValuesDiff{}},
+		{nil, []string{"1", "1"}, ValuesDiff{Added: []string{"1"}}},
+		{[]string{"1"}, nil, ValuesDiff{Removed: []string{"1"}}},
+		{[]string{"1", "2"}, []string{"2", "3"}, ValuesDiff{Added: []string{"3"}, Removed: []string{"1"}}},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			vd := diffValues(tt.current, tt.values)
+			testo.DeepEqual(t, vd, tt.exp)
+			testo.EqualValues(t, vd.Changed(), len(tt.exp.Added)+len(tt.exp.Removed) > 0)
+		})
+	}
+}
+
+func TestReduxReplaceValuesDiff(t *testing.T) {
+	rdx := mockRedux()
+
+	vd, err := rdx.ReplaceValuesDiff("a1", "k2", "v21", "v23")
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, vd, ValuesDiff{Added: []string{"v23"}, Removed: []string{"v22"}})
+	testo.DeepEqual(t, rdx.akv["a1"]["k2"], []string{"v21", "v23"})
+
+	diffs, err := rdx.BatchReplaceValuesDiff("a1", map[string][]string{
+		"k1": {"v11"},
+		"k3": {"v31"},
+	})
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, diffs, map[string]ValuesDiff{"k3": {Removed: []string{"v32", "v33"}}})
+
+	_, err = rdx.ReplaceValuesDiff("unknown", "k1")
+	testo.Error(t, err, true)
+
+	testo.Error(t, reduxCleanup("a1"), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -20,6 +20,7 @@ func TestReduxWriteConnect(t *testing.T) {
 		testo.Nil(t, rdx, false)
 		testo.Error(t, rdx.MustHave(asset), false)
 
+		testo.Error(t, closeRedux(rdx), false)
 		testo.Error(t, reduxCleanup(asset), false)
 	}
 }
@@ -75,3 +76,28 @@ func TestReduxReplaceValues(t *testing.T) {
 		})
 	}
 }
+
+func TestRedux_SkipsNoOpWrites(t *testing.T) {
+	rdx := mockRedux()
+	kv := rdx.kv.(*keyValues)
+
+	testo.Error(t, rdx.AddValues("a1", "k1", "v11"), false)
+	testo.Error(t, rdx.BatchAddValues("a1", map[string][]string{"k2": {"v21", "v22"}}), false)
+	testo.Error(t, rdx.ReplaceValues("a1", "k3", "v31", "v32", "v33"), false)
+	testo.Error(t, rdx.BatchReplaceValues("a2", map[string][]string{"k4": {"v41", "v42", "v43", "v44"}}), false)
+	testo.Error(t, rdx.CutValues("a1", "k1", "v-that-doesnt-exist"), false)
+	testo.Error(t, rdx.BatchCutValues("a1", map[string][]string{"k-that-doesnt-exist": {"v11"}}), false)
+	testo.Error(t, rdx.CutKeys("a2", "k-that-doesnt-exist"), false)
+
+	testo.EqualValues(t, kv.Stats().Sets, int64(0))
+
+	// values order is significant (e.g. for GetLastVal)
+	testo.Error(t, rdx.ReplaceValues("a1", "k2", "v22", "v21"), false)
+	testo.Error(t, rdx.BatchAddValues("a1", map[string][]string{"k1": {"v11"}, "k2": {"v23", "v23"}}), false)
+	testo.DeepEqual(t, rdx.akv["a1"]["k2"], []string{"v22", "v21", "v23"})
+
+	testo.EqualValues(t, kv.Stats().Sets, int64(2))
+
+	testo.Error(t, reduxCleanup("a1"), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,16 @@
+package kevlar
+
+const refreshAfterFilename = "_refresh.gob"
+
+// SetRefreshAfter annotates the key with a timestamp after which the value
+// should be refreshed (e.g. fetched again from the remote source). Negative
+// timestamp removes the annotation
+func (kv *keyValues) SetRefreshAfter(key string, ts int64) error {
+	return kv.setTimestamp(refreshAfterFilename, key, ts)
+}
+
+// DueForRefresh returns keys with refresh after annotation before or
+// equal to the provided timestamp
+func (kv *keyValues) DueForRefresh(ts int64) ([]string, error) {
+	return kv.keysBefore(refreshAfterFilename, ts)
+}
@@ -0,0 +1,55 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_DueForRefresh(t *testing.T) {
+	ikv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	kv, ok := ikv.(*keyValues)
+	testo.EqualValues(t, ok, true)
+
+	for _, key := range []string{"1", "2", "3"} {
+		testo.Error(t, kv.Set(key, strings.NewReader(key)), false)
+	}
+
+	testo.Error(t, kv.SetRefreshAfter("1", 10), false)
+	testo.Error(t, kv.SetRefreshAfter("2", 20), false)
+	testo.Error(t, kv.SetRefreshAfter("3", 30), false)
+	testo.Error(t, kv.SetRefreshAfter("key-that-doesnt-exist", 0), false)
+
+	due, err := kv.DueForRefresh(5)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(due), 0)
+
+	due, err = kv.DueForRefresh(20)
+	testo.Error(t, err, false)
+	sort.Strings(due)
+	testo.DeepEqual(t, due, []string{"1", "2"})
+
+	testo.Error(t, kv.SetRefreshAfter("1", -1), false)
+	ok, err = kv.Cut("2")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	due, err = kv.DueForRefresh(30)
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, due, []string{"3"})
+
+	for _, key := range []string{"1", "3"} {
+		ok, err = kv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, ikv.Close(), false)
+	testo.Error(t, os.Remove(kv.absTimestampsFilename(refreshAfterFilename)), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,34 @@
+package kevlar
+
+import "sync"
+
+var (
+	registry    = make(map[string]KeyValues)
+	registryMtx = new(sync.RWMutex)
+)
+
+// Register makes key values available by name, so that applications can
+// connect storage once at startup and resolve it with Lookup where it's
+// needed. Same as database/sql.Register, it panics when kv is nil or
+// when called twice with the same name
+func Register(name string, kv KeyValues) {
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+
+	if kv == nil {
+		panic("kevlar: Register key values are nil")
+	}
+	if _, ok := registry[name]; ok {
+		panic("kevlar: Register called twice for " + name)
+	}
+	registry[name] = kv
+}
+
+// Lookup returns key values registered with the name
+func Lookup(name string) (KeyValues, bool) {
+	registryMtx.RLock()
+	defer registryMtx.RUnlock()
+
+	kv, ok := registry[name]
+	return kv, ok
+}
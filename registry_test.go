@@ -0,0 +1,24 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"testing"
+)
+
+func TestRegisterLookup(t *testing.T) {
+	kv := mockKeyValues()
+
+	_, ok := Lookup("registry-test")
+	testo.EqualValues(t, ok, false)
+
+	Register("registry-test", kv)
+
+	rkv, ok := Lookup("registry-test")
+	testo.EqualValues(t, ok, true)
+	testo.EqualValues(t, rkv, KeyValues(kv))
+
+	defer func() {
+		testo.EqualValues(t, recover() != nil, true)
+	}()
+	Register("registry-test", kv)
+}
@@ -0,0 +1,58 @@
+package kevlar
+
+import (
+	"html/template"
+	"io"
+)
+
+type cachedTemplate struct {
+	hash string
+	tmpl *template.Template
+}
+
+// RenderValue treats the value as html/template source and executes it
+// with the provided data. Parsed templates are cached until value hash changes
+func (kv *keyValues) RenderValue(key string, data any, w io.Writer) error {
+	hash, err := kv.currentHash(key)
+	if err != nil {
+		return err
+	}
+
+	kv.mtx.Lock()
+	ct, ok := kv.templates[key]
+	kv.mtx.Unlock()
+
+	if !ok || ct.hash != hash || hash == "" {
+		tmpl, err := kv.parseTemplate(key)
+		if err != nil {
+			return err
+		}
+		ct = cachedTemplate{hash: hash, tmpl: tmpl}
+
+		kv.mtx.Lock()
+		if kv.templates == nil {
+			kv.templates = make(map[string]cachedTemplate)
+		}
+		kv.templates[key] = ct
+		kv.mtx.Unlock()
+	} else {
+		kv.stats.cacheHits.Add(1)
+	}
+
+	return ct.tmpl.Execute(w, data)
+}
+
+func (kv *keyValues) parseTemplate(key string) (*template.Template, error) {
+	rc, err := kv.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	bts, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	return template.New(key).Parse(string(bts))
+}
@@ -0,0 +1,43 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_RenderValue(t *testing.T) {
+	ikv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), HtmlExt)
+	testo.Error(t, err, false)
+
+	kv, ok := ikv.(*keyValues)
+	testo.EqualValues(t, ok, true)
+
+	testo.Error(t, kv.Set("template", strings.NewReader("<p>{{.}}</p>")), false)
+
+	sb := new(strings.Builder)
+	testo.Error(t, kv.RenderValue("template", "1", sb), false)
+	testo.EqualValues(t, sb.String(), "<p>1</p>")
+
+	hash, err := kv.currentHash("template")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, kv.templates["template"].hash, hash)
+
+	// changing template should invalidate cached template
+	testo.Error(t, kv.Set("template", strings.NewReader("<b>{{.}}</b>")), false)
+
+	sb.Reset()
+	testo.Error(t, kv.RenderValue("template", "<2>", sb), false)
+	testo.EqualValues(t, sb.String(), "<b>&lt;2&gt;</b>")
+
+	testo.Error(t, kv.RenderValue("template-that-doesnt-exist", nil, sb), true)
+
+	ok, err = kv.Cut("template")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, ikv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,151 @@
+package kevlar
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// RetentionRule specifies how long values with keys starting with
+// Prefix are kept after they've been last created or updated and how
+// many versions of them are kept
+type RetentionRule struct {
+	Prefix string
+	// MaxAge is the age after which values are cut, zero keeps values of any age
+	MaxAge time.Duration
+	// MaxVersions is the number of the latest versions of the key (see
+	// AppendVersion) that are kept, zero keeps all versions. The limit is
+	// applied by ApplyRetention only: writes of new versions are never
+	// rejected or skipped, older versions are cut when retention is applied
+	MaxVersions int
+}
+
+// ApplyRetention cuts values that are older than the MaxAge and versions
+// beyond the MaxVersions of the matching rule and returns cut keys. When
+// several rules match the key, the one with the longest prefix is applied.
+// Keys that don't match any rule and pinned keys (see Pin) are kept
+func (kv *keyValues) ApplyRetention(rules ...RetentionRule) ([]string, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	lmts, err := kv.lastModTimes()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expired := make([]string, 0)
+	retained := make([]string, 0, len(lmts))
+
+	for key, lmt := range lmts {
+		if rule, ok := matchingRetentionRule(key, rules); ok &&
+			rule.MaxAge != 0 &&
+			now.Sub(time.Unix(lmt, 0)) > rule.MaxAge {
+			expired = append(expired, key)
+		} else {
+			retained = append(retained, key)
+		}
+	}
+
+	kv.mtx.Lock()
+	versions, err := kv.loadTimestamps(versionsFilename)
+	kv.mtx.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	expired = append(expired, excessVersions(retained, versions, rules)...)
+	slices.Sort(expired)
+
+	if expired, err = kv.unpinned(expired); err != nil {
+		return nil, err
+	}
+
+	cut := make([]string, 0, len(expired))
+	for _, key := range expired {
+		if ok, err := kv.Cut(key); err != nil {
+			return cut, err
+		} else if ok {
+			cut = append(cut, key)
+		}
+	}
+
+	return cut, nil
+}
+
+// excessVersions returns version keys that exceed the MaxVersions of the
+// rule matching the key these are versions of. Only keys set with
+// AppendVersion have version numbers, the key itself is the first version
+// even when it's been set otherwise
+func excessVersions(keys []string, versions timestamps, rules []RetentionRule) []string {
+	existing := make(map[string]bool, len(keys))
+	keyVersions := make(map[string][]int64)
+	for _, key := range keys {
+		existing[key] = true
+		if n, ok := versions[key]; ok {
+			vk := versionedKey(key, n)
+			keyVersions[vk] = append(keyVersions[vk], n)
+		}
+	}
+
+	excess := make([]string, 0)
+	for key, ns := range keyVersions {
+		if existing[key] && !slices.Contains(ns, 1) {
+			ns = append(ns, 1)
+		}
+
+		rule, ok := matchingRetentionRule(key, rules)
+		if !ok || rule.MaxVersions <= 0 || len(ns) <= rule.MaxVersions {
+			continue
+		}
+		slices.Sort(ns)
+		for _, n := range ns[:len(ns)-rule.MaxVersions] {
+			excess = append(excess, VersionKey(key, int(n)))
+		}
+	}
+
+	return excess
+}
+
+// lastModTimes returns the last create or update timestamp for every
+// key that hasn't been cut
+func (kv *keyValues) lastModTimes() (map[string]int64, error) {
+	if err := kv.refreshLogRecords(); err != nil {
+		return nil, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	lmts := make(map[string]int64)
+	for _, lr := range kv.log {
+		switch lr.Mt {
+		case create:
+			fallthrough
+		case update:
+			if lr.Ts > lmts[lr.Id] {
+				lmts[lr.Id] = lr.Ts
+			}
+		case cut:
+			delete(lmts, lr.Id)
+		}
+	}
+
+	return lmts, nil
+}
+
+func matchingRetentionRule(key string, rules []RetentionRule) (RetentionRule, bool) {
+	var match RetentionRule
+	found := false
+	for _, rule := range rules {
+		if !strings.HasPrefix(key, rule.Prefix) {
+			continue
+		}
+		if !found || len(rule.Prefix) > len(match.Prefix) {
+			match = rule
+			found = true
+		}
+	}
+	return match, found
+}
@@ -0,0 +1,151 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchingRetentionRule(t *testing.T) {
+	rules := []RetentionRule{
+		{Prefix: "cache/", MaxAge: time.Hour},
+		{Prefix: "cache/long/", MaxAge: 24 * time.Hour},
+		{Prefix: "", MaxAge: 365 * 24 * time.Hour},
+	}
+
+	tests := []struct {
+		key    string
+		prefix string
+	}{
+		{"cache/1", "cache/"},
+		{"cache/long/1", "cache/long/"},
+		{"metadata/1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			rule, ok := matchingRetentionRule(tt.key, rules)
+			testo.EqualValues(t, ok, true)
+			testo.EqualValues(t, rule.Prefix, tt.prefix)
+		})
+	}
+
+	_, ok := matchingRetentionRule("key", rules[:2])
+	testo.EqualValues(t, ok, false)
+}
+
+func TestKeyValues_ApplyRetention(t *testing.T) {
+	kv := mockKeyValues()
+
+	cut, err := kv.ApplyRetention()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(cut), 0)
+
+	cut, err = kv.ApplyRetention(RetentionRule{Prefix: "2", MaxAge: time.Hour})
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, cut, []string{"2"})
+
+	keys, err := kv.Keys()
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, keys, []string{"3"})
+
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_ApplyRetentionMaxVersions(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	for ii := 0; ii < 4; ii++ {
+		_, err = kv.SetWithPolicy("doc", strings.NewReader("{}"), AppendVersion, 0)
+		testo.Error(t, err, false)
+	}
+	testo.Error(t, kv.Set("other", strings.NewReader("{}")), false)
+
+	cut, err := kv.ApplyRetention(RetentionRule{Prefix: "doc", MaxVersions: 2})
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, cut, []string{"doc", "doc~2"})
+
+	// versions beyond the limit are still set
+	key, err := kv.SetWithPolicy("doc", strings.NewReader("{}"), AppendVersion, 0)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, key, "doc~5")
+
+	keys, err := kv.Keys()
+	testo.Error(t, err, false)
+	slices.Sort(keys)
+	testo.DeepEqual(t, keys, []string{"doc~3", "doc~4", "doc~5", "other"})
+
+	_, err = kv.CutMany(keys...)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_ApplyRetentionKeepsKeysThatLookLikeVersions(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	// a~2 is set directly, not as a version of a
+	testo.Error(t, kv.Set("a~2", strings.NewReader("{}")), false)
+	testo.Error(t, kv.Set("a", strings.NewReader("{}")), false)
+
+	for _, exp := range []string{"a~3", "a~4"} {
+		key, err := kv.SetWithPolicy("a", strings.NewReader("{}"), AppendVersion, 0)
+		testo.Error(t, err, false)
+		testo.EqualValues(t, key, exp)
+	}
+
+	cut, err := kv.ApplyRetention(RetentionRule{Prefix: "a", MaxVersions: 2})
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, cut, []string{"a"})
+
+	keys, err := kv.Keys()
+	testo.Error(t, err, false)
+	slices.Sort(keys)
+	testo.DeepEqual(t, keys, []string{"a~2", "a~3", "a~4"})
+
+	// cut versions are forgotten, so keys set again later are not versions
+	testo.Error(t, kv.Set("a~3", strings.NewReader("{}")), false)
+	_, err = kv.Cut("a~3")
+	testo.Error(t, err, false)
+	testo.Error(t, kv.Set("a~3", strings.NewReader("{}")), false)
+
+	cut, err = kv.ApplyRetention(RetentionRule{Prefix: "a", MaxVersions: 1})
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, cut, []string{})
+
+	_, err = kv.CutMany("a~2", "a~3", "a~4")
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyVersion(t *testing.T) {
+	tests := []struct {
+		versionKey string
+		key        string
+		n          int
+	}{
+		{"key", "key", 1},
+		{"key~2", "key", 2},
+		{"key~10", "key", 10},
+		{"key~1", "key~1", 1},
+		{"key~latest", "key~latest", 1},
+		{"~2", "~2", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.versionKey, func(t *testing.T) {
+			key, n := keyVersion(tt.versionKey)
+			testo.EqualValues(t, key, tt.key)
+			testo.EqualValues(t, n, tt.n)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package kevlar
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+// IsTransient is the default retry classifier. It reports errors that
+// are likely to succeed when retried, e.g. interrupted system calls or
+// network filesystem blips
+func IsTransient(err error) bool {
+	for _, errno := range []syscall.Errno{
+		syscall.EAGAIN,
+		syscall.EBUSY,
+		syscall.EINTR,
+		syscall.ESTALE,
+		syscall.ETIMEDOUT,
+	} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
+
+// retry calls fn until it succeeds, the error is not retryable or the
+// number of attempts is exhausted (see WithRetry). Backoff is doubled
+// after every attempt
+func (kv *keyValues) retry(fn func() error) error {
+	err := fn()
+
+	backoff := kv.retryBackoff
+	for attempt := 1; attempt < kv.retryAttempts && err != nil; attempt++ {
+		retryable := IsTransient
+		if kv.retryClassifier != nil {
+			retryable = kv.retryClassifier
+		}
+		if !retryable(err) {
+			return err
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+
+		err = fn()
+	}
+
+	return err
+}
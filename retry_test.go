@@ -0,0 +1,72 @@
+package kevlar
+
+import (
+	"errors"
+	"fmt"
+	"github.com/boggydigital/testo"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err error
+		exp bool
+	}{
+		{syscall.EBUSY, true},
+		{&os.PathError{Op: "open", Path: "value", Err: syscall.EINTR}, true},
+		{fmt.Errorf("wrapped: %w", syscall.ESTALE), true},
+		{os.ErrNotExist, false},
+		{errors.New("permanent"), false},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			testo.EqualValues(t, IsTransient(tt.err), tt.exp)
+		})
+	}
+}
+
+func TestKeyValues_Retry(t *testing.T) {
+	errPermanent := errors.New("permanent")
+
+	tests := []struct {
+		attempts int
+		failures []error
+		classify func(error) bool
+		expCalls int
+		expErr   bool
+	}{
+		// no retry by default
+		{0, []error{syscall.EBUSY}, nil, 1, true},
+		{3, []error{syscall.EBUSY, syscall.EINTR}, nil, 3, false},
+		{3, []error{syscall.EBUSY, syscall.EBUSY, syscall.EBUSY}, nil, 3, true},
+		{3, []error{errPermanent}, nil, 1, true},
+		{3, []error{errPermanent}, func(err error) bool { return err == errPermanent }, 2, false},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			kv := &keyValues{
+				retryAttempts:   tt.attempts,
+				retryBackoff:    time.Millisecond,
+				retryClassifier: tt.classify,
+			}
+
+			calls := 0
+			err := kv.retry(func() error {
+				calls++
+				if calls <= len(tt.failures) {
+					return tt.failures[calls-1]
+				}
+				return nil
+			})
+
+			testo.Error(t, err, tt.expErr)
+			testo.EqualValues(t, calls, tt.expCalls)
+		})
+	}
+}
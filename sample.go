@@ -0,0 +1,40 @@
+package kevlar
+
+import "math/rand/v2"
+
+// SampleKeys returns up to n keys chosen at random, e.g. for diagnostics
+// or smoke tests. Keys are sampled from the index without copying all of
+// them. All keys are returned (in random order) when there are less than n keys
+func (kv *keyValues) SampleKeys(n int) ([]string, error) {
+	if err := kv.refreshLogRecords(); err != nil {
+		return nil, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	n = max(min(n, len(kv.keys)), 0)
+	sample := make([]string, 0, n)
+	if n == 0 {
+		return sample, nil
+	}
+
+	// reservoir sampling - every key replaces a sampled one
+	// with the probability of n / keys seen so far
+	seen := 0
+	for key := range kv.keys {
+		seen++
+		if len(sample) < n {
+			sample = append(sample, key)
+		} else if ii := rand.IntN(seen); ii < n {
+			sample[ii] = key
+		}
+	}
+
+	// keys are sampled in the map order, shuffle them
+	rand.Shuffle(len(sample), func(ii, jj int) {
+		sample[ii], sample[jj] = sample[jj], sample[ii]
+	})
+
+	return sample, nil
+}
@@ -0,0 +1,53 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"golang.org/x/exp/slices"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_SampleKeys(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	keys := []string{"1", "2", "3", "4", "5"}
+	for _, key := range keys {
+		testo.Error(t, kv.Set(key, strings.NewReader(key)), false)
+	}
+
+	tests := []struct {
+		n   int
+		exp int
+	}{
+		{-1, 0},
+		{0, 0},
+		{3, 3},
+		{5, 5},
+		{10, 5},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			sample, err := kv.SampleKeys(tt.n)
+			testo.Error(t, err, false)
+			testo.EqualValues(t, len(sample), tt.exp)
+			for jj, key := range sample {
+				testo.EqualValues(t, slices.Contains(keys, key), true)
+				testo.EqualValues(t, slices.Contains(sample[:jj], key), false)
+			}
+		})
+	}
+
+	for _, key := range keys {
+		ok, err := kv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,38 @@
+package kevlar
+
+import (
+	"io/fs"
+	"strings"
+)
+
+// SeedFrom sets values from files in the filesystem (e.g. embed.FS) that have
+// storage extension. Keys are file paths without extension. Only missing keys
+// are set, unless overwrite is requested
+func (kv *keyValues) SeedFrom(fsys fs.FS, overwrite bool) error {
+	return fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, kv.ext) {
+			return nil
+		}
+
+		key := strings.TrimSuffix(path, kv.ext)
+
+		if !overwrite {
+			if ok, err := kv.Has(key); err != nil {
+				return err
+			} else if ok {
+				return nil
+			}
+		}
+
+		file, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		return kv.Set(key, file)
+	})
+}
@@ -0,0 +1,52 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestKeyValues_SeedFrom(t *testing.T) {
+	fsys := fstest.MapFS{
+		"defaults.json":       {Data: []byte(`{"default":true}`)},
+		"nested/values.json":  {Data: []byte(`[]`)},
+		"ignored.txt":         {Data: []byte(`ignored`)},
+		"nested/ignored.html": {Data: []byte(`<html></html>`)},
+	}
+
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), JsonExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("defaults", strings.NewReader(`{"default":false}`)), false)
+
+	testo.Error(t, kv.SeedFrom(fsys, false), false)
+
+	keys, err := kv.Keys()
+	testo.Error(t, err, false)
+	sort.Strings(keys)
+	testo.DeepEqual(t, keys, []string{"defaults", "nested/values"})
+
+	// existing values are kept unless overwrite is requested
+	preview, err := Preview(kv, "defaults", 1024)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, string(preview), `{"default":false}`)
+
+	testo.Error(t, kv.SeedFrom(fsys, true), false)
+
+	preview, err = Preview(kv, "defaults", 1024)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, string(preview), `{"default":true}`)
+
+	for _, key := range keys {
+		ok, err := kv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,71 @@
+package kevlar
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// SetArchive sets values from a zip archive, e.g. created with GetArchive.
+// Keys are stored in entry comments by GetArchive. Entries without comments
+// (e.g. archives created with other tools) use filenames without storage
+// extension as keys. Filenames must have storage extension. By default the first
+// entry that can't be read or set aborts the import. When skipCorrupt is set,
+// such entries are skipped and returned with the reasons they were skipped
+func (kv *keyValues) SetArchive(r io.ReaderAt, size int64, skipCorrupt bool) (map[string]error, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	skipped := make(map[string]error)
+
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+
+		if err := kv.setArchiveValue(zf); err != nil {
+			if !skipCorrupt {
+				return skipped, fmt.Errorf("%s: %w", zf.Name, err)
+			}
+			skipped[zf.Name] = err
+		}
+	}
+
+	return skipped, nil
+}
+
+func (kv *keyValues) setArchiveValue(zf *zip.File) error {
+	if !strings.HasSuffix(zf.Name, kv.ext) {
+		return fmt.Errorf("kevlar: archive file extension doesn't match %s", kv.ext)
+	}
+	key := archiveFileKey(&zf.FileHeader)
+
+	rc, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	// zip checksum is verified when the entry has been read completely
+	// and Set doesn't write values when reading fails
+	return kv.Set(key, rc)
+}
+
+// archiveFileKey returns the key of the archive entry stored in the comment
+// by GetArchive, or the entry filename without extension otherwise
+func archiveFileKey(fh *zip.FileHeader) string {
+	if fh.Comment != "" {
+		return fh.Comment
+	}
+	return archiveEntryKey(fh.Name)
+}
+
+// archiveEntryKey returns the key of the archive entry or seeded file,
+// that is the filename without storage extension
+func archiveEntryKey(name string) string {
+	return strings.TrimSuffix(name, path.Ext(name))
+}
@@ -0,0 +1,96 @@
+package kevlar
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func mockArchive(t *testing.T, files map[string]string) []byte {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for name, content := range files {
+		// stored (uncompressed) entries allow tampering with content below
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		testo.Error(t, err, false)
+		_, err = fw.Write([]byte(content))
+		testo.Error(t, err, false)
+	}
+	testo.Error(t, zw.Close(), false)
+	return buf.Bytes()
+}
+
+func TestKeyValues_SetArchive(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), JsonExt)
+	testo.Error(t, err, false)
+
+	archive := mockArchive(t, map[string]string{
+		"1.json":    "{}",
+		"2.txt":     "wrong extension",
+		"3.json":    "\"corrupt\"",
+		"a..b.json": "\"invalid key\"",
+	})
+	// break checksum of the 3.json entry
+	archive = bytes.Replace(archive, []byte("\"corrupt\""), []byte("\"CORRUPT\""), 1)
+
+	_, err = kv.SetArchive(bytes.NewReader(archive), int64(len(archive)), false)
+	testo.Error(t, err, true)
+
+	skipped, err := kv.SetArchive(bytes.NewReader(archive), int64(len(archive)), true)
+	testo.Error(t, err, false)
+
+	skippedNames := make([]string, 0, len(skipped))
+	for name, err := range skipped {
+		testo.Error(t, err, true)
+		skippedNames = append(skippedNames, name)
+	}
+	sort.Strings(skippedNames)
+	testo.DeepEqual(t, skippedNames, []string{"2.txt", "3.json", "a..b.json"})
+
+	keys, err := kv.Keys()
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, keys, []string{"1"})
+
+	ok, err := kv.Cut("1")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_SetArchiveKeepsKeys(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), JsonExt)
+	testo.Error(t, err, false)
+
+	keys := []string{"user/1.avatar", "1"}
+	for ii, key := range keys {
+		testo.Error(t, kv.Set(key, strings.NewReader(strconv.Itoa(ii))), false)
+	}
+
+	buf := new(bytes.Buffer)
+	testo.Error(t, kv.GetArchive(buf, keys...), false)
+
+	_, err = kv.CutMany(keys...)
+	testo.Error(t, err, false)
+
+	skipped, err := kv.SetArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()), false)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(skipped), 0)
+
+	for ii, key := range keys {
+		testo.EqualValues(t, readValue(t, kv, key), strconv.Itoa(ii))
+	}
+
+	_, err = kv.CutMany(keys...)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,162 @@
+package kevlar
+
+import (
+	"crypto/sha256"
+	"errors"
+	"os"
+	"syscall"
+)
+
+// SetFromFile sets the value from the file at the provided path. The file is
+// hashed in place and, when move is requested, renamed into storage (copied
+// and removed when it's on a different device). Otherwise the file is copied
+// and left unchanged
+func (kv *keyValues) SetFromFile(key, path string, move bool) error {
+	span := kv.startSpan(opSet, key)
+
+	n, err := kv.setFromFile(key, path, move)
+	if err == nil {
+		kv.stats.sets.Add(1)
+		kv.stats.bytesIn.Add(n)
+	}
+
+	span.SetBytes(n)
+	span.End(err)
+	return err
+}
+
+func (kv *keyValues) setFromFile(key, path string, move bool) (int64, error) {
+	done, err := kv.mutating()
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+
+	if err := kv.validateKey(key); err != nil {
+		return 0, err
+	}
+
+	if err := kv.sniffFile(key, path); err != nil {
+		return 0, err
+	}
+
+	if kv.conflictPolicy != Overwrite {
+		return kv.setFromFileWithStoragePolicy(key, path, move)
+	}
+
+	unlock := kv.lockKey(key)
+	defer unlock()
+
+	release, err := kv.reserveKey(key)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	size := fi.Size()
+
+	var fh string
+	if kv.fastHash {
+		if fh, err = fastHashFile(path, size); err != nil {
+			return size, err
+		}
+		if currentFastHash, err := kv.currentFastHash(key); err != nil {
+			return size, err
+		} else if fh == currentFastHash {
+			kv.stats.unchangedSets.Add(1)
+			return size, removeIfMoved(path, move)
+		}
+	}
+
+	hash, err := hashFile(sha256.New(), path)
+	if err != nil {
+		return size, err
+	}
+
+	currentHash, err := kv.currentHash(key)
+	if err != nil {
+		return size, err
+	}
+
+	// the latest value is already set
+	if hash == currentHash {
+		kv.stats.unchangedSets.Add(1)
+		if kv.fastHash {
+			if err := kv.createFastHashFile(key, fh); err != nil {
+				return size, err
+			}
+		}
+		return size, removeIfMoved(path, move)
+	}
+
+	if err := kv.placeFile(key, path, move); err != nil {
+		return size, err
+	}
+
+	if err := kv.createHashFile(key, hash); err != nil {
+		return size, err
+	}
+
+	if kv.fastHash {
+		if err := kv.createFastHashFile(key, fh); err != nil {
+			return size, err
+		}
+	}
+
+	return size, kv.committed(kv.createOrUpdateLogRecord(key, size))
+}
+
+// setFromFileWithStoragePolicy sets the value from the file with the storage
+// conflict policy (see WithConflictPolicy). The file is read instead of
+// renamed, since the policy might keep the existing value
+func (kv *keyValues) setFromFileWithStoragePolicy(key, path string, move bool) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := kv.setWithStoragePolicy(key, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return size, err
+	}
+
+	return size, removeIfMoved(path, move)
+}
+
+func (kv *keyValues) placeFile(key, path string, move bool) error {
+	absValueFilename := kv.absValueFilename(key)
+
+	if move {
+		if err := os.Rename(path, absValueFilename); err == nil {
+			return nil
+		} else if !errors.Is(err, syscall.EXDEV) {
+			return err
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := kv.writeStaged(absValueFilename, file); err != nil {
+		return err
+	}
+
+	return removeIfMoved(path, move)
+}
+
+func removeIfMoved(path string, move bool) error {
+	if move {
+		return os.Remove(path)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_SetFromFile(t *testing.T) {
+	tests := []struct {
+		move     bool
+		fastHash bool
+	}{
+		{false, false},
+		{true, false},
+		{false, true},
+		{true, true},
+	}
+
+	dir := filepath.Join(os.TempDir(), testsDirname)
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			options := make([]KeyValuesOption, 0)
+			if tt.fastHash {
+				options = append(options, WithFastHash())
+			}
+
+			kv, err := NewKeyValues(dir, JsonExt, options...)
+			testo.Error(t, err, false)
+
+			path := filepath.Join(dir, "set_from_file")
+			testo.Error(t, os.WriteFile(path, []byte("{}"), 0644), false)
+
+			testo.Error(t, kv.SetFromFile("from-file", path, tt.move), false)
+
+			_, err = os.Stat(path)
+			testo.EqualValues(t, os.IsNotExist(err), tt.move)
+
+			rc, err := kv.Get("from-file")
+			testo.Error(t, err, false)
+			sb := new(strings.Builder)
+			_, err = io.Copy(sb, rc)
+			testo.Error(t, err, false)
+			testo.Error(t, rc.Close(), false)
+			testo.EqualValues(t, sb.String(), "{}")
+
+			// setting the same content again shouldn't add log records
+			testo.Error(t, os.WriteFile(path, []byte("{}"), 0644), false)
+			testo.Error(t, kv.SetFromFile("from-file", path, tt.move), false)
+			updated, err := kv.UpdatedAfter(0)
+			testo.Error(t, err, false)
+			testo.EqualValues(t, len(updated), 0)
+
+			if !tt.move {
+				testo.Error(t, os.Remove(path), false)
+			}
+
+			ok, err := kv.Cut("from-file")
+			testo.EqualValues(t, ok, true)
+			testo.Error(t, err, false)
+
+			testo.Error(t, kv.Close(), false)
+			testo.Error(t, logRecordsCleanup(), false)
+		})
+	}
+}
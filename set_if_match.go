@@ -0,0 +1,45 @@
+package kevlar
+
+import (
+	"errors"
+	"io"
+)
+
+var errHashMismatch = errors.New("kevlar: hash mismatch")
+
+type setIfMatchResult struct {
+	size    int64
+	matched bool
+}
+
+// SetIfMatch sets the value same as Set, but only when the SHA-256 hash of
+// the current value matches the expected hash (e.g. returned by GetRecord),
+// so that concurrent writers can detect lost updates. Empty expected hash
+// matches keys that don't exist. Returns false when the hash didn't match
+// and the value hasn't been set
+func (kv *keyValues) SetIfMatch(key string, reader io.Reader, expectedHash string) (bool, error) {
+	span := kv.startSpan(opSet, key)
+
+	result, err := withTimeout(kv, opSet, key, func(op *operation) (setIfMatchResult, error) {
+		return kv.setIfMatch(key, op.reader(reader), expectedHash)
+	}, nil)
+	if result.matched && err == nil {
+		kv.stats.sets.Add(1)
+		kv.stats.bytesIn.Add(result.size)
+	}
+
+	span.SetBytes(result.size)
+	span.End(err)
+	return result.matched, err
+}
+
+func (kv *keyValues) setIfMatch(key string, reader io.Reader, expectedHash string) (setIfMatchResult, error) {
+	done, err := kv.mutating()
+	if err != nil {
+		return setIfMatchResult{}, err
+	}
+	defer done()
+
+	size, matched, seq, err := kv.setValueIf(key, reader, &expectedHash)
+	return setIfMatchResult{size: size, matched: matched}, kv.committed(seq, err)
+}
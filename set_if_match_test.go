@@ -0,0 +1,99 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestKeyValues_SetIfMatch(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	// empty hash matches keys that don't exist
+	ok, err := kv.SetIfMatch("cas", strings.NewReader("1"), "")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, ok, true)
+
+	ok, err = kv.SetIfMatch("cas", strings.NewReader("2"), "")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, ok, false)
+	testo.EqualValues(t, readValue(t, kv, "cas"), "1")
+
+	ok, err = kv.SetIfMatch("cas", strings.NewReader("2"), sha256Hex([]byte("1")))
+	testo.Error(t, err, false)
+	testo.EqualValues(t, ok, true)
+	testo.EqualValues(t, readValue(t, kv, "cas"), "2")
+
+	// stale hash doesn't match
+	ok, err = kv.SetIfMatch("cas", strings.NewReader("3"), sha256Hex([]byte("1")))
+	testo.Error(t, err, false)
+	testo.EqualValues(t, ok, false)
+	testo.EqualValues(t, readValue(t, kv, "cas"), "2")
+
+	ok, err = kv.Cut("cas")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_SetIfMatchNoLostUpdates(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	const workers, increments = 4, 25
+
+	testo.Error(t, kv.Set("counter", strings.NewReader("0")), false)
+
+	wg := new(sync.WaitGroup)
+	errs := make(chan error, workers)
+
+	for ww := 0; ww < workers; ww++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ii := 0; ii < increments; {
+				data, err := os.ReadFile(kv.(*keyValues).absValueFilename("counter"))
+				if err != nil {
+					errs <- err
+					return
+				}
+				counter, err := strconv.Atoi(string(data))
+				if err != nil {
+					errs <- err
+					return
+				}
+				ok, err := kv.SetIfMatch("counter", strings.NewReader(strconv.Itoa(counter+1)), sha256Hex(data))
+				if err != nil {
+					errs <- err
+					return
+				}
+				if ok {
+					ii++
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		testo.Error(t, err, false)
+	}
+
+	testo.EqualValues(t, readValue(t, kv, "counter"), strconv.Itoa(workers*increments))
+
+	ok, err := kv.Cut("counter")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,50 @@
+package kevlar
+
+import (
+	"fmt"
+	"io"
+)
+
+// SetMany sets values same as Set, but writes log records once for all of
+// them, instead of once per value (unless the storage conflict policy is
+// not Overwrite). Values that have been set before an error are kept and
+// their log records are written
+func (kv *keyValues) SetMany(keyReaders map[string]io.Reader) error {
+	done, err := kv.mutating()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	var lastSeq uint64
+	for key, reader := range keyReaders {
+		span := kv.startSpan(opSet, key)
+
+		var n int64
+		var seq uint64
+		if kv.conflictPolicy != Overwrite {
+			// values might not be set to the key, commit each of them
+			n, err = kv.setWithStoragePolicy(key, reader)
+		} else {
+			n, seq, err = kv.setValue(key, reader)
+		}
+		if err == nil {
+			kv.stats.sets.Add(1)
+			kv.stats.bytesIn.Add(n)
+		}
+
+		span.SetBytes(n)
+		span.End(err)
+
+		if err != nil {
+			if commitErr := kv.committed(lastSeq, nil); commitErr != nil {
+				return commitErr
+			}
+			return fmt.Errorf("%s: %w", key, err)
+		}
+
+		lastSeq = max(lastSeq, seq)
+	}
+
+	return kv.committed(lastSeq, nil)
+}
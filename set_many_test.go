@@ -0,0 +1,53 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_SetMany(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname, "set-many")
+	testo.Error(t, os.MkdirAll(dir, 0755), false)
+
+	logWrites := 0
+	kv, err := NewKeyValues(dir, GobExt, WithAfterLogWrite(func(string) error {
+		logWrites++
+		return nil
+	}))
+	testo.Error(t, err, false)
+
+	keyReaders := make(map[string]io.Reader)
+	for ii := 0; ii < 20; ii++ {
+		keyReaders[strconv.Itoa(ii)] = strings.NewReader(strconv.Itoa(ii))
+	}
+
+	testo.Error(t, kv.SetMany(keyReaders), false)
+	testo.EqualValues(t, logWrites, 1)
+
+	// errors are reported with the key
+	err = kv.SetMany(map[string]io.Reader{"..": strings.NewReader("invalid")})
+	testo.EqualValues(t, errors.Is(err, ErrInvalidKey), true)
+
+	ckv, err := connect(dir, GobExt)
+	testo.Error(t, err, false)
+
+	keys, err := ckv.Keys()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(keys), 20)
+
+	rc, err := ckv.Get("7")
+	testo.Error(t, err, false)
+	data, err := io.ReadAll(rc)
+	testo.Error(t, err, false)
+	testo.Error(t, rc.Close(), false)
+	testo.EqualValues(t, string(data), "7")
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, os.RemoveAll(dir), false)
+}
@@ -0,0 +1,75 @@
+package kevlar
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type setWriter struct {
+	kv     *keyValues
+	key    string
+	file   *os.File
+	closed bool
+}
+
+// SetWriter returns a writer that streams the value into a staged file.
+// Closing the writer sets the value from that file same as SetFromFile:
+// the value is hashed, moved into storage if it has changed and the log
+// records are updated. Values are not set until the writer is closed
+func (kv *keyValues) SetWriter(key string) (io.WriteCloser, error) {
+	if err := kv.validateKey(key); err != nil {
+		return nil, err
+	}
+
+	dir, filename := filepath.Split(kv.absValueFilename(key))
+	if kv.stagingDir != "" {
+		dir = kv.stagingDir
+	}
+
+	var file *os.File
+	if err := kv.retry(func() (err error) {
+		file, err = os.CreateTemp(dir, "."+filename+"-*")
+		return err
+	}); err != nil {
+		return nil, noSpaceErr(err)
+	}
+
+	return &setWriter{kv: kv, key: key, file: file}, nil
+}
+
+func (sw *setWriter) Write(p []byte) (int, error) {
+	n, err := sw.file.Write(p)
+	return n, noSpaceErr(err)
+}
+
+func (sw *setWriter) Close() error {
+	if sw.closed {
+		return os.ErrClosed
+	}
+	sw.closed = true
+
+	stagedFilename := sw.file.Name()
+
+	// synced same as staged writes (see writeStaged)
+	err := sw.file.Chmod(stagedFileMode(sw.kv.absValueFilename(sw.key)))
+	if err == nil {
+		err = noSpaceErr(sw.file.Sync())
+	}
+	if closeErr := noSpaceErr(sw.file.Close()); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = sw.kv.SetFromFile(sw.key, stagedFilename, true)
+	}
+
+	if err != nil {
+		// staged file is moved or removed when the value has been set
+		if removeErr := os.Remove(stagedFilename); removeErr != nil && !os.IsNotExist(removeErr) {
+			return errors.Join(err, removeErr)
+		}
+	}
+
+	return err
+}
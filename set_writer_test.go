@@ -0,0 +1,72 @@
+package kevlar
+
+import (
+	"encoding/json"
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_SetWriter(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname)
+
+	kv, err := NewKeyValues(dir, JsonExt)
+	testo.Error(t, err, false)
+
+	wc, err := kv.SetWriter("set-writer")
+	testo.Error(t, err, false)
+
+	testo.Error(t, json.NewEncoder(wc).Encode(map[string]string{"k": "v"}), false)
+
+	// values are not set until the writer is closed
+	has, err := kv.Has("set-writer")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, has, false)
+
+	testo.Error(t, wc.Close(), false)
+	testo.EqualValues(t, wc.Close(), os.ErrClosed)
+
+	rc, err := kv.Get("set-writer")
+	testo.Error(t, err, false)
+	sb := new(strings.Builder)
+	_, err = io.Copy(sb, rc)
+	testo.Error(t, err, false)
+	testo.Error(t, rc.Close(), false)
+	testo.EqualValues(t, sb.String(), "{\"k\":\"v\"}\n")
+
+	fi, err := os.Stat(kv.(*keyValues).absValueFilename("set-writer"))
+	testo.Error(t, err, false)
+	testo.EqualValues(t, fi.Mode().Perm(), defaultFileMode)
+
+	hash, err := os.ReadFile(kv.(*keyValues).absHashFilename("set-writer"))
+	testo.Error(t, err, false)
+	testo.EqualValues(t, string(hash), sha256Hex([]byte(sb.String())))
+
+	// staged files are moved into storage
+	staged, err := filepath.Glob(filepath.Join(dir, ".set-writer*"))
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(staged), 0)
+
+	ok, err := kv.Cut("set-writer")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_SetWriterInvalidKey(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname)
+
+	kv, err := NewKeyValues(dir, JsonExt, WithMaxKeyLength(1))
+	testo.Error(t, err, false)
+
+	_, err = kv.SetWriter("too-long")
+	testo.Error(t, err, true)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -2,15 +2,86 @@ package kevlar
 
 import (
 	"crypto/sha256"
-	"fmt"
+	"encoding/hex"
+	"hash"
 	"io"
+	"os"
+	"runtime"
+	"sync"
 )
 
 func Sha256(reader io.Reader) (string, error) {
 	h := sha256.New()
 	if _, err := io.Copy(h, reader); err == nil {
-		return fmt.Sprintf("%x", h.Sum(nil)), nil
+		return hex.EncodeToString(h.Sum(nil)), nil
 	} else {
 		return "", err
 	}
 }
+
+// Sha256Tee returns a reader that reads from the provided reader and a function
+// that returns SHA-256 hash of everything that has been read so far
+func Sha256Tee(reader io.Reader) (io.Reader, func() string) {
+	h := sha256.New()
+	return io.TeeReader(reader, h), func() string {
+		return hex.EncodeToString(h.Sum(nil))
+	}
+}
+
+// HashFiles computes SHA-256 hashes of the files at the provided paths
+// concurrently, using one worker per CPU. Result is a map of path to hash
+func HashFiles(paths []string) (map[string]string, error) {
+	hashes := make(map[string]string, len(paths))
+	mtx := new(sync.Mutex)
+
+	pathsCh := make(chan string)
+	errs := make(chan error, len(paths))
+
+	wg := new(sync.WaitGroup)
+	for ii := 0; ii < runtime.NumCPU(); ii++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h := sha256.New()
+			for path := range pathsCh {
+				hash, err := hashFile(h, path)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				mtx.Lock()
+				hashes[path] = hash
+				mtx.Unlock()
+			}
+		}()
+	}
+
+	for _, path := range paths {
+		pathsCh <- path
+	}
+	close(pathsCh)
+
+	wg.Wait()
+	close(errs)
+
+	if err, ok := <-errs; ok {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+func hashFile(h hash.Hash, path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h.Reset()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -2,6 +2,9 @@ package kevlar
 
 import (
 	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -24,3 +27,45 @@ func TestSha256(t *testing.T) {
 		})
 	}
 }
+
+func TestSha256Tee(t *testing.T) {
+	reader, hash := Sha256Tee(strings.NewReader("1"))
+
+	sb := new(strings.Builder)
+	_, err := io.Copy(sb, reader)
+	testo.Error(t, err, false)
+
+	testo.EqualValues(t, sb.String(), "1")
+	testo.EqualValues(t, hash(), "6b86b273ff34fce19d6b804eff5a3f5747ada4eaa22f1d49c01e52ddb7875b4b")
+}
+
+func TestHashFiles(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname)
+	testo.Error(t, os.MkdirAll(dir, 0755), false)
+
+	contents := map[string]string{
+		"":  "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"1": "6b86b273ff34fce19d6b804eff5a3f5747ada4eaa22f1d49c01e52ddb7875b4b",
+	}
+
+	paths := make([]string, 0, len(contents))
+	for content := range contents {
+		path := filepath.Join(dir, "hash_file_"+content)
+		testo.Error(t, os.WriteFile(path, []byte(content), 0644), false)
+		paths = append(paths, path)
+	}
+
+	hashes, err := HashFiles(paths)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(hashes), len(contents))
+	for content, hash := range contents {
+		testo.EqualValues(t, hashes[filepath.Join(dir, "hash_file_"+content)], hash)
+	}
+
+	_, err = HashFiles(append(paths, filepath.Join(dir, "file-that-doesnt-exist")))
+	testo.Error(t, err, true)
+
+	for _, path := range paths {
+		testo.Error(t, os.Remove(path), false)
+	}
+}
@@ -0,0 +1,803 @@
+package kevlar
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"maps"
+	"math/rand/v2"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// shardVirtualNodes is the number of points every store has on the
+// consistent hashing ring. More points spread keys more evenly
+const shardVirtualNodes = 128
+
+var ErrNoShards = errors.New("kevlar: no stores to shard")
+
+type shardPoint struct {
+	hash  uint64
+	shard int
+}
+
+// shardedKeyValues routes keys to the underlying stores with consistent
+// hashing. Methods that don't operate on a single key are applied to every
+// store and their results are merged
+type shardedKeyValues struct {
+	stores []KeyValues
+	ring   []shardPoint
+}
+
+// ShardedKeyValues routes keys across several stores (e.g. on different
+// disks) with consistent hashing and presents them as a single KeyValues.
+// Keys are routed by the store position, adding a store at the end only moves
+// the keys that are routed to it. Copy and Rename between stores are done
+// with Get, Set (and Cut), so renamed values get new timestamps
+func ShardedKeyValues(stores []KeyValues) (KeyValues, error) {
+	if len(stores) == 0 {
+		return nil, ErrNoShards
+	}
+
+	ring := make([]shardPoint, 0, len(stores)*shardVirtualNodes)
+	for shard := range stores {
+		for vn := 0; vn < shardVirtualNodes; vn++ {
+			ring = append(ring, shardPoint{
+				hash:  shardHash(strconv.Itoa(shard) + "-" + strconv.Itoa(vn)),
+				shard: shard,
+			})
+		}
+	}
+	slices.SortFunc(ring, func(a, b shardPoint) int {
+		switch {
+		case a.hash < b.hash:
+			return -1
+		case a.hash > b.hash:
+			return 1
+		default:
+			return a.shard - b.shard
+		}
+	})
+
+	return &shardedKeyValues{
+		stores: stores,
+		ring:   ring,
+	}, nil
+}
+
+// shardHash returns FNV-1a hash of the string with bits mixed with the
+// SplitMix64 finalizer, since FNV-1a hashes of similar strings (e.g. ring
+// points of the same store) are not spread evenly enough on the ring
+func shardHash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// shardIndex returns the index of the store the key is routed to: the first
+// point on the ring at or after the key hash. Version keys (see VersionKey)
+// are routed with the key, so that all versions are set and retained
+// (see AppendVersion, RetentionRule) by the same store
+func (skv *shardedKeyValues) shardIndex(key string) int {
+	versionedKey, _ := keyVersion(key)
+	hash := shardHash(versionedKey)
+	ii, _ := slices.BinarySearchFunc(skv.ring, hash, func(p shardPoint, h uint64) int {
+		switch {
+		case p.hash < h:
+			return -1
+		case p.hash > h:
+			return 1
+		default:
+			return 0
+		}
+	})
+	if ii == len(skv.ring) {
+		ii = 0
+	}
+	return skv.ring[ii].shard
+}
+
+func (skv *shardedKeyValues) shard(key string) KeyValues {
+	return skv.stores[skv.shardIndex(key)]
+}
+
+// groupKeys groups keys by the index of the store they're routed to
+func (skv *shardedKeyValues) groupKeys(keys []string) map[int][]string {
+	groups := make(map[int][]string)
+	for _, key := range keys {
+		shard := skv.shardIndex(key)
+		groups[shard] = append(groups[shard], key)
+	}
+	return groups
+}
+
+// mergeKeys applies f to every store and merges returned keys
+func (skv *shardedKeyValues) mergeKeys(f func(kv KeyValues) ([]string, error)) ([]string, error) {
+	merged := make([]string, 0)
+	for _, kv := range skv.stores {
+		keys, err := f(kv)
+		if err != nil {
+			return merged, err
+		}
+		merged = append(merged, keys...)
+	}
+	return merged, nil
+}
+
+func (skv *shardedKeyValues) Keys() ([]string, error) {
+	return skv.mergeKeys(func(kv KeyValues) ([]string, error) {
+		return kv.Keys()
+	})
+}
+
+func (skv *shardedKeyValues) KeysWithPrefix(prefix string) ([]string, error) {
+	return skv.mergeKeys(func(kv KeyValues) ([]string, error) {
+		return kv.KeysWithPrefix(prefix)
+	})
+}
+
+func (skv *shardedKeyValues) KeysMatching(pattern string) ([]string, error) {
+	return skv.mergeKeys(func(kv KeyValues) ([]string, error) {
+		return kv.KeysMatching(pattern)
+	})
+}
+
+// KeysPage merges the first offset+limit ordered keys of every store,
+// since keys before the offset can be in any store
+func (skv *shardedKeyValues) KeysPage(offset, limit int) ([]string, error) {
+	if offset < 0 || limit <= 0 {
+		return []string{}, nil
+	}
+
+	keys, err := skv.mergeKeys(func(kv KeyValues) ([]string, error) {
+		return kv.KeysPage(0, offset+limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(keys)
+
+	if offset >= len(keys) {
+		return []string{}, nil
+	}
+	return keys[offset:min(offset+limit, len(keys))], nil
+}
+
+// KeysSorted sorts keys of all stores by their timestamps. Timestamps of
+// stores other than local key values are read with GetRecord
+func (skv *shardedKeyValues) KeysSorted(by SortField, desc bool) ([]string, error) {
+	tss := make(map[string]int64)
+	for _, kv := range skv.stores {
+		if lkv, ok := kv.(*keyValues); ok {
+			stss, err := lkv.keyTimestamps(by)
+			if err != nil {
+				return nil, err
+			}
+			maps.Copy(tss, stss)
+			continue
+		}
+		keys, err := kv.Keys()
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if record, ok := kv.GetRecord(key); ok {
+				tss[key] = record.Created
+				if by == Modified {
+					tss[key] = record.Modified
+				}
+			}
+		}
+	}
+	return sortKeysByTimestamps(tss, desc), nil
+}
+
+func (skv *shardedKeyValues) Len() (int, error) {
+	n := 0
+	for _, kv := range skv.stores {
+		kvn, err := kv.Len()
+		if err != nil {
+			return n, err
+		}
+		n += kvn
+	}
+	return n, nil
+}
+
+func (skv *shardedKeyValues) Has(key string) (bool, error) {
+	return skv.shard(key).Has(key)
+}
+
+// SampleKeys returns up to n keys chosen at random from all stores. The number
+// of keys sampled from every store is proportional to the number of its keys,
+// so that every key is equally likely to be sampled
+func (skv *shardedKeyValues) SampleKeys(n int) ([]string, error) {
+	lens := make([]int, len(skv.stores))
+	total := 0
+	for ii, kv := range skv.stores {
+		kvn, err := kv.Len()
+		if err != nil {
+			return nil, err
+		}
+		lens[ii] = kvn
+		total += kvn
+	}
+
+	n = max(min(n, total), 0)
+
+	// draw n keys without replacement, counting them per store
+	counts := make([]int, len(skv.stores))
+	for jj := n; jj > 0; jj-- {
+		ii, kk := 0, rand.IntN(total)
+		for kk >= lens[ii] {
+			kk -= lens[ii]
+			ii++
+		}
+		counts[ii]++
+		lens[ii]--
+		total--
+	}
+
+	sample := make([]string, 0, n)
+	for ii, kv := range skv.stores {
+		if counts[ii] == 0 {
+			continue
+		}
+		keys, err := kv.SampleKeys(counts[ii])
+		if err != nil {
+			return nil, err
+		}
+		sample = append(sample, keys...)
+	}
+
+	rand.Shuffle(len(sample), func(ii, jj int) {
+		sample[ii], sample[jj] = sample[jj], sample[ii]
+	})
+
+	return sample, nil
+}
+
+func (skv *shardedKeyValues) Get(key string) (io.ReadCloser, error) {
+	return skv.shard(key).Get(key)
+}
+
+func (skv *shardedKeyValues) GetContext(ctx context.Context, key string) (io.ReadCloser, error) {
+	return skv.shard(key).GetContext(ctx, key)
+}
+
+func (skv *shardedKeyValues) GetIfModifiedSince(key string, ts int64) (io.ReadCloser, bool, error) {
+	return skv.shard(key).GetIfModifiedSince(key, ts)
+}
+
+// GetArchive streams values from every store into a single zip archive.
+// Entries are written in the keys order, same as for a single store.
+// Values are streamed with Get of the store they're routed to, values of
+// stores that weren't connected with NewKeyValues (e.g. wrapped stores)
+// are copied from their single entry archives
+func (skv *shardedKeyValues) GetArchive(w io.Writer, keys ...string) error {
+	zw := newArchiveWriter(w)
+
+	for _, key := range keys {
+		var err error
+		switch kv := skv.shard(key).(type) {
+		case *keyValues:
+			err = kv.archiveValue(zw, key)
+		default:
+			err = copyArchiveValue(zw, kv, key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// copyArchiveValue copies the entry of the single value archive of the store
+func copyArchiveValue(zw *zip.Writer, kv KeyValues, key string) error {
+	buf := new(bytes.Buffer)
+	if err := kv.GetArchive(buf, key); err != nil {
+		return err
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		return err
+	}
+
+	for _, zf := range zr.File {
+		if err := zw.Copy(zf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (skv *shardedKeyValues) RenderValue(key string, data any, w io.Writer) error {
+	return skv.shard(key).RenderValue(key, data, w)
+}
+
+func (skv *shardedKeyValues) Set(key string, data io.Reader) error {
+	return skv.shard(key).Set(key, data)
+}
+
+func (skv *shardedKeyValues) SetContext(ctx context.Context, key string, data io.Reader) error {
+	return skv.shard(key).SetContext(ctx, key, data)
+}
+
+func (skv *shardedKeyValues) SetIfMatch(key string, data io.Reader, expectedHash string) (bool, error) {
+	return skv.shard(key).SetIfMatch(key, data, expectedHash)
+}
+
+func (skv *shardedKeyValues) SetWithPolicy(key string, data io.Reader, policy ConflictPolicy, ts int64) (string, error) {
+	return skv.shard(key).SetWithPolicy(key, data, policy, ts)
+}
+
+func (skv *shardedKeyValues) SetMany(keyReaders map[string]io.Reader) error {
+	groups := make(map[int]map[string]io.Reader)
+	for key, reader := range keyReaders {
+		shard := skv.shardIndex(key)
+		if groups[shard] == nil {
+			groups[shard] = make(map[string]io.Reader)
+		}
+		groups[shard][key] = reader
+	}
+
+	for shard, shardKeyReaders := range groups {
+		if err := skv.stores[shard].SetMany(shardKeyReaders); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (skv *shardedKeyValues) SetWriter(key string) (io.WriteCloser, error) {
+	return skv.shard(key).SetWriter(key)
+}
+
+func (skv *shardedKeyValues) Append(key string, data io.Reader) error {
+	return skv.shard(key).Append(key, data)
+}
+
+func (skv *shardedKeyValues) SetFromFile(key, path string, move bool) error {
+	return skv.shard(key).SetFromFile(key, path, move)
+}
+
+func (skv *shardedKeyValues) PatchJSON(key string, patch []byte) error {
+	return skv.shard(key).PatchJSON(key, patch)
+}
+
+// SeedFrom seeds every store with the files that have keys routed to it
+func (skv *shardedKeyValues) SeedFrom(fsys fs.FS, overwrite bool) error {
+	for shard, kv := range skv.stores {
+		if err := kv.SeedFrom(&shardFS{FS: fsys, skv: skv, shard: shard}, overwrite); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetArchive splits the archive into archives of entries routed to every
+// store and sets values from them
+func (skv *shardedKeyValues) SetArchive(r io.ReaderAt, size int64, skipCorrupt bool) (map[string]error, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	buffers := make(map[int]*bytes.Buffer)
+	writers := make(map[int]*zip.Writer)
+	for _, zf := range zr.File {
+		shard := skv.shardIndex(archiveFileKey(&zf.FileHeader))
+		if writers[shard] == nil {
+			buffers[shard] = new(bytes.Buffer)
+			writers[shard] = zip.NewWriter(buffers[shard])
+		}
+		if err := writers[shard].Copy(zf); err != nil {
+			return nil, err
+		}
+	}
+
+	skipped := make(map[string]error)
+	for shard, zw := range writers {
+		if err := zw.Close(); err != nil {
+			return skipped, err
+		}
+		buf := buffers[shard]
+		shardSkipped, err := skv.stores[shard].SetArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()), skipCorrupt)
+		for name, skipErr := range shardSkipped {
+			skipped[name] = skipErr
+		}
+		if err != nil {
+			return skipped, err
+		}
+	}
+
+	return skipped, nil
+}
+
+func (skv *shardedKeyValues) Cut(key string) (bool, error) {
+	return skv.shard(key).Cut(key)
+}
+
+func (skv *shardedKeyValues) CutContext(ctx context.Context, key string) (bool, error) {
+	return skv.shard(key).CutContext(ctx, key)
+}
+
+func (skv *shardedKeyValues) CutMany(keys ...string) (map[string]bool, error) {
+	cut := make(map[string]bool, len(keys))
+	for shard, shardKeys := range skv.groupKeys(keys) {
+		shardCut, err := skv.stores[shard].CutMany(shardKeys...)
+		for key, ok := range shardCut {
+			cut[key] = ok
+		}
+		if err != nil {
+			return cut, err
+		}
+	}
+	return cut, nil
+}
+
+func (skv *shardedKeyValues) Copy(src, dst string) error {
+	srcKv, dstKv := skv.shard(src), skv.shard(dst)
+	if srcKv == dstKv {
+		return srcKv.Copy(src, dst)
+	}
+
+	rc, err := srcKv.Get(src)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return dstKv.Set(dst, rc)
+}
+
+func (skv *shardedKeyValues) Rename(src, dst string) error {
+	srcKv, dstKv := skv.shard(src), skv.shard(dst)
+	if srcKv == dstKv {
+		return srcKv.Rename(src, dst)
+	}
+
+	if err := skv.Copy(src, dst); err != nil {
+		return err
+	}
+
+	_, err := srcKv.Cut(src)
+	return err
+}
+
+func (skv *shardedKeyValues) SetDerived(key, variant string, data io.Reader) error {
+	return skv.shard(key).SetDerived(key, variant, data)
+}
+
+func (skv *shardedKeyValues) GetDerived(key, variant string) (io.ReadCloser, error) {
+	return skv.shard(key).GetDerived(key, variant)
+}
+
+// IsCurrent reports whether every store is current and the latest
+// log records modification time
+func (skv *shardedKeyValues) IsCurrent() (bool, int64) {
+	current := true
+	var lmt int64 = -1
+	for _, kv := range skv.stores {
+		ok, slmt := kv.IsCurrent()
+		current = current && ok
+		lmt = max(lmt, slmt)
+	}
+	return current, lmt
+}
+
+func (skv *shardedKeyValues) CreatedAfter(ts int64) ([]string, error) {
+	return skv.mergeKeys(func(kv KeyValues) ([]string, error) {
+		return kv.CreatedAfter(ts)
+	})
+}
+
+func (skv *shardedKeyValues) UpdatedAfter(ts int64) ([]string, error) {
+	return skv.mergeKeys(func(kv KeyValues) ([]string, error) {
+		return kv.UpdatedAfter(ts)
+	})
+}
+
+func (skv *shardedKeyValues) CreatedOrUpdatedAfter(ts int64) ([]string, error) {
+	return skv.mergeKeys(func(kv KeyValues) ([]string, error) {
+		return kv.CreatedOrUpdatedAfter(ts)
+	})
+}
+
+func (skv *shardedKeyValues) IsUpdatedAfter(key string, ts int64) (bool, error) {
+	return skv.shard(key).IsUpdatedAfter(key, ts)
+}
+
+func (skv *shardedKeyValues) ModTime(key string) (int64, error) {
+	return skv.shard(key).ModTime(key)
+}
+
+func (skv *shardedKeyValues) Size(key string) (int64, error) {
+	return skv.shard(key).Size(key)
+}
+
+func (skv *shardedKeyValues) TotalSize() (int64, error) {
+	var total int64
+	for _, kv := range skv.stores {
+		size, err := kv.TotalSize()
+		if err != nil {
+			return 0, err
+		}
+		total += size
+	}
+	return total, nil
+}
+
+func (skv *shardedKeyValues) GetRecord(key string) (Record, bool) {
+	return skv.shard(key).GetRecord(key)
+}
+
+// Snapshot records snapshots of every store. Snapshot id combines
+// store snapshot ids
+func (skv *shardedKeyValues) Snapshot() (string, error) {
+	ids := make([]string, 0, len(skv.stores))
+	for _, kv := range skv.stores {
+		id, err := kv.Snapshot()
+		if err != nil {
+			return "", err
+		}
+		ids = append(ids, id)
+	}
+
+	id, err := json.Marshal(ids)
+	return string(id), err
+}
+
+func (skv *shardedKeyValues) ChangedSince(snapshotId string) (*Changes, error) {
+	var ids []string
+	if err := json.Unmarshal([]byte(snapshotId), &ids); err != nil {
+		return nil, fmt.Errorf("kevlar: snapshot %s: %w", snapshotId, err)
+	}
+	if len(ids) != len(skv.stores) {
+		return nil, fmt.Errorf("kevlar: snapshot %s has %d stores, expected %d", snapshotId, len(ids), len(skv.stores))
+	}
+
+	changes := &Changes{
+		Added:    make([]string, 0),
+		Removed:  make([]string, 0),
+		Modified: make([]string, 0),
+	}
+	for ii, kv := range skv.stores {
+		sc, err := kv.ChangedSince(ids[ii])
+		if err != nil {
+			return nil, err
+		}
+		changes.Added = append(changes.Added, sc.Added...)
+		changes.Removed = append(changes.Removed, sc.Removed...)
+		changes.Modified = append(changes.Modified, sc.Modified...)
+	}
+
+	return changes, nil
+}
+
+// Generation combines generations of all stores: the id joins
+// store ids in shard order and N is the sum of store generations
+func (skv *shardedKeyValues) Generation() (Generation, error) {
+	ids := make([]string, 0, len(skv.stores))
+	var n uint64
+	for _, kv := range skv.stores {
+		g, err := kv.Generation()
+		if err != nil {
+			return Generation{}, err
+		}
+		ids = append(ids, g.Id)
+		n += g.N
+	}
+	return Generation{Id: strings.Join(ids, ","), N: n}, nil
+}
+
+func (skv *shardedKeyValues) SetRefreshAfter(key string, ts int64) error {
+	return skv.shard(key).SetRefreshAfter(key, ts)
+}
+
+func (skv *shardedKeyValues) DueForRefresh(ts int64) ([]string, error) {
+	return skv.mergeKeys(func(kv KeyValues) ([]string, error) {
+		return kv.DueForRefresh(ts)
+	})
+}
+
+func (skv *shardedKeyValues) SetWithExpiry(key string, data io.Reader, ttl time.Duration) error {
+	return skv.shard(key).SetWithExpiry(key, data, ttl)
+}
+
+func (skv *shardedKeyValues) PruneExpired() ([]string, error) {
+	return skv.mergeKeys(func(kv KeyValues) ([]string, error) {
+		return kv.PruneExpired()
+	})
+}
+
+func (skv *shardedKeyValues) Pin(key string) error {
+	return skv.shard(key).Pin(key)
+}
+
+func (skv *shardedKeyValues) Unpin(key string) error {
+	return skv.shard(key).Unpin(key)
+}
+
+func (skv *shardedKeyValues) PinnedKeys() ([]string, error) {
+	return skv.mergeKeys(func(kv KeyValues) ([]string, error) {
+		return kv.PinnedKeys()
+	})
+}
+
+func (skv *shardedKeyValues) Flush() error {
+	errs := make([]error, 0, len(skv.stores))
+	for _, kv := range skv.stores {
+		errs = append(errs, kv.Flush())
+	}
+	return errors.Join(errs...)
+}
+
+// Freeze freezes every store. When any store can't be frozen,
+// stores that have been frozen are thawed
+func (skv *shardedKeyValues) Freeze() error {
+	for ii, kv := range skv.stores {
+		if err := kv.Freeze(); err != nil {
+			for _, frozen := range skv.stores[:ii] {
+				frozen.Thaw()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (skv *shardedKeyValues) Thaw() {
+	for _, kv := range skv.stores {
+		kv.Thaw()
+	}
+}
+
+func (skv *shardedKeyValues) Close() error {
+	errs := make([]error, 0, len(skv.stores))
+	for _, kv := range skv.stores {
+		errs = append(errs, kv.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (skv *shardedKeyValues) VetContent(quarantine bool) ([]string, error) {
+	return skv.mergeKeys(func(kv KeyValues) ([]string, error) {
+		return kv.VetContent(quarantine)
+	})
+}
+
+func (skv *shardedKeyValues) VetKeys() ([]string, error) {
+	return skv.mergeKeys(func(kv KeyValues) ([]string, error) {
+		return kv.VetKeys()
+	})
+}
+
+func (skv *shardedKeyValues) ApplyRetention(rules ...RetentionRule) ([]string, error) {
+	return skv.mergeKeys(func(kv KeyValues) ([]string, error) {
+		return kv.ApplyRetention(rules...)
+	})
+}
+
+func (skv *shardedKeyValues) Archive(pred func(Record) bool, dst KeyValues) (int, error) {
+	archived := 0
+	for _, kv := range skv.stores {
+		n, err := kv.Archive(pred, dst)
+		archived += n
+		if err != nil {
+			return archived, err
+		}
+	}
+	return archived, nil
+}
+
+func (skv *shardedKeyValues) PrefixStats(delimiter string) (map[string]PrefixStat, error) {
+	stats := make(map[string]PrefixStat)
+	for _, kv := range skv.stores {
+		sps, err := kv.PrefixStats(delimiter)
+		if err != nil {
+			return nil, err
+		}
+		for prefix, sp := range sps {
+			ps := stats[prefix]
+			ps.Count += sp.Count
+			ps.Bytes += sp.Bytes
+			ps.ModTime = max(ps.ModTime, sp.ModTime)
+			stats[prefix] = ps
+		}
+	}
+	return stats, nil
+}
+
+// Stats returns the sum of every store counters
+func (skv *shardedKeyValues) Stats() Stats {
+	var stats Stats
+	for _, kv := range skv.stores {
+		ss := kv.Stats()
+		stats.Gets += ss.Gets
+		stats.Sets += ss.Sets
+		stats.UnchangedSets += ss.UnchangedSets
+		stats.Cuts += ss.Cuts
+		stats.BytesIn += ss.BytesIn
+		stats.BytesOut += ss.BytesOut
+		stats.CacheHits += ss.CacheHits
+		stats.MaintenanceRuns += ss.MaintenanceRuns
+		stats.MaintenanceErrors += ss.MaintenanceErrors
+		stats.LastMaintenance = max(stats.LastMaintenance, ss.LastMaintenance)
+	}
+	return stats
+}
+
+func (skv *shardedKeyValues) SizeDistribution(buckets []int64) (map[string]int, error) {
+	distribution := make(map[string]int)
+	for _, kv := range skv.stores {
+		sd, err := kv.SizeDistribution(buckets)
+		if err != nil {
+			return nil, err
+		}
+		for label, count := range sd {
+			distribution[label] += count
+		}
+	}
+	return distribution, nil
+}
+
+// SizePercentile returns the value size at the percentile p across all
+// stores. Percentiles can't be merged, so sizes of all values are read
+func (skv *shardedKeyValues) SizePercentile(p float64) (int64, error) {
+	if p < 0 || p > 100 {
+		return 0, fmt.Errorf("kevlar: percentile %v is out of range 0-100", p)
+	}
+
+	keys, err := skv.Keys()
+	if err != nil {
+		return 0, err
+	}
+
+	sizes := make([]int64, 0, len(keys))
+	for _, key := range keys {
+		if record, ok := skv.GetRecord(key); ok {
+			sizes = append(sizes, record.Size)
+		}
+	}
+
+	return percentile(sizes, p), nil
+}
+
+// shardFS exposes files of the filesystem with keys routed to the store
+// (and all directories), see SeedFrom
+type shardFS struct {
+	fs.FS
+	skv   *shardedKeyValues
+	shard int
+}
+
+func (sfs *shardFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(sfs.FS, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(entries, func(de fs.DirEntry) bool {
+		return !de.IsDir() && sfs.skv.shardIndex(archiveEntryKey(path.Join(name, de.Name()))) != sfs.shard
+	}), nil
+}
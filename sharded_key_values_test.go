@@ -0,0 +1,236 @@
+package kevlar
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/boggydigital/testo"
+	"golang.org/x/exp/slices"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func mockShards(t *testing.T, name string, n int) ([]KeyValues, string) {
+	dir := filepath.Join(os.TempDir(), testsDirname, name)
+
+	stores := make([]KeyValues, 0, n)
+	for ii := 0; ii < n; ii++ {
+		kv, err := NewKeyValues(filepath.Join(dir, strconv.Itoa(ii)), JsonExt)
+		testo.Error(t, err, false)
+		stores = append(stores, kv)
+	}
+
+	return stores, dir
+}
+
+func TestShardedKeyValues(t *testing.T) {
+	_, err := ShardedKeyValues(nil)
+	testo.EqualValues(t, err, ErrNoShards)
+
+	stores, dir := mockShards(t, "shards", 3)
+
+	skv, err := ShardedKeyValues(stores)
+	testo.Error(t, err, false)
+
+	keys := make([]string, 0, 100)
+	for ii := 0; ii < 100; ii++ {
+		key := strconv.Itoa(ii)
+		keys = append(keys, key)
+		testo.Error(t, skv.Set(key, strings.NewReader("{\"k\":"+key+"}")), false)
+	}
+
+	merged, err := skv.Keys()
+	testo.Error(t, err, false)
+	slices.Sort(merged)
+	expected := slices.Clone(keys)
+	slices.Sort(expected)
+	testo.DeepEqual(t, merged, expected)
+
+	updated, err := skv.CreatedOrUpdatedAfter(0)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(updated), len(keys))
+
+	// every store has a share of keys, and every key is only in one store
+	total := 0
+	for _, kv := range stores {
+		storeKeys, err := kv.Keys()
+		testo.Error(t, err, false)
+		testo.EqualValues(t, len(storeKeys) > 10, true)
+		total += len(storeKeys)
+	}
+	testo.EqualValues(t, total, len(keys))
+
+	testo.EqualValues(t, readValue(t, skv, "42"), "{\"k\":42}")
+
+	// values are copied between stores
+	testo.Error(t, skv.Copy("1", "copied"), false)
+	testo.EqualValues(t, readValue(t, skv, "copied"), "{\"k\":1}")
+	testo.Error(t, skv.Rename("copied", "renamed"), false)
+	has, err := skv.Has("copied")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, has, false)
+	testo.EqualValues(t, readValue(t, skv, "renamed"), "{\"k\":1}")
+
+	// archives combine values from every store in the keys order
+	// and preserve keys that are not valid filenames
+	testo.Error(t, skv.Set("user/1.avatar", strings.NewReader("{}")), false)
+	buf := new(bytes.Buffer)
+	testo.Error(t, skv.GetArchive(buf, append(keys, "user/1.avatar")...), false)
+
+	archived, archivedDir := mockShards(t, "archived-shards", 2)
+	askv, err := ShardedKeyValues(archived)
+	testo.Error(t, err, false)
+
+	skipped, err := askv.SetArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()), false)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(skipped), 0)
+	testo.EqualValues(t, readValue(t, askv, "42"), "{\"k\":42}")
+	testo.EqualValues(t, readValue(t, askv, "user/1.avatar"), "{}")
+
+	testo.EqualValues(t, skv.Stats().Sets >= int64(len(keys)), true)
+
+	testo.Error(t, skv.Close(), false)
+	testo.Error(t, askv.Close(), false)
+	testo.Error(t, os.RemoveAll(dir), false)
+	testo.Error(t, os.RemoveAll(archivedDir), false)
+}
+
+func TestShardedKeyValues_AddingStoreMovesKeysToIt(t *testing.T) {
+	stores := make([]KeyValues, 4)
+
+	skv3, err := ShardedKeyValues(stores[:3])
+	testo.Error(t, err, false)
+	skv4, err := ShardedKeyValues(stores)
+	testo.Error(t, err, false)
+
+	moved := 0
+	for ii := 0; ii < 1000; ii++ {
+		key := strconv.Itoa(ii)
+		before := skv3.(*shardedKeyValues).shardIndex(key)
+		after := skv4.(*shardedKeyValues).shardIndex(key)
+		if before != after {
+			testo.EqualValues(t, after, 3)
+			moved++
+		}
+	}
+
+	// about a quarter of keys are expected to move to the new store
+	testo.EqualValues(t, moved > 150 && moved < 350, true)
+}
+
+func TestShardedKeyValues_Snapshot(t *testing.T) {
+	stores, dir := mockShards(t, "shards", 2)
+
+	skv, err := ShardedKeyValues(stores)
+	testo.Error(t, err, false)
+
+	testo.Error(t, skv.Set("1", strings.NewReader("{}")), false)
+
+	id, err := skv.Snapshot()
+	testo.Error(t, err, false)
+
+	testo.Error(t, skv.Set("2", strings.NewReader("{}")), false)
+
+	changes, err := skv.ChangedSince(id)
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, changes.Added, []string{"2"})
+
+	_, err = skv.ChangedSince("[]")
+	testo.Error(t, err, true)
+
+	testo.Error(t, skv.Close(), false)
+	testo.Error(t, os.RemoveAll(dir), false)
+}
+
+func TestShardedKeyValues_SampleKeys(t *testing.T) {
+	stores, dir := mockShards(t, "shards", 3)
+
+	skv, err := ShardedKeyValues(stores)
+	testo.Error(t, err, false)
+
+	keys := make([]string, 0, 10)
+	for ii := 0; ii < cap(keys); ii++ {
+		key := strconv.Itoa(ii)
+		testo.Error(t, skv.Set(key, strings.NewReader("{}")), false)
+		keys = append(keys, key)
+	}
+
+	for n, exp := range map[int]int{-1: 0, 4: 4, 20: 10} {
+		sample, err := skv.SampleKeys(n)
+		testo.Error(t, err, false)
+		testo.EqualValues(t, len(sample), exp)
+		for ii, key := range sample {
+			testo.EqualValues(t, slices.Contains(keys, key), true)
+			testo.EqualValues(t, slices.Contains(sample[:ii], key), false)
+		}
+	}
+
+	testo.Error(t, skv.Close(), false)
+	testo.Error(t, os.RemoveAll(dir), false)
+}
+
+func TestShardedKeyValues_ApplyRetentionMaxVersions(t *testing.T) {
+	stores, dir := mockShards(t, "shards", 3)
+
+	skv, err := ShardedKeyValues(stores)
+	testo.Error(t, err, false)
+
+	for ii := 0; ii < 5; ii++ {
+		_, err = skv.SetWithPolicy("doc", strings.NewReader("{}"), AppendVersion, 0)
+		testo.Error(t, err, false)
+	}
+
+	// versions are routed with the key
+	for ii := 2; ii <= 5; ii++ {
+		testo.EqualValues(t, skv.(*shardedKeyValues).shardIndex(VersionKey("doc", ii)), skv.(*shardedKeyValues).shardIndex("doc"))
+	}
+
+	cut, err := skv.ApplyRetention(RetentionRule{MaxVersions: 2})
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, cut, []string{"doc", "doc~2", "doc~3"})
+
+	keys, err := skv.Keys()
+	testo.Error(t, err, false)
+	slices.Sort(keys)
+	testo.DeepEqual(t, keys, []string{"doc~4", "doc~5"})
+
+	testo.Error(t, skv.Close(), false)
+	testo.Error(t, os.RemoveAll(dir), false)
+}
+
+// wrappedKeyValues hides the store type, same as wrappers from other packages
+type wrappedKeyValues struct {
+	KeyValues
+}
+
+func TestShardedKeyValues_GetArchiveWrappedStores(t *testing.T) {
+	stores, dir := mockShards(t, "shards", 3)
+	stores[1] = wrappedKeyValues{KeyValues: stores[1]}
+
+	skv, err := ShardedKeyValues(stores)
+	testo.Error(t, err, false)
+
+	keys := make([]string, 0, 20)
+	for ii := 0; ii < 20; ii++ {
+		key := strconv.Itoa(ii)
+		keys = append(keys, key)
+		testo.Error(t, skv.Set(key, strings.NewReader("{\"k\":"+key+"}")), false)
+	}
+
+	buf := new(bytes.Buffer)
+	testo.Error(t, skv.GetArchive(buf, keys...), false)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	testo.Error(t, err, false)
+
+	archivedKeys := make([]string, 0, len(zr.File))
+	for _, zf := range zr.File {
+		archivedKeys = append(archivedKeys, zf.Comment)
+	}
+	testo.DeepEqual(t, archivedKeys, keys)
+
+	testo.Error(t, skv.Close(), false)
+	testo.Error(t, os.RemoveAll(dir), false)
+}
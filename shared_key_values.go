@@ -0,0 +1,102 @@
+package kevlar
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+var ErrOptionsConflict = errors.New("kevlar: options conflict with shared key values")
+
+type dirExt struct {
+	dir string
+	ext string
+}
+
+var (
+	sharedKeyValues    = make(map[dirExt]*keyValues)
+	sharedKeyValuesMtx = new(sync.Mutex)
+)
+
+// connectShared returns key values already connected to the same directory
+// and extension in this process (or connects new ones), so that all users
+// share the same log records instead of overwriting each other's writes.
+// Options must match the ones shared key values have been connected with,
+// unless none are specified. Shared key values are released when every user
+// has called Close
+func connectShared(dir, ext string, options ...KeyValuesOption) (*keyValues, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	de := dirExt{dir: absDir, ext: ext}
+
+	sharedKeyValuesMtx.Lock()
+	defer sharedKeyValuesMtx.Unlock()
+
+	if kv, ok := sharedKeyValues[de]; ok {
+		if len(options) > 0 {
+			okv := &keyValues{}
+			for _, option := range options {
+				option(okv)
+			}
+			if !kv.sameOptions(okv) {
+				return nil, fmt.Errorf("%w: %s", ErrOptionsConflict, absDir)
+			}
+		}
+
+		// log records might have been changed externally since the last
+		// refresh (within modification time resolution), make sure they're
+		// reloaded for the new user
+		kv.mtx.Lock()
+		kv.logState = logState{modTime: -2}
+		kv.refs++
+		kv.mtx.Unlock()
+
+		if err := kv.refreshLogRecords(); err != nil {
+			return nil, err
+		}
+		return kv, nil
+	}
+
+	kv, err := connect(dir, ext, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	kv.shared = true
+	kv.refs = 1
+	sharedKeyValues[de] = kv
+
+	return kv, nil
+}
+
+// release decrements the number of shared key values users and returns
+// true when the last user has released them
+func (kv *keyValues) release() (bool, error) {
+	if !kv.shared {
+		return true, nil
+	}
+
+	absDir, err := filepath.Abs(kv.dir)
+	if err != nil {
+		return false, err
+	}
+
+	sharedKeyValuesMtx.Lock()
+	defer sharedKeyValuesMtx.Unlock()
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	if kv.refs > 0 {
+		kv.refs--
+	}
+	if kv.refs > 0 {
+		return false, nil
+	}
+
+	delete(sharedKeyValues, dirExt{dir: absDir, ext: kv.ext})
+	return true, nil
+}
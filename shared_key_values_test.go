@@ -0,0 +1,98 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewKeyValues_Shared(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname, "shared")
+
+	kv1, err := NewKeyValues(dir, GobExt)
+	testo.Error(t, err, false)
+	kv2, err := NewKeyValues(dir, GobExt)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, kv1 == kv2, true)
+
+	// different extension should connect separate key values
+	kv3, err := NewKeyValues(dir, JsonExt)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, kv1 == kv3, false)
+
+	// options that are different from the shared key values are rejected
+	_, err = NewKeyValues(dir, GobExt, WithFastHash())
+	testo.EqualValues(t, errors.Is(err, ErrOptionsConflict), true)
+
+	testo.Error(t, kv1.Set("shared", strings.NewReader("shared")), false)
+	has, err := kv2.Has("shared")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, has, true)
+
+	ok, err := kv2.Cut("shared")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	// shared key values are released after the last user closes them
+	testo.Error(t, kv1.Close(), false)
+	kv5, err := NewKeyValues(dir, GobExt)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, kv2 == kv5, true)
+
+	testo.Error(t, kv2.Close(), false)
+	testo.Error(t, kv5.Close(), false)
+	kv6, err := NewKeyValues(dir, GobExt)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, kv2 == kv6, false)
+
+	for _, kv := range []KeyValues{kv3, kv6} {
+		testo.Error(t, kv.Close(), false)
+	}
+
+	testo.Error(t, os.RemoveAll(dir), false)
+}
+
+func TestNewKeyValues_SharedWithOptions(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname, "shared-options")
+
+	m := Maintenance{Interval: time.Hour, ReduxAssets: []string{"a1"}}
+	kv1, err := NewKeyValues(dir, GobExt, WithFastHash(), WithMaintenance(m))
+	testo.Error(t, err, false)
+
+	// connecting without options or with the same options shares key values
+	kv2, err := NewKeyValues(dir, GobExt)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, kv1 == kv2, true)
+	kv3, err := NewKeyValues(dir, GobExt, WithMaintenance(m), WithFastHash())
+	testo.Error(t, err, false)
+	testo.EqualValues(t, kv1 == kv3, true)
+
+	for _, options := range [][]KeyValuesOption{
+		{WithFastHash()},
+		{WithFastHash(), WithMaintenance(m), WithStrict()},
+		{WithFastHash(), WithMaintenance(Maintenance{Interval: time.Minute})},
+	} {
+		_, err = NewKeyValues(dir, GobExt, options...)
+		testo.EqualValues(t, errors.Is(err, ErrOptionsConflict), true)
+	}
+
+	// reductions connected to the same directory see values set by the
+	// key values with maintenance
+	rdx, err := NewReduxWriter(dir, "a1")
+	testo.Error(t, err, false)
+	testo.Error(t, rdx.AddValues("a1", "k1", "v1"), false)
+	has, err := kv1.Has("a1")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, has, true)
+	testo.Error(t, closeRedux(rdx), false)
+
+	for _, kv := range []KeyValues{kv1, kv2, kv3} {
+		testo.Error(t, kv.Close(), false)
+	}
+
+	testo.Error(t, os.RemoveAll(dir), false)
+}
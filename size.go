@@ -0,0 +1,85 @@
+package kevlar
+
+import (
+	"fmt"
+	"os"
+)
+
+// Size returns the size of the value recorded in the log records, without
+// reading file metadata. Values written before sizes were recorded (and empty
+// values) fall back to the file size. Unknown keys return ErrUnknownKey
+func (kv *keyValues) Size(key string) (int64, error) {
+	size, ok, err := kv.loggedSize(key)
+	if err != nil {
+		return -1, err
+	} else if !ok {
+		return -1, fmt.Errorf("%w: %s", ErrUnknownKey, key)
+	}
+
+	if size > 0 {
+		return size, nil
+	}
+
+	fi, err := os.Stat(kv.absValueFilename(key))
+	if err != nil {
+		return -1, err
+	}
+	return fi.Size(), nil
+}
+
+// loggedSize returns the size of the latest create or update log record
+// of the key and whether the key exists
+func (kv *keyValues) loggedSize(key string) (int64, bool, error) {
+	if err := kv.refreshLogRecords(); err != nil {
+		return 0, false, err
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	created, updated := kv.currentLogRecords(key)
+	switch {
+	case updated != nil:
+		return updated.Sz, true, nil
+	case created != nil:
+		return created.Sz, true, nil
+	default:
+		return 0, false, nil
+	}
+}
+
+// TotalSize returns the cumulative size of all values, same as the sum of
+// Size for every key
+func (kv *keyValues) TotalSize() (int64, error) {
+	if err := kv.refreshLogRecords(); err != nil {
+		return 0, err
+	}
+
+	kv.mtx.Lock()
+	sizes := make(map[string]int64, len(kv.keys))
+	for _, lr := range kv.log {
+		switch lr.Mt {
+		case create:
+			fallthrough
+		case update:
+			sizes[lr.Id] = lr.Sz
+		case cut:
+			delete(sizes, lr.Id)
+		}
+	}
+	kv.mtx.Unlock()
+
+	var total int64
+	for key, size := range sizes {
+		if size == 0 {
+			fi, err := os.Stat(kv.absValueFilename(key))
+			if err != nil {
+				return 0, err
+			}
+			size = fi.Size()
+		}
+		total += size
+	}
+
+	return total, nil
+}
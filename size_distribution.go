@@ -0,0 +1,100 @@
+package kevlar
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+)
+
+// SizeDistribution counts values by size into buckets defined by their upper
+// bounds (in bytes). Values are counted in the first bucket they fit, labeled
+// "<=bound", and values larger than every bound are labeled ">bound" with the
+// largest bound. Every bucket is present in the result, even if empty
+func (kv *keyValues) SizeDistribution(buckets []int64) (map[string]int, error) {
+	bounds := slices.Clone(buckets)
+	slices.Sort(bounds)
+	bounds = slices.Compact(bounds)
+
+	sizes, err := kv.valueSizes()
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make([]string, 0, len(bounds)+1)
+	for _, bound := range bounds {
+		labels = append(labels, "<="+strconv.FormatInt(bound, 10))
+	}
+	if len(bounds) > 0 {
+		labels = append(labels, ">"+strconv.FormatInt(bounds[len(bounds)-1], 10))
+	} else {
+		labels = append(labels, ">=0")
+	}
+
+	distribution := make(map[string]int, len(labels))
+	for _, label := range labels {
+		distribution[label] = 0
+	}
+
+	for _, size := range sizes {
+		ii, _ := slices.BinarySearch(bounds, size)
+		distribution[labels[ii]]++
+	}
+
+	return distribution, nil
+}
+
+// SizePercentile returns the value size (in bytes) at the percentile p (0-100),
+// using nearest-rank method. Empty storage has all percentiles equal to 0
+func (kv *keyValues) SizePercentile(p float64) (int64, error) {
+	if p < 0 || p > 100 {
+		return 0, fmt.Errorf("kevlar: percentile %v is out of range 0-100", p)
+	}
+
+	sizes, err := kv.valueSizes()
+	if err != nil {
+		return 0, err
+	}
+
+	return percentile(sizes, p), nil
+}
+
+// percentile returns the size at the percentile p, using nearest-rank
+// method, or 0 when there are no sizes. Sizes are sorted in place
+func percentile(sizes []int64, p float64) int64 {
+	if len(sizes) == 0 {
+		return 0
+	}
+
+	slices.Sort(sizes)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sizes))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sizes[rank-1]
+}
+
+// valueSizes returns sizes of all stored values, skipping
+// keys with value files missing
+func (kv *keyValues) valueSizes() ([]int64, error) {
+	keys, err := kv.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make([]int64, 0, len(keys))
+	for _, key := range keys {
+		fi, err := os.Stat(kv.absValueFilename(key))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, fi.Size())
+	}
+
+	return sizes, nil
+}
@@ -0,0 +1,65 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_SizeDistribution(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	keyValues := map[string]string{
+		"1": "1",
+		"2": "22",
+		"3": "333",
+		"4": strings.Repeat("4", 10),
+	}
+
+	for key, value := range keyValues {
+		testo.Error(t, kv.Set(key, strings.NewReader(value)), false)
+	}
+
+	distribution, err := kv.SizeDistribution([]int64{5, 2})
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, distribution, map[string]int{"<=2": 2, "<=5": 1, ">5": 1})
+
+	distribution, err = kv.SizeDistribution(nil)
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, distribution, map[string]int{">=0": 4})
+
+	tests := []struct {
+		p   float64
+		exp int64
+	}{
+		{0, 1},
+		{25, 1},
+		{50, 2},
+		{75, 3},
+		{100, 10},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			size, err := kv.SizePercentile(tt.p)
+			testo.Error(t, err, false)
+			testo.EqualValues(t, size, tt.exp)
+		})
+	}
+
+	_, err = kv.SizePercentile(101)
+	testo.Error(t, err, true)
+
+	for key := range keyValues {
+		ok, err := kv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,83 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_Size(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname)
+
+	kv, err := NewKeyValues(dir, GobExt)
+	testo.Error(t, err, false)
+
+	tests := []struct {
+		mutate func() error
+		key    string
+		size   int64
+	}{
+		{func() error { return kv.Set("sized", strings.NewReader("value")) }, "sized", 5},
+		{func() error { return kv.Set("sized", strings.NewReader("longer value")) }, "sized", 12},
+		{func() error { return kv.Append("sized", strings.NewReader("!")) }, "sized", 13},
+		{func() error { return kv.Set("empty", strings.NewReader("")) }, "empty", 0},
+		{func() error { return kv.Copy("sized", "copied") }, "copied", 13},
+		{func() error { return kv.Rename("copied", "renamed") }, "renamed", 13},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			testo.Error(t, tt.mutate(), false)
+			size, err := kv.Size(tt.key)
+			testo.Error(t, err, false)
+			testo.EqualValues(t, size, tt.size)
+		})
+	}
+
+	total, err := kv.TotalSize()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, total, int64(13+0+13))
+
+	// sizes are logged, so they're available after the value file is gone
+	testo.Error(t, os.Remove(kv.(*keyValues).absValueFilename("sized")), false)
+	size, err := kv.Size("sized")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, size, int64(13))
+
+	for _, key := range []string{"copied", "key-that-doesnt-exist"} {
+		_, err = kv.Size(key)
+		testo.EqualValues(t, err != nil, true)
+	}
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_SizeAfterRecreate(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("recreated", strings.NewReader("1")), false)
+	testo.Error(t, kv.Set("recreated", strings.NewReader("22")), false)
+	_, err = kv.Cut("recreated")
+	testo.Error(t, err, false)
+	testo.Error(t, kv.Set("recreated", strings.NewReader("333")), false)
+	testo.Error(t, kv.Set("recreated", strings.NewReader("4444")), false)
+
+	size, err := kv.Size("recreated")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, size, int64(4))
+
+	total, err := kv.TotalSize()
+	testo.Error(t, err, false)
+	testo.EqualValues(t, total, int64(4))
+
+	_, err = kv.Cut("recreated")
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,65 @@
+package kevlar
+
+import (
+	"golang.org/x/exp/maps"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// slowOpSpan wraps tracer span and logs operations that took longer
+// than the threshold when the span ends (see WithSlowOpLog)
+type slowOpSpan struct {
+	Span
+	kv    *keyValues
+	op    string
+	key   string
+	bytes int64
+	start time.Time
+}
+
+// startSpan starts tracer span for the operation on the key
+func (kv *keyValues) startSpan(op, key string) Span {
+	span := tracer.Start(op, key)
+	if ls, ok := span.(LabeledSpan); ok && len(kv.labels) > 0 {
+		ls.SetLabels(kv.labels)
+	}
+	if kv.slowOpThreshold <= 0 {
+		return span
+	}
+	return &slowOpSpan{Span: span, kv: kv, op: op, key: key, start: time.Now()}
+}
+
+func (sos *slowOpSpan) SetBytes(n int64) {
+	sos.bytes = n
+	sos.Span.SetBytes(n)
+}
+
+func (sos *slowOpSpan) End(err error) {
+	sos.Span.End(err)
+
+	elapsed := time.Since(sos.start)
+	if elapsed < sos.kv.slowOpThreshold {
+		return
+	}
+
+	logger := sos.kv.slowOpLogger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	attrs := []any{"op", sos.op, "key", sos.key, "bytes", sos.bytes, "duration", elapsed}
+	if err != nil {
+		attrs = append(attrs, "err", err)
+	}
+	if len(sos.kv.labels) > 0 {
+		names := maps.Keys(sos.kv.labels)
+		slices.Sort(names)
+		labels := make([]any, 0, len(names))
+		for _, name := range names {
+			labels = append(labels, slog.String(name, sos.kv.labels[name]))
+		}
+		attrs = append(attrs, slog.Group("labels", labels...))
+	}
+	logger.Warn("kevlar: slow operation", attrs...)
+}
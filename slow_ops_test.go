@@ -0,0 +1,47 @@
+package kevlar
+
+import (
+	"bytes"
+	"github.com/boggydigital/testo"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyValues_WithSlowOpLog(t *testing.T) {
+	tests := []struct {
+		threshold time.Duration
+		logged    bool
+	}{
+		{0, false},
+		{time.Nanosecond, true},
+		{time.Hour, false},
+	}
+
+	dir := filepath.Join(os.TempDir(), testsDirname)
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			logger := slog.New(slog.NewTextHandler(buf, nil))
+
+			kv, err := NewKeyValues(dir, GobExt, WithSlowOpLog(tt.threshold, logger))
+			testo.Error(t, err, false)
+
+			testo.Error(t, kv.Set("slow", strings.NewReader("value")), false)
+
+			testo.EqualValues(t, strings.Contains(buf.String(), "kevlar: slow operation"), tt.logged)
+			testo.EqualValues(t, strings.Contains(buf.String(), "op=Set key=slow bytes=5"), tt.logged)
+
+			_, err = kv.Cut("slow")
+			testo.Error(t, err, false)
+
+			testo.Error(t, kv.Close(), false)
+			testo.Error(t, logRecordsCleanup(), false)
+		})
+	}
+}
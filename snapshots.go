@@ -0,0 +1,105 @@
+package kevlar
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const snapshotsDirname = "_snapshots"
+
+// Changes lists keys that have been added, removed or modified
+type Changes struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
+
+func (kv *keyValues) absSnapshotFilename(id string) string {
+	return filepath.Join(kv.dir, kevlarDirname, snapshotsDirname, id+GobExt)
+}
+
+// Snapshot records current keys and value hashes and returns snapshot id
+// that can be used with ChangedSince
+func (kv *keyValues) Snapshot() (string, error) {
+	hashes, err := kv.currentHashes()
+	if err != nil {
+		return "", err
+	}
+
+	absSnapshotsDir := filepath.Join(kv.dir, kevlarDirname, snapshotsDirname)
+	if _, err := os.Stat(absSnapshotsDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(absSnapshotsDir, 0755); err != nil {
+			return "", err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(hashes); err != nil {
+		return "", err
+	}
+
+	id := strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	return id, kv.writeStaged(kv.absSnapshotFilename(id), buf)
+}
+
+// ChangedSince compares current keys and value hashes with a snapshot
+func (kv *keyValues) ChangedSince(snapshotId string) (*Changes, error) {
+	snapshotFile, err := os.Open(kv.absSnapshotFilename(snapshotId))
+	if err != nil {
+		return nil, err
+	}
+	defer snapshotFile.Close()
+
+	var snapshot map[string]string
+	if err := gob.NewDecoder(snapshotFile).Decode(&snapshot); err != nil {
+		return nil, err
+	}
+
+	hashes, err := kv.currentHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &Changes{
+		Added:    make([]string, 0),
+		Removed:  make([]string, 0),
+		Modified: make([]string, 0),
+	}
+
+	for key, hash := range hashes {
+		if snapshotHash, ok := snapshot[key]; !ok {
+			changes.Added = append(changes.Added, key)
+		} else if snapshotHash != hash {
+			changes.Modified = append(changes.Modified, key)
+		}
+	}
+
+	for key := range snapshot {
+		if _, ok := hashes[key]; !ok {
+			changes.Removed = append(changes.Removed, key)
+		}
+	}
+
+	return changes, nil
+}
+
+func (kv *keyValues) currentHashes() (map[string]string, error) {
+	keys, err := kv.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if hashes[key], err = readSidecarFile(kv.absHashFilename(key)); err != nil {
+			return nil, err
+		}
+	}
+
+	return hashes, nil
+}
@@ -0,0 +1,49 @@
+package kevlar
+
+import (
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_ChangedSince(t *testing.T) {
+	ikv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	kv, ok := ikv.(*keyValues)
+	testo.EqualValues(t, ok, true)
+
+	testo.Error(t, kv.Set("unchanged", strings.NewReader("1")), false)
+	testo.Error(t, kv.Set("modified", strings.NewReader("1")), false)
+	testo.Error(t, kv.Set("removed", strings.NewReader("1")), false)
+
+	id, err := kv.Snapshot()
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("modified", strings.NewReader("2")), false)
+	testo.Error(t, kv.Set("added", strings.NewReader("1")), false)
+	ok, err = kv.Cut("removed")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	changes, err := kv.ChangedSince(id)
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, changes.Added, []string{"added"})
+	testo.DeepEqual(t, changes.Removed, []string{"removed"})
+	testo.DeepEqual(t, changes.Modified, []string{"modified"})
+
+	_, err = kv.ChangedSince("snapshot-that-doesnt-exist")
+	testo.Error(t, err, true)
+
+	for _, key := range []string{"unchanged", "modified", "added"} {
+		ok, err = kv.Cut(key)
+		testo.EqualValues(t, ok, true)
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, ikv.Close(), false)
+	testo.Error(t, os.RemoveAll(filepath.Join(kv.dir, kevlarDirname, snapshotsDirname)), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
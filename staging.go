@@ -0,0 +1,110 @@
+package kevlar
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+var (
+	ErrNoSpace            = errors.New("kevlar: no space left on device")
+	ErrStagingOtherDevice = errors.New("kevlar: staging dir is on a different device")
+)
+
+// noSpaceErr annotates out of space errors with ErrNoSpace, so that
+// applications can detect them with errors.Is
+func noSpaceErr(err error) error {
+	if errors.Is(err, syscall.ENOSPC) {
+		return fmt.Errorf("%w: %w", ErrNoSpace, err)
+	}
+	return err
+}
+
+// validateStagingDir makes sure staging dir exists and is located on the
+// same device as the storage dir, so that staged files can be renamed
+func validateStagingDir(stagingDir, dir string) error {
+	if _, err := os.Stat(stagingDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(stagingDir, 0755); err != nil {
+			return err
+		}
+	}
+
+	sfi, err := os.Stat(stagingDir)
+	if err != nil {
+		return err
+	}
+	dfi, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+
+	sst, sok := sfi.Sys().(*syscall.Stat_t)
+	dst, dok := dfi.Sys().(*syscall.Stat_t)
+	if sok && dok && sst.Dev != dst.Dev {
+		return ErrStagingOtherDevice
+	}
+
+	return nil
+}
+
+// defaultFileMode is the mode of new value, log and sidecar files,
+// matching the files created before writes have been staged
+const defaultFileMode os.FileMode = 0644
+
+// stagedFileMode returns the mode staged file should have to replace the
+// file: the mode of the existing file or defaultFileMode for new files.
+// Temporary files are created owner-only and renames keep the mode
+func stagedFileMode(absFilename string) os.FileMode {
+	if fi, err := os.Stat(absFilename); err == nil {
+		return fi.Mode().Perm()
+	}
+	return defaultFileMode
+}
+
+// writeStaged writes data to a temporary file and renames it into place
+// only when the write has completed. Staged file is synced before it's
+// renamed, since filesystems with delayed allocation might only report
+// running out of space (or I/O errors) when the data is written back.
+// Partially written files (e.g. when the disk fills up) are removed and
+// never replace existing values. Creating and renaming staged file is
+// retried, since the reader can't be read again
+func (kv *keyValues) writeStaged(absFilename string, reader io.Reader) error {
+	dir, filename := filepath.Split(absFilename)
+	if kv.stagingDir != "" {
+		dir = kv.stagingDir
+	}
+
+	var stagedFile *os.File
+	if err := kv.retry(func() (err error) {
+		stagedFile, err = os.CreateTemp(dir, "."+filename+"-*")
+		return err
+	}); err != nil {
+		return noSpaceErr(err)
+	}
+	stagedFilename := stagedFile.Name()
+
+	err := stagedFile.Chmod(stagedFileMode(absFilename))
+	if err == nil {
+		_, err = io.Copy(stagedFile, reader)
+	}
+	if err == nil {
+		err = stagedFile.Sync()
+	}
+	if closeErr := stagedFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = kv.retry(func() error {
+			return os.Rename(stagedFilename, absFilename)
+		})
+	}
+
+	if err != nil {
+		return noSpaceErr(errors.Join(err, os.Remove(stagedFilename)))
+	}
+
+	return nil
+}
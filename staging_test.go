@@ -0,0 +1,81 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+type errReader struct {
+	err error
+}
+
+func (er errReader) Read([]byte) (int, error) {
+	return 0, er.err
+}
+
+func TestKeyValues_WriteStaged(t *testing.T) {
+	kv := mockKeyValues()
+	testo.Error(t, os.MkdirAll(kv.dir, 0755), false)
+
+	absFilename := filepath.Join(kv.dir, "staged"+kv.ext)
+	testo.Error(t, kv.writeStaged(absFilename, strings.NewReader("original")), false)
+
+	fi, err := os.Stat(absFilename)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, fi.Mode().Perm(), defaultFileMode)
+
+	// failed writes should leave existing file unchanged and remove staged files
+	err = kv.writeStaged(absFilename, errReader{err: syscall.ENOSPC})
+	testo.Error(t, err, true)
+	testo.EqualValues(t, errors.Is(err, ErrNoSpace), true)
+
+	err = kv.writeStaged(absFilename, errReader{err: syscall.EIO})
+	testo.Error(t, err, true)
+	testo.EqualValues(t, errors.Is(err, ErrNoSpace), false)
+
+	bts, err := os.ReadFile(absFilename)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, string(bts), "original")
+
+	matches, err := filepath.Glob(filepath.Join(kv.dir, ".staged*"))
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(matches), 0)
+
+	// replacing files should keep their mode
+	testo.Error(t, os.Chmod(absFilename, 0600), false)
+	testo.Error(t, kv.writeStaged(absFilename, strings.NewReader("replaced")), false)
+
+	fi, err = os.Stat(absFilename)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, fi.Mode().Perm(), os.FileMode(0600))
+
+	testo.Error(t, os.Remove(absFilename), false)
+}
+
+func TestNewKeyValues_WithStagingDir(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname)
+	stagingDir := filepath.Join(dir, "_staging")
+
+	kv, err := NewKeyValues(dir, GobExt, WithStagingDir(stagingDir))
+	testo.Error(t, err, false)
+	testo.Nil(t, kv, false)
+
+	testo.Error(t, kv.Set("staged", strings.NewReader("staged")), false)
+
+	entries, err := os.ReadDir(stagingDir)
+	testo.Error(t, err, false)
+	testo.EqualValues(t, len(entries), 0)
+
+	ok, err := kv.Cut("staged")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, os.Remove(stagingDir), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,73 @@
+package kevlar
+
+import (
+	"errors"
+	"expvar"
+	"sync/atomic"
+)
+
+// Stats are counters of successful storage operations since the key values
+// were connected. Sets include unchanged values, that were not written
+// because their hash matched. CacheHits counts templates reused by
+// RenderValue without parsing the value again. Maintenance counters report
+// background maintenance runs, runs that failed and the Unix timestamp of the
+// last run (see WithMaintenance). Labels attribute counters to the storage
+// (see WithLabels)
+type Stats struct {
+	Gets              int64
+	Sets              int64
+	UnchangedSets     int64
+	Cuts              int64
+	BytesIn           int64
+	BytesOut          int64
+	CacheHits         int64
+	MaintenanceRuns   int64
+	MaintenanceErrors int64
+	LastMaintenance   int64
+	Labels            map[string]string `json:",omitempty"`
+}
+
+type storeStats struct {
+	gets          atomic.Int64
+	sets          atomic.Int64
+	unchangedSets atomic.Int64
+	cuts          atomic.Int64
+	bytesIn       atomic.Int64
+	bytesOut      atomic.Int64
+	cacheHits     atomic.Int64
+
+	maintenanceRuns   atomic.Int64
+	maintenanceErrors atomic.Int64
+	lastMaintenance   atomic.Int64
+}
+
+// Stats returns current values of storage counters
+func (kv *keyValues) Stats() Stats {
+	return Stats{
+		Gets:          kv.stats.gets.Load(),
+		Sets:          kv.stats.sets.Load(),
+		UnchangedSets: kv.stats.unchangedSets.Load(),
+		Cuts:          kv.stats.cuts.Load(),
+		BytesIn:       kv.stats.bytesIn.Load(),
+		BytesOut:      kv.stats.bytesOut.Load(),
+		CacheHits:     kv.stats.cacheHits.Load(),
+
+		MaintenanceRuns:   kv.stats.maintenanceRuns.Load(),
+		MaintenanceErrors: kv.stats.maintenanceErrors.Load(),
+		LastMaintenance:   kv.stats.lastMaintenance.Load(),
+		Labels:            kv.labels,
+	}
+}
+
+// PublishStats publishes storage counters as an expvar variable with the
+// provided name, so that they're available with the standard debug
+// tooling (e.g. /debug/vars). Names must be unique within the process
+func PublishStats(name string, kv KeyValues) error {
+	if expvar.Get(name) != nil {
+		return errors.New("kevlar: expvar already published " + name)
+	}
+	expvar.Publish(name, expvar.Func(func() any {
+		return kv.Stats()
+	}))
+	return nil
+}
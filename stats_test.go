@@ -0,0 +1,59 @@
+package kevlar
+
+import (
+	"bytes"
+	"expvar"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyValues_Stats(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname, "stats")
+	testo.Error(t, os.MkdirAll(dir, 0755), false)
+
+	kv, err := NewKeyValues(dir, HtmlExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("template", strings.NewReader("{{.}}")), false)
+	testo.Error(t, kv.Set("template", strings.NewReader("{{.}}")), false)
+
+	rc, err := kv.Get("template")
+	testo.Error(t, err, false)
+	testo.Error(t, rc.Close(), false)
+
+	_, err = kv.Get("missing")
+	testo.Error(t, err, true)
+
+	for ii := 0; ii < 2; ii++ {
+		testo.Error(t, kv.RenderValue("template", "value", new(bytes.Buffer)), false)
+	}
+
+	ok, err := kv.Cut("template")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	ok, err = kv.Cut("template")
+	testo.EqualValues(t, ok, false)
+	testo.Error(t, err, false)
+
+	testo.DeepEqual(t, kv.Stats(), Stats{
+		// RenderValue gets the value to parse template
+		Gets:          2,
+		Sets:          2,
+		UnchangedSets: 1,
+		Cuts:          1,
+		BytesIn:       10,
+		BytesOut:      10,
+		CacheHits:     1,
+	})
+
+	testo.Error(t, PublishStats("kevlar-stats-test", kv), false)
+	testo.Error(t, PublishStats("kevlar-stats-test", kv), true)
+	testo.EqualValues(t, strings.Contains(expvar.Get("kevlar-stats-test").String(), `"Cuts":1`), true)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, os.RemoveAll(dir), false)
+}
@@ -0,0 +1,216 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	stressWorkers    = 8
+	stressOperations = 500
+	stressKeys       = 32
+)
+
+// TestKeyValues_StressMixedWorkload runs concurrent Set, Get, Cut and Keys
+// on a small set of keys, then validates that log records, value files
+// and hashes are consistent with each other
+func TestKeyValues_StressMixedWorkload(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname, "stress")
+	testo.Error(t, os.MkdirAll(dir, 0755), false)
+
+	kv, err := NewKeyValues(dir, GobExt)
+	testo.Error(t, err, false)
+
+	wg := new(sync.WaitGroup)
+	errs := make(chan error, stressWorkers)
+
+	for ww := 0; ww < stressWorkers; ww++ {
+		wg.Add(1)
+		go func(ww int) {
+			defer wg.Done()
+			errs <- stressKeyValues(kv, ww)
+		}(ww)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		testo.Error(t, err, false)
+	}
+
+	testo.Error(t, kv.Close(), false)
+
+	// connect again to validate what has been persisted
+	ckv, err := connect(dir, GobExt)
+	testo.Error(t, err, false)
+
+	keys, err := ckv.Keys()
+	testo.Error(t, err, false)
+
+	for _, key := range keys {
+		data, err := os.ReadFile(ckv.absValueFilename(key))
+		testo.Error(t, err, false)
+		// values are always set to their key
+		testo.EqualValues(t, string(data), key)
+
+		hash, err := os.ReadFile(ckv.absHashFilename(key))
+		testo.Error(t, err, false)
+		testo.EqualValues(t, string(hash), sha256Hex(data))
+	}
+
+	// no value or hash files are left for cut keys
+	for ii := 0; ii < stressKeys; ii++ {
+		key := strconv.Itoa(ii)
+		if slices.Contains(keys, key) {
+			continue
+		}
+		for _, absFilename := range []string{ckv.absValueFilename(key), ckv.absHashFilename(key)} {
+			_, err := os.Stat(absFilename)
+			testo.EqualValues(t, os.IsNotExist(err), true)
+		}
+	}
+
+	testo.Error(t, os.RemoveAll(dir), false)
+}
+
+func stressKeyValues(kv KeyValues, seed int) error {
+	rnd := rand.New(rand.NewPCG(uint64(seed), 0))
+
+	for ii := 0; ii < stressOperations; ii++ {
+		key := strconv.Itoa(rnd.IntN(stressKeys))
+
+		switch rnd.IntN(4) {
+		case 0:
+			if err := kv.Set(key, strings.NewReader(key)); err != nil {
+				return err
+			}
+		case 1:
+			rc, err := kv.Get(key)
+			if os.IsNotExist(err) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			_, err = io.ReadAll(rc)
+			if err = errors.Join(err, rc.Close()); err != nil {
+				return err
+			}
+		case 2:
+			if _, err := kv.Cut(key); err != nil {
+				return err
+			}
+		case 3:
+			if _, err := kv.Keys(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// TestRedux_StressMixedWorkload runs a redux writer concurrently with
+// redux readers refreshing and matching the same asset, then validates
+// that the persisted reduction matches the values written
+func TestRedux_StressMixedWorkload(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname, "stress-redux")
+	testo.Error(t, os.MkdirAll(dir, 0755), false)
+
+	const asset = "stress"
+
+	rdx, err := NewReduxWriter(dir, asset)
+	testo.Error(t, err, false)
+
+	// reductions are not goroutine safe, so every reader has its own
+	readers := make([]ReadableRedux, 0, stressWorkers)
+	for ww := 0; ww < stressWorkers; ww++ {
+		rdr, err := NewReduxReader(dir, asset)
+		testo.Error(t, err, false)
+		readers = append(readers, rdr)
+	}
+
+	expected := make(map[string][]string)
+
+	wg := new(sync.WaitGroup)
+	errs := make(chan error, stressWorkers+1)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		rnd := rand.New(rand.NewPCG(1, 0))
+		for ii := 0; ii < stressOperations; ii++ {
+			key := strconv.Itoa(rnd.IntN(stressKeys))
+			val := strconv.Itoa(rnd.IntN(stressKeys))
+			if rnd.IntN(3) == 0 {
+				if err := rdx.CutValues(asset, key, val); err != nil {
+					errs <- err
+					return
+				}
+				expected[key] = slices.DeleteFunc(expected[key], func(v string) bool { return v == val })
+				if len(expected[key]) == 0 {
+					delete(expected, key)
+				}
+			} else {
+				if err := rdx.AddValues(asset, key, val); err != nil {
+					errs <- err
+					return
+				}
+				if !slices.Contains(expected[key], val) {
+					expected[key] = append(expected[key], val)
+				}
+			}
+		}
+	}()
+
+	for _, rdr := range readers {
+		wg.Add(1)
+		go func(rdr ReadableRedux) {
+			defer wg.Done()
+			for ii := 0; ii < stressOperations/10; ii++ {
+				var err error
+				if rdr, err = rdr.RefreshReader(); err != nil {
+					errs <- err
+					return
+				}
+				rdr.Match(map[string][]string{asset: {"1"}})
+			}
+		}(rdr)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		testo.Error(t, err, false)
+	}
+
+	rdr, err := NewReduxReader(dir, asset)
+	testo.Error(t, err, false)
+
+	keys := rdr.Keys(asset)
+	slices.Sort(keys)
+	expectedKeys := maps.Keys(expected)
+	slices.Sort(expectedKeys)
+	testo.DeepEqual(t, keys, expectedKeys)
+
+	for key, values := range expected {
+		actual, ok := rdr.GetAllValues(asset, key)
+		testo.EqualValues(t, ok, true)
+		slices.Sort(actual)
+		slices.Sort(values)
+		testo.DeepEqual(t, actual, values)
+	}
+
+	testo.Error(t, os.RemoveAll(dir), false)
+}
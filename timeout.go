@@ -0,0 +1,124 @@
+package kevlar
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+type timeoutResult[T any] struct {
+	val T
+	err error
+}
+
+const (
+	operationRunning int32 = iota
+	operationCommitted
+	operationAbandoned
+)
+
+// operation is run with timeout (see withContext). Operations that change
+// storage must commit before the first change: commit fails once the
+// operation has been abandoned, and operations that have been committed
+// are not abandoned, so that operations that returned an error don't
+// change storage afterwards
+type operation struct {
+	ctx   context.Context
+	state atomic.Int32
+}
+
+func (op *operation) commit() error {
+	if op.state.CompareAndSwap(operationRunning, operationCommitted) ||
+		op.state.Load() == operationCommitted {
+		return nil
+	}
+	return op.ctx.Err()
+}
+
+func (op *operation) abandon() bool {
+	return op.state.CompareAndSwap(operationRunning, operationAbandoned)
+}
+
+// reader returns a reader that fails once the operation context is done and
+// commits the operation when the data has been read completely. Values are
+// read completely before they are written, so abandoned operations fail
+// before writing and committed operations are completed
+func (op *operation) reader(r io.Reader) io.Reader {
+	return &operationReader{op: op, r: r}
+}
+
+type operationReader struct {
+	op *operation
+	r  io.Reader
+}
+
+func (or *operationReader) Read(p []byte) (int, error) {
+	if err := or.op.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := or.r.Read(p)
+	if err == io.EOF {
+		if commitErr := or.op.commit(); commitErr != nil {
+			return n, commitErr
+		}
+	}
+	return n, err
+}
+
+// withTimeout runs the operation and returns context.DeadlineExceeded error
+// when it doesn't complete within storage default timeout (see WithTimeout).
+// Filesystem calls can't be interrupted, so the operation continues in the
+// background and abandon is called with its result, e.g. to close a file.
+// Operations that have been committed (see operation) are waited for
+func withTimeout[T any](kv *keyValues, op, key string, fn func(*operation) (T, error), abandon func(T)) (T, error) {
+	return withContext(context.Background(), kv, op, key, fn, abandon)
+}
+
+// withContext runs the operation same as withTimeout and also returns
+// context error when the parent context is cancelled or its deadline
+// passes before the operation completes
+func withContext[T any](parent context.Context, kv *keyValues, opName, key string, fn func(*operation) (T, error), abandon func(T)) (T, error) {
+	if kv.timeout <= 0 && parent.Done() == nil {
+		return fn(&operation{ctx: parent})
+	}
+
+	if err := parent.Err(); err != nil {
+		var zero T
+		return zero, fmt.Errorf("kevlar: %s %s: %w", opName, key, err)
+	}
+
+	ctx, cancel := parent, context.CancelFunc(func() {})
+	if kv.timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, kv.timeout)
+	}
+	defer cancel()
+
+	op := &operation{ctx: ctx}
+
+	results := make(chan timeoutResult[T], 1)
+	go func() {
+		val, err := fn(op)
+		results <- timeoutResult[T]{val: val, err: err}
+	}()
+
+	select {
+	case result := <-results:
+		return result.val, result.err
+	case <-ctx.Done():
+		if !op.abandon() {
+			// the operation has been committed before it's been abandoned
+			result := <-results
+			return result.val, result.err
+		}
+		if abandon != nil {
+			go func() {
+				if result := <-results; result.err == nil {
+					abandon(result.val)
+				}
+			}()
+		}
+		var zero T
+		return zero, fmt.Errorf("kevlar: %s %s: %w", opName, key, ctx.Err())
+	}
+}
@@ -0,0 +1,106 @@
+package kevlar
+
+import (
+	"context"
+	"errors"
+	"github.com/boggydigital/testo"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingReader blocks reads until released and then fails,
+// simulating a hung filesystem
+type blockingReader struct {
+	release chan struct{}
+}
+
+func (br *blockingReader) Read([]byte) (int, error) {
+	<-br.release
+	return 0, errors.New("released")
+}
+
+// lateReader blocks reads until released and then completes
+// the value, simulating a slow client
+type lateReader struct {
+	release chan struct{}
+	done    chan struct{}
+}
+
+func (lr *lateReader) Read(p []byte) (int, error) {
+	<-lr.release
+	defer close(lr.done)
+	return copy(p, "late"), io.EOF
+}
+
+func TestWithTimeout(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt,
+		WithTimeout(10*time.Millisecond))
+	testo.Error(t, err, false)
+
+	br := &blockingReader{release: make(chan struct{})}
+	err = kv.Set("hung", br)
+	testo.EqualValues(t, errors.Is(err, context.DeadlineExceeded), true)
+	close(br.release)
+
+	ok, err := kv.Has("hung")
+	testo.EqualValues(t, ok, false)
+	testo.Error(t, err, false)
+
+	// operations completing within timeout are not affected
+	testo.Error(t, kv.Set("fast", strings.NewReader("value")), false)
+
+	rc, err := kv.Get("fast")
+	testo.Error(t, err, false)
+	testo.Error(t, rc.Close(), false)
+
+	ok, err = kv.Cut("fast")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestWithTimeout_AbandonedWritesAreNotSet(t *testing.T) {
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt,
+		WithTimeout(10*time.Millisecond))
+	testo.Error(t, err, false)
+
+	lr := &lateReader{release: make(chan struct{}), done: make(chan struct{})}
+	err = kv.Set("late", lr)
+	testo.EqualValues(t, errors.Is(err, context.DeadlineExceeded), true)
+	close(lr.release)
+	<-lr.done
+
+	// value read after the timeout must not be set in the background
+	time.Sleep(10 * time.Millisecond)
+	ok, err := kv.Has("late")
+	testo.EqualValues(t, ok, false)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestOperation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// committed operations are not abandoned
+	committed := &operation{ctx: ctx}
+	testo.Error(t, committed.commit(), false)
+	testo.EqualValues(t, committed.abandon(), false)
+	testo.Error(t, committed.commit(), false)
+
+	// abandoned operations can't be committed
+	abandoned := &operation{ctx: ctx}
+	testo.EqualValues(t, abandoned.abandon(), true)
+	testo.EqualValues(t, errors.Is(abandoned.commit(), context.Canceled), true)
+
+	_, err := io.ReadAll(abandoned.reader(strings.NewReader("value")))
+	testo.EqualValues(t, errors.Is(err, context.Canceled), true)
+}
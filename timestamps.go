@@ -0,0 +1,176 @@
+package kevlar
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// timestamps are per-key Unix timestamps stored in a single
+// gob file alongside the log records (e.g. refresh schedule)
+type timestamps map[string]int64
+
+func (kv *keyValues) absTimestampsFilename(filename string) string {
+	return filepath.Join(kv.dir, kevlarDirname, filename)
+}
+
+func (kv *keyValues) loadTimestamps(filename string) (timestamps, error) {
+	tsFile, err := os.Open(kv.absTimestampsFilename(filename))
+	if os.IsNotExist(err) {
+		return make(timestamps), nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer tsFile.Close()
+
+	var ts timestamps
+	if err := gob.NewDecoder(tsFile).Decode(&ts); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if ts == nil {
+		ts = make(timestamps)
+	}
+
+	return ts, nil
+}
+
+func (kv *keyValues) writeTimestamps(filename string, ts timestamps) error {
+	absFilename := kv.absTimestampsFilename(filename)
+	dir, _ := filepath.Split(absFilename)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(ts); err != nil {
+		return err
+	}
+
+	return kv.writeStaged(absFilename, buf)
+}
+
+// setTimestamp sets (or removes, when ts is negative) the key timestamp
+func (kv *keyValues) setTimestamp(filename, key string, ts int64) error {
+	done, err := kv.mutating()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return kv.putTimestamp(filename, key, ts)
+}
+
+// putTimestamp sets (or removes, when ts is negative) the key timestamp.
+// Expects mutations to be allowed (see mutating)
+func (kv *keyValues) putTimestamp(filename, key string, ts int64) error {
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	tss, err := kv.loadTimestamps(filename)
+	if err != nil {
+		return err
+	}
+
+	if ts < 0 {
+		delete(tss, key)
+	} else {
+		tss[key] = ts
+	}
+
+	return kv.writeTimestamps(filename, tss)
+}
+
+// keysBefore returns existing keys with timestamps before or equal to ts
+func (kv *keyValues) keysBefore(filename string, ts int64) ([]string, error) {
+	kv.mtx.Lock()
+	tss, err := kv.loadTimestamps(filename)
+	kv.mtx.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0)
+	for key, kts := range tss {
+		if kts > ts {
+			continue
+		}
+		if ok, err := kv.Has(key); err != nil {
+			return nil, err
+		} else if ok {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
+
+// cutTimestamps removes timestamps of the keys. Expects mutations
+// to be allowed (see mutating)
+func (kv *keyValues) cutTimestamps(filename string, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	tss, err := kv.loadTimestamps(filename)
+	if err != nil {
+		return err
+	}
+
+	cut := false
+	for _, key := range keys {
+		if _, ok := tss[key]; ok {
+			delete(tss, key)
+			cut = true
+		}
+	}
+	if !cut {
+		return nil
+	}
+
+	return kv.writeTimestamps(filename, tss)
+}
+
+// keyTimestampsFilenames are the files with per-key timestamps
+// that are moved with renamed keys and removed with cut keys
+var keyTimestampsFilenames = []string{refreshAfterFilename, expiresFilename, pinnedFilename}
+
+// valueTimestampsFilenames are the files with per-key timestamps that
+// describe the value set for the key (e.g. its version or the stub of
+// the archived value) and are removed with cut, created and renamed keys
+var valueTimestampsFilenames = []string{versionsFilename, archivedFilename}
+
+// moveTimestamp moves the src key timestamp to the dst key. When src key
+// doesn't have a timestamp, dst key timestamp is removed. Expects mutations
+// to be allowed (see mutating)
+func (kv *keyValues) moveTimestamp(filename, src, dst string) error {
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+
+	tss, err := kv.loadTimestamps(filename)
+	if err != nil {
+		return err
+	}
+
+	ts, srcOk := tss[src]
+	_, dstOk := tss[dst]
+	if !srcOk && !dstOk {
+		return nil
+	}
+
+	if srcOk {
+		tss[dst] = ts
+		delete(tss, src)
+	} else {
+		delete(tss, dst)
+	}
+
+	return kv.writeTimestamps(filename, tss)
+}
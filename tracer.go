@@ -0,0 +1,50 @@
+package kevlar
+
+const (
+	opSet    = "Set"
+	opGet    = "Get"
+	opCut    = "Cut"
+	opAppend = "Append"
+	opMatch  = "Match"
+)
+
+// Tracer is a minimal instrumentation interface that allows connecting
+// kevlar operations to a tracing system (e.g. OpenTelemetry spans)
+// without kevlar depending on that system
+type Tracer interface {
+	Start(op, key string) Span
+}
+
+// Span represents a single traced operation
+type Span interface {
+	SetBytes(n int64)
+	End(err error)
+}
+
+// LabeledSpan is implemented by spans that attribute operations
+// to the storage labels (see WithLabels)
+type LabeledSpan interface {
+	Span
+	SetLabels(labels map[string]string)
+}
+
+type nopTracer struct{}
+
+func (nt nopTracer) Start(string, string) Span { return nopSpan{} }
+
+type nopSpan struct{}
+
+func (ns nopSpan) SetBytes(int64) {}
+func (ns nopSpan) End(error)      {}
+
+var tracer Tracer = nopTracer{}
+
+// SetTracer sets the tracer used by all key values and reductions.
+// It's expected to be called once before connecting any storage.
+// Setting nil tracer disables tracing
+func SetTracer(t Tracer) {
+	if t == nil {
+		t = nopTracer{}
+	}
+	tracer = t
+}
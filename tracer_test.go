@@ -0,0 +1,92 @@
+package kevlar
+
+import (
+	"bytes"
+	"github.com/boggydigital/testo"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testSpan struct {
+	op, key string
+	bytes   int64
+	ended   bool
+	labels  map[string]string
+}
+
+func (ts *testSpan) SetBytes(n int64)                   { ts.bytes = n }
+func (ts *testSpan) End(error)                          { ts.ended = true }
+func (ts *testSpan) SetLabels(labels map[string]string) { ts.labels = labels }
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (tt *testTracer) Start(op, key string) Span {
+	span := &testSpan{op: op, key: key}
+	tt.spans = append(tt.spans, span)
+	return span
+}
+
+func TestSetTracer(t *testing.T) {
+	tt := &testTracer{}
+	SetTracer(tt)
+	defer SetTracer(nil)
+
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("traced", strings.NewReader("value")), false)
+	rc, err := kv.Get("traced")
+	testo.Error(t, err, false)
+	testo.Error(t, rc.Close(), false)
+	ok, err := kv.Cut("traced")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.EqualValues(t, len(tt.spans), 3)
+	for ii, op := range []string{opSet, opGet, opCut} {
+		testo.EqualValues(t, tt.spans[ii].op, op)
+		testo.EqualValues(t, tt.spans[ii].key, "traced")
+		testo.EqualValues(t, tt.spans[ii].ended, true)
+	}
+	testo.EqualValues(t, tt.spans[0].bytes, int64(len("value")))
+	testo.EqualValues(t, tt.spans[1].bytes, int64(len("value")))
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestWithLabels(t *testing.T) {
+	tt := &testTracer{}
+	SetTracer(tt)
+	defer SetTracer(nil)
+
+	labels := map[string]string{"dataset": "orders", "app": "shop"}
+
+	buf := new(bytes.Buffer)
+	logger := slog.New(slog.NewTextHandler(buf, nil))
+
+	kv, err := NewKeyValues(filepath.Join(os.TempDir(), testsDirname), GobExt,
+		WithLabels(labels),
+		WithSlowOpLog(time.Nanosecond, logger))
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("labeled", strings.NewReader("value")), false)
+
+	testo.DeepEqual(t, kv.Stats().Labels, labels)
+	testo.EqualValues(t, len(tt.spans), 1)
+	testo.DeepEqual(t, tt.spans[0].labels, labels)
+	testo.EqualValues(t, strings.Contains(buf.String(), "labels.app=shop labels.dataset=orders"), true)
+
+	ok, err := kv.Cut("labeled")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
@@ -0,0 +1,154 @@
+package kevlar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrContentMismatch = errors.New("kevlar: content doesn't match storage extension")
+
+const (
+	quarantineDirname = "_quarantine"
+	sniffLen          = 512
+)
+
+// VetContent sniffs every value and returns the keys with content that doesn't
+// match storage extension (e.g. HTML saved into JsonExt storage). When quarantine
+// is requested, mismatched values are moved out of storage and their keys are cut
+func (kv *keyValues) VetContent(quarantine bool) ([]string, error) {
+	keys, err := kv.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	mismatched := make([]string, 0)
+
+	for _, key := range keys {
+		head, err := Preview(kv, key, sniffLen)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if contentMatchesExt(head, kv.ext) {
+			continue
+		}
+
+		mismatched = append(mismatched, key)
+
+		if quarantine {
+			if err := kv.quarantine(key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return mismatched, nil
+}
+
+func (kv *keyValues) quarantine(key string) error {
+	absQuarantineDir := filepath.Join(kv.dir, kevlarDirname, quarantineDirname)
+	if _, err := os.Stat(absQuarantineDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(absQuarantineDir, 0755); err != nil {
+			return err
+		}
+	}
+
+	absValueFilename := kv.absValueFilename(key)
+	_, filename := filepath.Split(absValueFilename)
+	absQuarantineFilename := filepath.Join(absQuarantineDir, filename)
+
+	// the value is copied rather than moved, since Cut removes it and the
+	// quarantined copy must be removed when the key can't be cut
+	if err := GetToFile(kv, key, absQuarantineFilename, stagedFileMode(absValueFilename)); err != nil {
+		return err
+	}
+
+	if _, err := kv.Cut(key); err != nil {
+		return errors.Join(err, os.Remove(absQuarantineFilename))
+	}
+
+	return nil
+}
+
+func contentMatchesExt(head []byte, ext string) bool {
+	contentType := http.DetectContentType(head)
+	isHtml := strings.HasPrefix(contentType, "text/html")
+
+	switch ext {
+	case JsonExt:
+		trimmed := bytes.TrimSpace(head)
+		if len(trimmed) == 0 || isHtml {
+			return false
+		}
+		return strings.ContainsRune("{[\"-0123456789tfn", rune(trimmed[0]))
+	case HtmlExt:
+		return isHtml
+	case XmlExt:
+		return strings.HasPrefix(contentType, "text/xml") ||
+			bytes.HasPrefix(bytes.TrimSpace(head), []byte("<"))
+	case GobExt:
+		return !isHtml
+	default:
+		return true
+	}
+}
+
+// sniffValue returns ErrContentMismatch when a value set into HtmlExt storage
+// is clearly not HTML (see WithHtmlSniffing)
+func (kv *keyValues) sniffValue(key string, head []byte) error {
+	if !kv.htmlSniffing || kv.ext != HtmlExt {
+		return nil
+	}
+	if contentType, ok := clearlyNotHtml(head); ok {
+		return fmt.Errorf("%w: %s is %s", ErrContentMismatch, key, contentType)
+	}
+	return nil
+}
+
+// sniffFile sniffs the value that will be set from a file, see sniffValue
+func (kv *keyValues) sniffFile(key, path string) error {
+	if !kv.htmlSniffing || kv.ext != HtmlExt {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	return kv.sniffValue(key, head[:n])
+}
+
+// clearlyNotHtml detects content that can't be HTML: JSON (e.g. API
+// error bodies) or binary content. Text that doesn't look like HTML
+// (e.g. a fragment without known tags) is allowed
+func clearlyNotHtml(head []byte) (string, bool) {
+	if len(head) > sniffLen {
+		head = head[:sniffLen]
+	}
+
+	trimmed := bytes.TrimSpace(head)
+	if len(trimmed) == 0 {
+		return "", false
+	}
+	if trimmed[0] == '{' || trimmed[0] == '[' {
+		return "application/json", true
+	}
+
+	contentType := http.DetectContentType(head)
+	return contentType, !strings.HasPrefix(contentType, "text/")
+}
@@ -0,0 +1,99 @@
+package kevlar
+
+import (
+	"errors"
+	"github.com/boggydigital/testo"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestContentMatchesExt(t *testing.T) {
+	tests := []struct {
+		content string
+		ext     string
+		exp     bool
+	}{
+		{"{\"key\":\"value\"}", JsonExt, true},
+		{"  [1, 2]", JsonExt, true},
+		{"<!DOCTYPE html><html></html>", JsonExt, false},
+		{"", JsonExt, false},
+		{"<!DOCTYPE html><html></html>", HtmlExt, true},
+		{"{\"key\":\"value\"}", HtmlExt, false},
+		{"<?xml version=\"1.0\"?><root/>", XmlExt, true},
+		{"<html><body></body></html>", GobExt, false},
+		{"anything", ".txt", true},
+	}
+
+	for ii, tt := range tests {
+		t.Run(strconv.Itoa(ii), func(t *testing.T) {
+			testo.EqualValues(t, contentMatchesExt([]byte(tt.content), tt.ext), tt.exp)
+		})
+	}
+}
+
+func TestKeyValues_VetContent(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname)
+	kv, err := NewKeyValues(dir, JsonExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("json", strings.NewReader("{}")), false)
+	testo.Error(t, kv.Set("html", strings.NewReader("<html><body></body></html>")), false)
+
+	mismatched, err := kv.VetContent(false)
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, mismatched, []string{"html"})
+
+	mismatched, err = kv.VetContent(true)
+	testo.Error(t, err, false)
+	testo.DeepEqual(t, mismatched, []string{"html"})
+
+	has, err := kv.Has("html")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, has, false)
+
+	absQuarantineDir := filepath.Join(dir, kevlarDirname, quarantineDirname)
+	_, err = os.Stat(filepath.Join(absQuarantineDir, "html"+JsonExt))
+	testo.Error(t, err, false)
+
+	ok, err := kv.Cut("json")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, os.RemoveAll(absQuarantineDir), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}
+
+func TestKeyValues_VetContentQuarantineFrozen(t *testing.T) {
+	dir := filepath.Join(os.TempDir(), testsDirname)
+	kv, err := NewKeyValues(dir, JsonExt)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Set("html", strings.NewReader("<html><body></body></html>")), false)
+	testo.Error(t, kv.Freeze(), false)
+
+	_, err = kv.VetContent(true)
+	testo.EqualValues(t, errors.Is(err, ErrFrozen), true)
+
+	// values that can't be cut stay in storage and are not quarantined
+	has, err := kv.Has("html")
+	testo.Error(t, err, false)
+	testo.EqualValues(t, has, true)
+
+	absQuarantineDir := filepath.Join(dir, kevlarDirname, quarantineDirname)
+	_, err = os.Stat(filepath.Join(absQuarantineDir, "html"+JsonExt))
+	testo.EqualValues(t, os.IsNotExist(err), true)
+
+	kv.Thaw()
+
+	ok, err := kv.Cut("html")
+	testo.EqualValues(t, ok, true)
+	testo.Error(t, err, false)
+
+	testo.Error(t, kv.Close(), false)
+	testo.Error(t, os.RemoveAll(absQuarantineDir), false)
+	testo.Error(t, logRecordsCleanup(), false)
+}